@@ -0,0 +1,33 @@
+package api
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/apigatewaymanagementapi"
+	"github.com/pkg/errors"
+)
+
+type ConnectionGetter interface {
+	GetConnectionWithContext(ctx aws.Context, input *apigatewaymanagementapi.GetConnectionInput, opts ...request.Option) (*apigatewaymanagementapi.GetConnectionOutput, error)
+}
+
+// ConnectionProber reports whether a websocket connection is still open
+type ConnectionProber func(ctx context.Context, connectionID string) (bool, error)
+
+func NewConnectionProber(gateway ConnectionGetter) ConnectionProber {
+	return func(ctx context.Context, connectionID string) (bool, error) {
+		_, err := gateway.GetConnectionWithContext(ctx, &apigatewaymanagementapi.GetConnectionInput{
+			ConnectionId: aws.String(connectionID),
+		})
+		if err != nil {
+			if aerr, ok := err.(awserr.Error); ok && aerr.Code() == apigatewaymanagementapi.ErrCodeGoneException {
+				return false, nil
+			}
+			return false, errors.WithStack(err)
+		}
+		return true, nil
+	}
+}
@@ -0,0 +1,73 @@
+package api_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/apigatewaymanagementapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/jonsabados/pointypoints/api"
+	"github.com/jonsabados/pointypoints/session/testutil"
+)
+
+func Test_NewConnectionProber(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		getErr        error
+		expected      bool
+		expectedError string
+	}{
+		{
+			desc:     "connected",
+			expected: true,
+		},
+		{
+			desc:     "gone",
+			getErr:   awserr.New(apigatewaymanagementapi.ErrCodeGoneException, "gone", nil),
+			expected: false,
+		},
+		{
+			desc:          "error",
+			getErr:        errors.New("kablam"),
+			expectedError: "kablam",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			asserter := assert.New(t)
+
+			ctx := testutil.NewTestContext()
+			gateway := &MockConnectionGetter{}
+			gateway.On("GetConnectionWithContext", ctx, &apigatewaymanagementapi.GetConnectionInput{
+				ConnectionId: aws.String("abc"),
+			}, emptyOpts).Return(&apigatewaymanagementapi.GetConnectionOutput{}, tc.getErr)
+
+			res, err := api.NewConnectionProber(gateway)(ctx, "abc")
+			if tc.expectedError != "" {
+				asserter.EqualError(err, tc.expectedError)
+			} else {
+				asserter.NoError(err)
+			}
+			asserter.Equal(tc.expected, res)
+		})
+	}
+}
+
+type MockConnectionGetter struct {
+	mock.Mock
+}
+
+func (m *MockConnectionGetter) GetConnectionWithContext(ctx aws.Context, input *apigatewaymanagementapi.GetConnectionInput, opts ...request.Option) (*apigatewaymanagementapi.GetConnectionOutput, error) {
+	args := m.Called(ctx, input, opts)
+	ret := args.Get(0)
+	if ret == nil {
+		return nil, args.Error(1)
+	}
+	return ret.(*apigatewaymanagementapi.GetConnectionOutput), args.Error(1)
+}
@@ -14,6 +14,11 @@ type MessageType string
 const (
 	SessionUpdated = MessageType("SESSION_UPDATED")
 	Ping           = MessageType("PING")
+	Chat           = MessageType("CHAT")
+	Reaction       = MessageType("REACTION")
+	ChatCleared    = MessageType("CHAT_CLEARED")
+	RateLimited    = MessageType("RATE_LIMITED")
+	Announcement   = MessageType("ANNOUNCEMENT")
 )
 
 type ConnectionPoster interface {
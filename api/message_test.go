@@ -55,6 +55,7 @@ func Test_NewMessageDispatcher(t *testing.T) {
 				Type: "SESSION_UPDATED",
 				Body: session.CompleteSessionView{
 					SessionID:             "123",
+					Type:                  session.SessionTypeEstimate,
 					VotesShown:            true,
 					FacilitatorSessionKey: "123345",
 					Facilitator: session.User{
@@ -62,15 +63,18 @@ func Test_NewMessageDispatcher(t *testing.T) {
 						Name:        "b",
 						Handle:      "c",
 						CurrentVote: aws.String("123"),
+						HasVoted:    true,
 						SocketID:    "123",
 					},
 					FacilitatorPoints: false,
+					Round:             1,
 					Participants: []session.User{
 						{
 							UserID:      "f",
 							Name:        "g",
 							Handle:      "h",
 							CurrentVote: aws.String("521"),
+							HasVoted:    true,
 							SocketID:    "987",
 						},
 					},
@@ -78,6 +82,40 @@ func Test_NewMessageDispatcher(t *testing.T) {
 			},
 			"fixture/sessionUpdate.json",
 		},
+		{
+			"participant view indicates who has voted without revealing votes",
+			api.Message{
+				Type: "SESSION_UPDATED",
+				Body: session.ToParticipantView(session.CompleteSessionView{
+					SessionID:             "123",
+					Type:                  session.SessionTypeEstimate,
+					VotesShown:            false,
+					FacilitatorSessionKey: "123345",
+					Facilitator: session.User{
+						UserID:   "a",
+						Name:     "b",
+						SocketID: "123",
+					},
+					Round: 1,
+					Participants: []session.User{
+						{
+							UserID:      "f",
+							Handle:      "h",
+							CurrentVote: aws.String("521"),
+							HasVoted:    true,
+							SocketID:    "987",
+						},
+						{
+							UserID:   "i",
+							Handle:   "j",
+							SocketID: "654",
+							Status:   session.PresenceIdle,
+						},
+					},
+				}, "123"),
+			},
+			"fixture/participantSessionUpdate.json",
+		},
 	}
 
 	for _, tc := range testCases {
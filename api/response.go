@@ -7,6 +7,9 @@ import (
 	"github.com/aws/aws-lambda-go/lambdacontext"
 	"github.com/google/uuid"
 	"net/http"
+	"sort"
+	"strconv"
+	"strings"
 )
 
 type FieldValidationError struct {
@@ -31,6 +34,16 @@ func NewSuccessResponse(ctx context.Context, baseHeaders map[string]string, resu
 	}, responseHeaders(baseHeaders), http.StatusOK)
 }
 
+// NewContentResponse is for the odd endpoint that returns something other than the standard json envelope, such as a
+// file download
+func NewContentResponse(ctx context.Context, baseHeaders map[string]string, contentType string, body string) events.APIGatewayProxyResponse {
+	return events.APIGatewayProxyResponse{
+		StatusCode: http.StatusOK,
+		Body:       body,
+		Headers:    contentHeaders(baseHeaders, contentType),
+	}
+}
+
 func NewNoContentResponse(ctx context.Context, baseHeaders map[string]string) events.APIGatewayProxyResponse {
 	return events.APIGatewayProxyResponse{
 		StatusCode: http.StatusNoContent,
@@ -80,10 +93,95 @@ func wrapResponse(r Response, headers map[string]string, statusCode int) events.
 }
 
 func responseHeaders(baseHeaders map[string]string) map[string]string {
+	return contentHeaders(baseHeaders, "application/json")
+}
+
+func contentHeaders(baseHeaders map[string]string, contentType string) map[string]string {
 	ret := make(map[string]string)
 	for k, v := range baseHeaders {
 		ret[k] = v
 	}
-	ret["content-type"] = "application/json"
+	ret["content-type"] = contentType
 	return ret
 }
+
+type acceptedType struct {
+	mediaRange string
+	quality    float64
+}
+
+// NegotiateContentType picks the offered content type the client most prefers based on its Accept header. Clients that
+// don't send one get the first offer, and an empty string is returned if nothing offered is acceptable.
+func NegotiateContentType(headers map[string]string, offered ...string) string {
+	accept := ""
+	for k, v := range headers {
+		if strings.ToLower(k) == "accept" {
+			accept = v
+		}
+	}
+	if strings.TrimSpace(accept) == "" {
+		if len(offered) == 0 {
+			return ""
+		}
+		return offered[0]
+	}
+
+	accepted := parseAccept(accept)
+	// a type explicitly refused takes precedence over any wildcard that would otherwise let it through
+	refused := make(map[string]bool)
+	for _, a := range accepted {
+		if a.quality <= 0 {
+			refused[a.mediaRange] = true
+		}
+	}
+	for _, a := range accepted {
+		if a.quality <= 0 {
+			continue
+		}
+		for _, o := range offered {
+			if !refused[strings.ToLower(o)] && mediaRangeMatches(a.mediaRange, o) {
+				return o
+			}
+		}
+	}
+	return ""
+}
+
+func parseAccept(accept string) []acceptedType {
+	ret := make([]acceptedType, 0)
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+		a := acceptedType{
+			mediaRange: strings.ToLower(strings.TrimSpace(params[0])),
+			quality:    1,
+		}
+		if a.mediaRange == "" {
+			continue
+		}
+		for _, p := range params[1:] {
+			kv := strings.SplitN(strings.TrimSpace(p), "=", 2)
+			if len(kv) == 2 && strings.ToLower(kv[0]) == "q" {
+				if q, err := strconv.ParseFloat(kv[1], 64); err == nil {
+					a.quality = q
+				}
+			}
+		}
+		ret = append(ret, a)
+	}
+	// stable so that equally weighted types keep the order the client listed them in
+	sort.SliceStable(ret, func(i, j int) bool {
+		return ret[i].quality > ret[j].quality
+	})
+	return ret
+}
+
+func mediaRangeMatches(mediaRange string, contentType string) bool {
+	contentType = strings.ToLower(contentType)
+	if mediaRange == "*/*" || mediaRange == contentType {
+		return true
+	}
+	if strings.HasSuffix(mediaRange, "/*") {
+		return strings.HasPrefix(contentType, strings.TrimSuffix(mediaRange, "*"))
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package api_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jonsabados/pointypoints/api"
+)
+
+func TestNegotiateContentType(t *testing.T) {
+	offered := []string{"application/json", "text/csv", "text/markdown"}
+
+	testCases := []struct {
+		name     string
+		headers  map[string]string
+		expected string
+	}{
+		{
+			name:     "no accept header",
+			headers:  map[string]string{},
+			expected: "application/json",
+		},
+		{
+			name:     "exact match",
+			headers:  map[string]string{"Accept": "text/csv"},
+			expected: "text/csv",
+		},
+		{
+			name:     "header case ignored",
+			headers:  map[string]string{"accept": "text/markdown"},
+			expected: "text/markdown",
+		},
+		{
+			name:     "quality respected",
+			headers:  map[string]string{"Accept": "text/csv;q=0.5, text/markdown"},
+			expected: "text/markdown",
+		},
+		{
+			name:     "wildcard subtype",
+			headers:  map[string]string{"Accept": "text/*"},
+			expected: "text/csv",
+		},
+		{
+			name:     "anything goes",
+			headers:  map[string]string{"Accept": "*/*"},
+			expected: "application/json",
+		},
+		{
+			name:     "explicitly refused",
+			headers:  map[string]string{"Accept": "text/csv;q=0, text/*"},
+			expected: "text/markdown",
+		},
+		{
+			name:     "nothing acceptable",
+			headers:  map[string]string{"Accept": "application/pdf"},
+			expected: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, api.NegotiateContentType(tc.headers, offered...))
+		})
+	}
+}
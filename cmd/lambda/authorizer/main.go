@@ -51,10 +51,11 @@ func (a *authCallback) AuthPass(p goauth.Principal) error {
 	} else if saved.Name != p.Name || saved.Email != p.Email {
 		// unsure if you can update name or email with google accounts... but lets support it just in case.
 		err := a.writeProfile(a.ctx, profile.Profile{
-			UserID: p.UserID,
-			Email:  p.Email,
-			Name:   p.Name,
-			Handle: saved.Handle,
+			UserID:        p.UserID,
+			Email:         p.Email,
+			Name:          p.Name,
+			Handle:        saved.Handle,
+			Notifications: saved.Notifications,
 		})
 		if err != nil {
 			return errors.Wrap(err, "error writing profile")
@@ -2,7 +2,9 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
+	"time"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
@@ -11,9 +13,16 @@ import (
 	"github.com/jonsabados/pointypoints/api"
 	"github.com/jonsabados/pointypoints/lambdautil"
 	"github.com/jonsabados/pointypoints/logging"
+	"github.com/jonsabados/pointypoints/session"
 )
 
-func NewHandler(prepareLogs logging.Preparer, dispatch api.MessageDispatcher) func(ctx context.Context, request events.APIGatewayWebsocketProxyRequest) (events.APIGatewayProxyResponse, error) {
+type pingRequest struct {
+	// Active is set by clients when the person using them has interacted with the page since the last ping, pings
+	// also keep the connection alive so an open but ignored tab still sends them
+	Active bool `json:"active"`
+}
+
+func NewHandler(prepareLogs logging.Preparer, dispatch api.MessageDispatcher, recordPresence session.PresenceRecorder, loadSession session.Loader, notifyParticipants session.ChangeNotifier) func(ctx context.Context, request events.APIGatewayWebsocketProxyRequest) (events.APIGatewayProxyResponse, error) {
 	return func(ctx context.Context, request events.APIGatewayWebsocketProxyRequest) (events.APIGatewayProxyResponse, error) {
 		ctx = prepareLogs(ctx)
 
@@ -30,14 +39,55 @@ func NewHandler(prepareLogs logging.Preparer, dispatch api.MessageDispatcher) fu
 		if err != nil {
 			zerolog.Ctx(ctx).Error().Err(err).Msg("error dispatching message")
 		}
+
+		r := new(pingRequest)
+		err = json.Unmarshal([]byte(request.Body), r)
+		if err != nil {
+			zerolog.Ctx(ctx).Warn().Err(err).Msg("error reading ping body")
+		}
+		if r.Active {
+			recordActivity(ctx, request.RequestContext.ConnectionID, recordPresence, loadSession, notifyParticipants)
+		}
+
 		return events.APIGatewayProxyResponse{
 			StatusCode: http.StatusNoContent,
 		}, nil
 	}
 }
 
+func recordActivity(ctx context.Context, connectionID string, recordPresence session.PresenceRecorder, loadSession session.Loader, notifyParticipants session.ChangeNotifier) {
+	changed, err := recordPresence(ctx, connectionID, time.Now())
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("error recording presence")
+		return
+	}
+	// only people coming back from idle or away change what everyone else sees
+	for _, sessionID := range changed {
+		sess, err := loadSession(ctx, sessionID)
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Str("sessionID", sessionID).Msg("error loading session")
+			continue
+		}
+		if sess == nil {
+			continue
+		}
+		err = notifyParticipants(ctx, *sess)
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Str("sessionID", sessionID).Msg("error notifying participants")
+		}
+	}
+}
+
 func main() {
 	lambdautil.CoreStartup()
 	logPreparer := logging.NewPreparer()
-	lambda.Start(NewHandler(logPreparer, lambdautil.NewProdMessageDispatcher()))
+	sess := lambdautil.DefaultAWSConfig()
+
+	dynamo := lambdautil.NewDynamoClient(sess)
+	dispatcher := lambdautil.NewProdMessageDispatcher()
+	recordPresence := session.NewPresenceRecorder(dynamo, lambdautil.SessionTable, lambdautil.SessionSocketIndex, lambdautil.SessionTimeout)
+	loader := session.NewLoader(dynamo, lambdautil.SessionTable)
+	notifier := session.NewChangeNotifier(dynamo, lambdautil.SessionTable, dispatcher)
+
+	lambda.Start(NewHandler(logPreparer, dispatcher, recordPresence, loader, notifier))
 }
@@ -34,9 +34,10 @@ func NewHandler(prepareLogs logging.Preparer, corsHeaders cors.ResponseHeaderBui
 			return api.NewInternalServerError(ctx, corsHeaders(ctx, request.Headers)), nil
 		}
 		return api.NewSuccessResponse(ctx, corsHeaders(ctx, request.Headers), profile.UserView{
-			Email:  p.Email,
-			Name:   p.Name,
-			Handle: p.Handle,
+			Email:         p.Email,
+			Name:          p.Name,
+			Handle:        p.Handle,
+			Notifications: p.Notifications,
 		}), nil
 	}
 }
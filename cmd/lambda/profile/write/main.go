@@ -37,10 +37,11 @@ func NewHandler(prepareLogs logging.Preparer, corsHeaders cors.ResponseHeaderBui
 		}
 
 		err = writeProfile(ctx, profile.Profile{
-			UserID: principal.UserID,
-			Email:  principal.Email,
-			Name:   principal.Name,
-			Handle: input.Handle,
+			UserID:        principal.UserID,
+			Email:         principal.Email,
+			Name:          principal.Name,
+			Handle:        input.Handle,
+			Notifications: input.Notifications,
 		})
 		if err != nil {
 			zerolog.Ctx(ctx).Error().Err(err).Msg("error writing profile")
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/jonsabados/goauth/aws"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+
+	"github.com/jonsabados/pointypoints/api"
+	"github.com/jonsabados/pointypoints/cors"
+	"github.com/jonsabados/pointypoints/lambdautil"
+	"github.com/jonsabados/pointypoints/logging"
+	"github.com/jonsabados/pointypoints/room"
+	"github.com/jonsabados/pointypoints/team"
+)
+
+func NewHandler(prepareLogs logging.Preparer, corsHeaders cors.ResponseHeaderBuilder, loadTeam team.Loader, createRoom room.Creator) func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		ctx = prepareLogs(ctx)
+		r := new(room.CreateRequest)
+		err := json.Unmarshal([]byte(request.Body), r)
+		if err != nil {
+			zerolog.Ctx(ctx).Warn().Err(err).Msg("error reading room request body")
+			return api.NewInternalServerError(ctx, corsHeaders(ctx, request.Headers)), nil
+		}
+		if fieldErrors := room.Validate(*r); len(fieldErrors) > 0 {
+			return api.NewValidationFailureResponse(ctx, corsHeaders(ctx, request.Headers), api.ValidationError{
+				FieldErrors: fieldErrors,
+			}), nil
+		}
+
+		principal, err := aws.ExtractPrincipal(request)
+		if err != nil {
+			zerolog.Ctx(ctx).Warn().Err(err).Msg("error extracting principal")
+			return api.NewInternalServerError(ctx, corsHeaders(ctx, request.Headers)), nil
+		}
+
+		if r.TeamID != "" {
+			t, err := loadTeam(ctx, r.TeamID)
+			if err != nil {
+				zerolog.Ctx(ctx).Error().Err(err).Msg("error reading team")
+				return api.NewInternalServerError(ctx, corsHeaders(ctx, request.Headers)), nil
+			}
+			if t == nil || !t.CanFacilitate(principal.Email) {
+				zerolog.Ctx(ctx).Warn().Str("teamID", r.TeamID).Str("userID", principal.UserID).Msg("attempt to create team room by non facilitator")
+				return api.NewPermissionDeniedResponse(ctx, corsHeaders(ctx, request.Headers)), nil
+			}
+		}
+
+		toCreate := room.NewRoom(principal, *r, time.Now())
+		err = createRoom(ctx, toCreate)
+		if errors.Is(err, room.ErrorSlugTaken) {
+			return api.NewValidationFailureResponse(ctx, corsHeaders(ctx, request.Headers), api.ValidationError{
+				FieldErrors: []api.FieldValidationError{
+					{Field: "slug", Error: "is already taken"},
+				},
+			}), nil
+		}
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("error saving room")
+			return api.NewInternalServerError(ctx, corsHeaders(ctx, request.Headers)), nil
+		}
+		return api.NewSuccessResponse(ctx, corsHeaders(ctx, request.Headers), toCreate), nil
+	}
+}
+
+func main() {
+	lambdautil.CoreStartup()
+
+	logPreparer := logging.NewPreparer()
+	sess := lambdautil.DefaultAWSConfig()
+
+	dynamo := lambdautil.NewDynamoClient(sess)
+	teamLoader := team.NewLoader(dynamo, lambdautil.TeamTable)
+	creator := room.NewCreator(dynamo, lambdautil.RoomTable)
+
+	allowedDomains := lambdautil.AllowedCORSOrigins()
+
+	lambda.Start(NewHandler(logPreparer, cors.NewResponseHeaderBuilder(allowedDomains), teamLoader, creator))
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/rs/zerolog"
+
+	"github.com/jonsabados/pointypoints/api"
+	"github.com/jonsabados/pointypoints/cors"
+	"github.com/jonsabados/pointypoints/lambdautil"
+	"github.com/jonsabados/pointypoints/logging"
+	"github.com/jonsabados/pointypoints/room"
+	"github.com/jonsabados/pointypoints/session"
+)
+
+func NewHandler(prepareLogs logging.Preparer, corsHeaders cors.ResponseHeaderBuilder, loadRoom room.Loader, loadSession session.Loader) func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		ctx = prepareLogs(ctx)
+
+		slug := request.PathParameters["room"]
+		r, err := loadRoom(ctx, slug)
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("error reading room")
+			return api.NewInternalServerError(ctx, corsHeaders(ctx, request.Headers)), nil
+		}
+		if r == nil {
+			zerolog.Ctx(ctx).Warn().Str("room", slug).Msg("room not found")
+			return api.NewPermissionDeniedResponse(ctx, corsHeaders(ctx, request.Headers)), nil
+		}
+
+		// sessions expire out from under rooms, in which case there is nothing to land in until the next one starts
+		if r.SessionID != "" {
+			sess, err := loadSession(ctx, r.SessionID)
+			if err != nil {
+				zerolog.Ctx(ctx).Error().Err(err).Msg("error reading session")
+				return api.NewInternalServerError(ctx, corsHeaders(ctx, request.Headers)), nil
+			}
+			if sess == nil {
+				r.SessionID = ""
+			}
+		}
+		return api.NewSuccessResponse(ctx, corsHeaders(ctx, request.Headers), r), nil
+	}
+}
+
+func main() {
+	lambdautil.CoreStartup()
+
+	logPreparer := logging.NewPreparer()
+	sess := lambdautil.DefaultAWSConfig()
+
+	dynamo := lambdautil.NewDynamoClient(sess)
+	roomLoader := room.NewLoader(dynamo, lambdautil.RoomTable)
+	sessionLoader := session.NewLoader(dynamo, lambdautil.SessionTable)
+
+	allowedDomains := lambdautil.AllowedCORSOrigins()
+
+	lambda.Start(NewHandler(logPreparer, cors.NewResponseHeaderBuilder(allowedDomains), roomLoader, sessionLoader))
+}
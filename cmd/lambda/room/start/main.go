@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/jonsabados/goauth/aws"
+	"github.com/rs/zerolog"
+
+	"github.com/jonsabados/pointypoints/api"
+	"github.com/jonsabados/pointypoints/cors"
+	"github.com/jonsabados/pointypoints/lambdautil"
+	"github.com/jonsabados/pointypoints/launch"
+	"github.com/jonsabados/pointypoints/logging"
+	"github.com/jonsabados/pointypoints/profile"
+	"github.com/jonsabados/pointypoints/room"
+	"github.com/jonsabados/pointypoints/session"
+	"github.com/jonsabados/pointypoints/team"
+)
+
+func NewHandler(prepareLogs logging.Preparer, corsHeaders cors.ResponseHeaderBuilder, loadRoom room.Loader, loadTeam team.Loader, startSession launch.Starter, switchSession room.SessionSwitcher) func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		ctx = prepareLogs(ctx)
+		toStart := new(session.StartRequest)
+		err := json.Unmarshal([]byte(request.Body), toStart)
+		if err != nil {
+			zerolog.Ctx(ctx).Warn().Err(err).Msg("error session start reading request body")
+			return api.NewInternalServerError(ctx, corsHeaders(ctx, request.Headers)), nil
+		}
+		if errors := session.ValidateStart(toStart, time.Now()); len(errors) > 0 {
+			return api.NewValidationFailureResponse(ctx, corsHeaders(ctx, request.Headers), api.ValidationError{
+				Errors: errors,
+			}), nil
+		}
+
+		principal, err := aws.ExtractPrincipal(request)
+		if err != nil {
+			zerolog.Ctx(ctx).Warn().Err(err).Msg("error extracting principal")
+			return api.NewInternalServerError(ctx, corsHeaders(ctx, request.Headers)), nil
+		}
+
+		slug := request.PathParameters["room"]
+		r, err := loadRoom(ctx, slug)
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("error reading room")
+			return api.NewInternalServerError(ctx, corsHeaders(ctx, request.Headers)), nil
+		}
+		if r == nil {
+			zerolog.Ctx(ctx).Warn().Str("room", slug).Msg("room not found")
+			return api.NewPermissionDeniedResponse(ctx, corsHeaders(ctx, request.Headers)), nil
+		}
+
+		var t *team.Team
+		if r.TeamID != "" {
+			t, err = loadTeam(ctx, r.TeamID)
+			if err != nil {
+				zerolog.Ctx(ctx).Error().Err(err).Msg("error reading team")
+				return api.NewInternalServerError(ctx, corsHeaders(ctx, request.Headers)), nil
+			}
+		}
+		if !room.CanStart(*r, t, principal) {
+			zerolog.Ctx(ctx).Warn().Str("room", slug).Str("userID", principal.UserID).Msg("attempt to start room session by non owner")
+			return api.NewPermissionDeniedResponse(ctx, corsHeaders(ctx, request.Headers)), nil
+		}
+
+		sess, err := startSession(ctx, principal, t, *toStart)
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("error starting session")
+			return api.NewInternalServerError(ctx, corsHeaders(ctx, request.Headers)), nil
+		}
+
+		err = switchSession(ctx, slug, sess.SessionID)
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("error pointing room at new session")
+			return api.NewInternalServerError(ctx, corsHeaders(ctx, request.Headers)), nil
+		}
+
+		return api.NewSuccessResponse(ctx, corsHeaders(ctx, request.Headers), sess), nil
+	}
+}
+
+func main() {
+	lambdautil.CoreStartup()
+
+	logPreparer := logging.NewPreparer()
+	sess := lambdautil.DefaultAWSConfig()
+
+	statsFactory := profile.NewStatsUpdateFactory(lambdautil.ProfileTable)
+
+	dynamo := lambdautil.NewDynamoClient(sess)
+	roomLoader := room.NewLoader(dynamo, lambdautil.RoomTable)
+	teamLoader := team.NewLoader(dynamo, lambdautil.TeamTable)
+	starter := launch.NewStarter(session.NewStarter(dynamo, lambdautil.SessionTable, lambdautil.SessionTimeout, statsFactory), lambdautil.NewWebhookPublisher(sess), lambdautil.NewNotifier(dynamo))
+	switcher := room.NewSessionSwitcher(dynamo, lambdautil.RoomTable)
+
+	allowedDomains := lambdautil.AllowedCORSOrigins()
+
+	lambda.Start(NewHandler(logPreparer, cors.NewResponseHeaderBuilder(allowedDomains), roomLoader, teamLoader, starter, switcher))
+}
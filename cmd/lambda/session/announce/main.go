@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/rs/zerolog"
+
+	"github.com/jonsabados/pointypoints/api"
+	"github.com/jonsabados/pointypoints/cors"
+	"github.com/jonsabados/pointypoints/lambdautil"
+	"github.com/jonsabados/pointypoints/logging"
+	"github.com/jonsabados/pointypoints/session"
+)
+
+func NewHandler(prepareLogs logging.Preparer, corsHeaders cors.ResponseHeaderBuilder, loadSession session.Loader, announce session.Announcer) func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		ctx = prepareLogs(ctx)
+
+		r := new(session.AnnouncementRequest)
+		err := json.Unmarshal([]byte(request.Body), r)
+		if err != nil {
+			zerolog.Ctx(ctx).Warn().Err(err).Msg("error reading announcement request body")
+			return api.NewInternalServerError(ctx, corsHeaders(ctx, request.Headers)), nil
+		}
+
+		sessionID := request.PathParameters["session"]
+		sess, err := loadSession(ctx, sessionID)
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("error reading session")
+			return api.NewPermissionDeniedResponse(ctx, corsHeaders(ctx, request.Headers)), nil
+		}
+		if sess == nil {
+			zerolog.Ctx(ctx).Warn().Str("sessionID", sessionID).Msg("session not found")
+			return api.NewPermissionDeniedResponse(ctx, corsHeaders(ctx, request.Headers)), nil
+		}
+		if facilitatorKey := api.FacilitatorKey(request.Headers); sess.FacilitatorSessionKey != facilitatorKey {
+			zerolog.Ctx(ctx).Warn().Str("sessionID", sessionID).Msg("attempt to announce with incorrect facilitator key")
+			return api.NewPermissionDeniedResponse(ctx, corsHeaders(ctx, request.Headers)), nil
+		}
+
+		_, err = announce(ctx, *sess, *r)
+		if err == session.ErrorInvalidAnnouncement {
+			return api.NewValidationFailureResponse(ctx, corsHeaders(ctx, request.Headers), api.ValidationError{
+				FieldErrors: []api.FieldValidationError{
+					{
+						Field: "text",
+						Error: fmt.Sprintf("must be between 1 and %d characters", session.MaxAnnouncementLen),
+					},
+				},
+			}), nil
+		}
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("error sending announcement")
+			return api.NewInternalServerError(ctx, corsHeaders(ctx, request.Headers)), nil
+		}
+		return api.NewNoContentResponse(ctx, corsHeaders(ctx, request.Headers)), nil
+	}
+}
+
+func main() {
+	lambdautil.CoreStartup()
+
+	logPreparer := logging.NewPreparer()
+	sess := lambdautil.DefaultAWSConfig()
+
+	dynamo := lambdautil.NewDynamoClient(sess)
+	dispatcher := lambdautil.NewProdMessageDispatcher()
+	loader := session.NewLoader(dynamo, lambdautil.SessionTable)
+	notifier := session.NewChangeNotifier(dynamo, lambdautil.SessionTable, dispatcher)
+	announcer := session.NewAnnouncer(dynamo, lambdautil.SessionTable, dispatcher, notifier)
+
+	allowedDomains := lambdautil.AllowedCORSOrigins()
+
+	lambda.Start(NewHandler(logPreparer, cors.NewResponseHeaderBuilder(allowedDomains), loader, announcer))
+}
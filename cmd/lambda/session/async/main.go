@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/jonsabados/goauth/aws"
+	"github.com/rs/zerolog"
+
+	"github.com/jonsabados/pointypoints/api"
+	"github.com/jonsabados/pointypoints/cors"
+	"github.com/jonsabados/pointypoints/lambdautil"
+	"github.com/jonsabados/pointypoints/logging"
+	"github.com/jonsabados/pointypoints/session"
+)
+
+func NewHandler(prepareLogs logging.Preparer, corsHeaders cors.ResponseHeaderBuilder, loadSession session.Loader) func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		ctx = prepareLogs(ctx)
+
+		sessionID := request.PathParameters["session"]
+		sess, err := loadSession(ctx, sessionID)
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("error reading session")
+			return api.NewPermissionDeniedResponse(ctx, corsHeaders(ctx, request.Headers)), nil
+		}
+		if sess == nil || sess.Deadline == nil {
+			zerolog.Ctx(ctx).Warn().Str("sessionID", sessionID).Msg("async session not found")
+			return api.NewPermissionDeniedResponse(ctx, corsHeaders(ctx, request.Headers)), nil
+		}
+
+		principal, err := aws.ExtractPrincipal(request)
+		if err != nil {
+			zerolog.Ctx(ctx).Warn().Err(err).Msg("error extracting principal")
+			return api.NewInternalServerError(ctx, corsHeaders(ctx, request.Headers)), nil
+		}
+
+		voterID := session.AsyncVoterID(principal, request.QueryStringParameters["userId"])
+		return api.NewSuccessResponse(ctx, corsHeaders(ctx, request.Headers), session.ToAsyncView(*sess, voterID)), nil
+	}
+}
+
+func main() {
+	lambdautil.CoreStartup()
+
+	logPreparer := logging.NewPreparer()
+	sess := lambdautil.DefaultAWSConfig()
+
+	dynamo := lambdautil.NewDynamoClient(sess)
+	loader := session.NewLoader(dynamo, lambdautil.SessionTable)
+
+	allowedDomains := lambdautil.AllowedCORSOrigins()
+
+	lambda.Start(NewHandler(logPreparer, cors.NewResponseHeaderBuilder(allowedDomains), loader))
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/jonsabados/goauth/aws"
+	"github.com/rs/zerolog"
+
+	"github.com/jonsabados/pointypoints/api"
+	"github.com/jonsabados/pointypoints/cors"
+	"github.com/jonsabados/pointypoints/lambdautil"
+	"github.com/jonsabados/pointypoints/logging"
+	"github.com/jonsabados/pointypoints/profile"
+	"github.com/jonsabados/pointypoints/session"
+)
+
+func NewHandler(prepareLogs logging.Preparer, corsHeaders cors.ResponseHeaderBuilder, loadSession session.Loader, recordVote session.AsyncVoteRecorder, notifyParticipants session.ChangeNotifier) func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		ctx = prepareLogs(ctx)
+		r := new(session.AsyncVoteRequest)
+		err := json.Unmarshal([]byte(request.Body), r)
+		if err != nil {
+			zerolog.Ctx(ctx).Warn().Err(err).Msg("error reading async vote request body")
+			return api.NewInternalServerError(ctx, corsHeaders(ctx, request.Headers)), nil
+		}
+
+		principal, err := aws.ExtractPrincipal(request)
+		if err != nil {
+			zerolog.Ctx(ctx).Warn().Err(err).Msg("error extracting principal")
+			return api.NewInternalServerError(ctx, corsHeaders(ctx, request.Headers)), nil
+		}
+		voterID := session.AsyncVoterID(principal, r.UserID)
+
+		r.Rationale = strings.TrimSpace(r.Rationale)
+		var fieldErrors []api.FieldValidationError
+		if voterID == "" {
+			fieldErrors = append(fieldErrors, api.FieldValidationError{Field: "userId", Error: "is required"})
+		}
+		if r.Name == "" && r.Handle == "" {
+			fieldErrors = append(fieldErrors, api.FieldValidationError{Field: "name", Error: "name or handle is required"})
+		}
+		if !r.Confidence.Valid() {
+			fieldErrors = append(fieldErrors, api.FieldValidationError{Field: "confidence", Error: "must be one of low, medium or high"})
+		}
+		if utf8.RuneCountInString(r.Rationale) > session.MaxRationaleLen {
+			fieldErrors = append(fieldErrors, api.FieldValidationError{Field: "rationale", Error: fmt.Sprintf("must be at most %d characters", session.MaxRationaleLen)})
+		}
+		if len(fieldErrors) > 0 {
+			return api.NewValidationFailureResponse(ctx, corsHeaders(ctx, request.Headers), api.ValidationError{
+				FieldErrors: fieldErrors,
+			}), nil
+		}
+
+		sessionID := request.PathParameters["session"]
+		sess, err := loadSession(ctx, sessionID)
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("error reading session")
+			return api.NewPermissionDeniedResponse(ctx, corsHeaders(ctx, request.Headers)), nil
+		}
+		if sess == nil {
+			zerolog.Ctx(ctx).Warn().Str("sessionID", sessionID).Msg("session not found")
+			return api.NewPermissionDeniedResponse(ctx, corsHeaders(ctx, request.Headers)), nil
+		}
+
+		if sess.Deadline == nil {
+			return api.NewValidationFailureResponse(ctx, corsHeaders(ctx, request.Headers), api.ValidationError{
+				Errors: []string{"session is not asynchronous"},
+			}), nil
+		}
+		if session.AsyncVotingClosed(*sess, time.Now()) {
+			return api.NewValidationFailureResponse(ctx, corsHeaders(ctx, request.Headers), api.ValidationError{
+				Errors: []string{"voting has closed"},
+			}), nil
+		}
+
+		storyKey := request.PathParameters["story"]
+		if session.FindStory(sess.Stories, storyKey) == nil {
+			return api.NewValidationFailureResponse(ctx, corsHeaders(ctx, request.Headers), api.ValidationError{
+				Errors: []string{"story is not part of this session"},
+			}), nil
+		}
+
+		if !session.ValidVote(*sess, r.Vote) {
+			return api.NewValidationFailureResponse(ctx, corsHeaders(ctx, request.Headers), api.ValidationError{
+				FieldErrors: []api.FieldValidationError{
+					{Field: "vote", Error: fmt.Sprintf("must be one of %s", strings.Join(session.SessionDeck(*sess), ", "))},
+				},
+			}), nil
+		}
+
+		vote := session.AsyncVote{
+			StoryKey:   storyKey,
+			UserID:     voterID,
+			Name:       r.Name,
+			Handle:     r.Handle,
+			Confidence: r.Confidence,
+			Rationale:  r.Rationale,
+		}
+		// a blank vote takes back whatever was there before
+		if r.Vote != "" {
+			vote.Vote = &r.Vote
+		}
+
+		err = recordVote(ctx, principal, *sess, vote)
+		if err == session.ErrorVotingClosed {
+			return api.NewValidationFailureResponse(ctx, corsHeaders(ctx, request.Headers), api.ValidationError{
+				Errors: []string{"voting has closed"},
+			}), nil
+		}
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("error recording async vote")
+			return api.NewInternalServerError(ctx, corsHeaders(ctx, request.Headers)), nil
+		}
+
+		// the facilitator may be watching the counts tick up
+		sess, err = loadSession(ctx, sessionID)
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("error reading session")
+			return api.NewInternalServerError(ctx, corsHeaders(ctx, request.Headers)), nil
+		}
+		if sess != nil {
+			err = notifyParticipants(ctx, *sess)
+			if err != nil {
+				zerolog.Ctx(ctx).Error().Err(err).Msg("error notifying participants")
+			}
+		}
+
+		return api.NewNoContentResponse(ctx, corsHeaders(ctx, request.Headers)), nil
+	}
+}
+
+func main() {
+	lambdautil.CoreStartup()
+
+	logPreparer := logging.NewPreparer()
+	sess := lambdautil.DefaultAWSConfig()
+
+	statsFactory := profile.NewStatsUpdateFactory(lambdautil.ProfileTable)
+
+	dynamo := lambdautil.NewDynamoClient(sess)
+	loader := session.NewLoader(dynamo, lambdautil.SessionTable)
+	recorder := session.NewAsyncVoteRecorder(dynamo, lambdautil.SessionTable, lambdautil.SessionTimeout, statsFactory)
+	notifier := session.NewChangeNotifier(dynamo, lambdautil.SessionTable, lambdautil.NewProdMessageDispatcher())
+
+	allowedDomains := strings.Split(os.Getenv("ALLOWED_ORIGINS"), ",")
+
+	lambda.Start(NewHandler(logPreparer, cors.NewResponseHeaderBuilder(allowedDomains), loader, recorder, notifier))
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/rs/zerolog"
+
+	"github.com/jonsabados/pointypoints/api"
+	"github.com/jonsabados/pointypoints/lambdautil"
+	"github.com/jonsabados/pointypoints/logging"
+	"github.com/jonsabados/pointypoints/session"
+)
+
+func NewHandler(prepareLogs logging.Preparer, dispatch api.MessageDispatcher, sendChat session.ChatSender) func(ctx context.Context, request events.APIGatewayWebsocketProxyRequest) (events.APIGatewayProxyResponse, error) {
+	return func(ctx context.Context, request events.APIGatewayWebsocketProxyRequest) (events.APIGatewayProxyResponse, error) {
+		ctx = prepareLogs(ctx)
+		connectionID := request.RequestContext.ConnectionID
+
+		r := new(session.ChatRequest)
+		err := json.Unmarshal([]byte(request.Body), r)
+		if err != nil {
+			zerolog.Ctx(ctx).Warn().Err(err).Msg("error reading chat body")
+			return events.APIGatewayProxyResponse{StatusCode: http.StatusBadRequest}, nil
+		}
+
+		_, err = sendChat(ctx, r.SessionID, connectionID, r.Text)
+		switch err {
+		case nil:
+			return events.APIGatewayProxyResponse{StatusCode: http.StatusNoContent}, nil
+		case session.ErrorRateLimited:
+			err = dispatch(ctx, connectionID, api.Message{Type: api.RateLimited, Body: r})
+			if err != nil {
+				zerolog.Ctx(ctx).Warn().Err(err).Msg("error letting sender know they are rate limited")
+			}
+			return events.APIGatewayProxyResponse{StatusCode: http.StatusTooManyRequests}, nil
+		case session.ErrorInvalidChatMessage:
+			return events.APIGatewayProxyResponse{StatusCode: http.StatusBadRequest}, nil
+		case session.ErrorSessionNotFound, session.ErrorUserNotFound:
+			zerolog.Ctx(ctx).Warn().Err(err).Str("sessionID", r.SessionID).Msg("chat from connection not in session")
+			return events.APIGatewayProxyResponse{StatusCode: http.StatusForbidden}, nil
+		default:
+			zerolog.Ctx(ctx).Error().Err(err).Msg("error sending chat")
+			return events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}, nil
+		}
+	}
+}
+
+func main() {
+	lambdautil.CoreStartup()
+
+	logPreparer := logging.NewPreparer()
+	sess := lambdautil.DefaultAWSConfig()
+
+	dynamo := lambdautil.NewDynamoClient(sess)
+	dispatcher := lambdautil.NewProdMessageDispatcher()
+	loader := session.NewLoader(dynamo, lambdautil.SessionTable)
+	sender := session.NewChatSender(dynamo, lambdautil.SessionTable, loader, dispatcher, lambdautil.SessionTimeout)
+
+	lambda.Start(NewHandler(logPreparer, dispatcher, sender))
+}
@@ -12,9 +12,10 @@ import (
 	"github.com/jonsabados/pointypoints/lambdautil"
 	"github.com/jonsabados/pointypoints/logging"
 	"github.com/jonsabados/pointypoints/session"
+	"github.com/jonsabados/pointypoints/webhook"
 )
 
-func NewHandler(prepareLogs logging.Preparer, corsHeaders cors.ResponseHeaderBuilder, loadSession session.Loader, saveSession session.Saver) func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+func NewHandler(prepareLogs logging.Preparer, corsHeaders cors.ResponseHeaderBuilder, loadSession session.Loader, clearVotes session.VoteClearer, publish webhook.Publisher) func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
 	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
 		ctx = prepareLogs(ctx)
 
@@ -35,16 +36,23 @@ func NewHandler(prepareLogs logging.Preparer, corsHeaders cors.ResponseHeaderBui
 			return api.NewPermissionDeniedResponse(ctx, corsHeaders(ctx, request.Headers)), nil
 		}
 
-		sess.VotesShown = false
-		for i := 0; i < len(sess.Participants); i++ {
-			sess.Participants[i].CurrentVote = nil
+		if sess.Deadline != nil {
+			return api.NewValidationFailureResponse(ctx, corsHeaders(ctx, request.Headers), api.ValidationError{
+				Errors: []string{"votes can't be cleared in asynchronous sessions"},
+			}), nil
 		}
 
-		err = saveSession(ctx, *sess)
+		// clearing votes moves on to the next item, a fresh timer and round count go with it
+		cleared, err := clearVotes(ctx, *sess)
 		if err != nil {
-			zerolog.Ctx(ctx).Error().Err(err).Msg("error saving session")
+			zerolog.Ctx(ctx).Error().Err(err).Msg("error clearing votes")
 			return api.NewInternalServerError(ctx, corsHeaders(ctx, request.Headers)), nil
 		}
+
+		err = publish(ctx, webhook.NewVotesClearedEvent(cleared))
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("error publishing webhook event")
+		}
 		return api.NewNoContentResponse(ctx, corsHeaders(ctx, request.Headers)), nil
 	}
 }
@@ -58,9 +66,9 @@ func main() {
 	dynamo := lambdautil.NewDynamoClient(sess)
 	loader := session.NewLoader(dynamo, lambdautil.SessionTable)
 	notifier := session.NewChangeNotifier(dynamo, lambdautil.SessionTable, lambdautil.NewProdMessageDispatcher())
-	saveSess := session.NewSaver(dynamo, lambdautil.SessionTable, notifier, lambdautil.SessionTimeout)
+	clearer := session.NewVoteClearer(dynamo, lambdautil.SessionTable, notifier, lambdautil.SessionTimeout)
 
 	allowedDomains := lambdautil.AllowedCORSOrigins()
 
-	lambda.Start(NewHandler(logPreparer, cors.NewResponseHeaderBuilder(allowedDomains), loader, saveSess))
+	lambda.Start(NewHandler(logPreparer, cors.NewResponseHeaderBuilder(allowedDomains), loader, clearer, lambdautil.NewWebhookPublisher(sess)))
 }
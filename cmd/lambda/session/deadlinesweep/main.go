@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/rs/zerolog"
+
+	"github.com/jonsabados/pointypoints/lambdautil"
+	"github.com/jonsabados/pointypoints/logging"
+	"github.com/jonsabados/pointypoints/notify"
+	"github.com/jonsabados/pointypoints/session"
+	"github.com/jonsabados/pointypoints/team"
+	"github.com/jonsabados/pointypoints/webhook"
+)
+
+func NewHandler(prepareLogs logging.Preparer, sweep session.AsyncDeadlineSweeper) func(ctx context.Context, event events.CloudWatchEvent) error {
+	return func(ctx context.Context, event events.CloudWatchEvent) error {
+		ctx = prepareLogs(ctx)
+		err := sweep(ctx, time.Now())
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("error sweeping async deadlines")
+		}
+		// anything missed will be picked up on the next run, no point in having lambda retry
+		return nil
+	}
+}
+
+func main() {
+	lambdautil.CoreStartup()
+
+	logPreparer := logging.NewPreparer()
+	sess := lambdautil.DefaultAWSConfig()
+
+	dynamo := lambdautil.NewDynamoClient(sess)
+	loader := session.NewLoader(dynamo, lambdautil.SessionTable)
+	notifier := session.NewChangeNotifier(dynamo, lambdautil.SessionTable, lambdautil.NewProdMessageDispatcher())
+	publish := lambdautil.NewWebhookPublisher(sess)
+	loadTeam := team.NewLoader(dynamo, lambdautil.TeamTable)
+	sendNotifications := lambdautil.NewNotifier(dynamo)
+	// passing the deadline reveals votes, webhooks get told just like a live reveal and participants get the results
+	notifyRevealed := func(ctx context.Context, sess session.CompleteSessionView) error {
+		err := notifier(ctx, sess)
+		var t *team.Team
+		if sess.TeamID != "" {
+			var teamErr error
+			t, teamErr = loadTeam(ctx, sess.TeamID)
+			if teamErr != nil {
+				zerolog.Ctx(ctx).Error().Err(teamErr).Str("sessionID", sess.SessionID).Msg("error reading team")
+			}
+		}
+		if sendErr := sendNotifications(ctx, notify.KindResults, sess, notify.AsyncParticipants(sess, t)); sendErr != nil {
+			zerolog.Ctx(ctx).Error().Err(sendErr).Str("sessionID", sess.SessionID).Msg("error sending results")
+		}
+		if publishErr := publish(ctx, webhook.NewVotesRevealedEvent(sess)); publishErr != nil {
+			zerolog.Ctx(ctx).Error().Err(publishErr).Str("sessionID", sess.SessionID).Msg("error publishing webhook event")
+		}
+		return err
+	}
+	sweeper := session.NewAsyncDeadlineSweeper(dynamo, lambdautil.SessionTable, lambdautil.SessionScheduleIndex, loader, notifyRevealed)
+
+	lambda.Start(NewHandler(logPreparer, sweeper))
+}
@@ -11,19 +11,28 @@ import (
 	"github.com/jonsabados/pointypoints/lambdautil"
 	"github.com/jonsabados/pointypoints/logging"
 	"github.com/jonsabados/pointypoints/session"
+	"github.com/jonsabados/pointypoints/webhook"
 )
 
 func NewHandler(prepareLogs logging.Preparer, disconnect session.Disconnector) func(ctx context.Context, request events.APIGatewayWebsocketProxyRequest) (events.APIGatewayProxyResponse, error) {
 	return func(ctx context.Context, request events.APIGatewayWebsocketProxyRequest) (events.APIGatewayProxyResponse, error) {
 		ctx = prepareLogs(ctx)
 		zerolog.Ctx(ctx).Info().Interface("request", request).Msg("disconnect called")
-		err := disconnect(ctx, request.RequestContext.ConnectionID)
+		results, err := disconnect(ctx, request.RequestContext.ConnectionID)
 		if err != nil {
 			zerolog.Ctx(ctx).Error().Err(err).Msg("error disconnecting user")
 			return events.APIGatewayProxyResponse{
 				StatusCode: http.StatusInternalServerError,
 			}, nil
 		}
+		// the connection is gone either way, sessions that missed the news will catch up on their next change
+		for _, r := range results {
+			if r.Err != nil {
+				zerolog.Ctx(ctx).Warn().Err(r.Err).Str("sessionID", r.SessionID).Msg("error notifying session of disconnect")
+			} else if !r.Notified {
+				zerolog.Ctx(ctx).Info().Str("sessionID", r.SessionID).Msg("disconnected from session that no longer exists")
+			}
+		}
 		return events.APIGatewayProxyResponse{
 			StatusCode: http.StatusNoContent,
 		}, nil
@@ -39,7 +48,11 @@ func main() {
 	dynamo := lambdautil.NewDynamoClient(sess)
 	loader := session.NewLoader(dynamo, lambdautil.SessionTable)
 	notifier := session.NewChangeNotifier(dynamo, lambdautil.SessionTable, lambdautil.NewProdMessageDispatcher())
-	disconnect := session.NewDisconnector(dynamo, lambdautil.SessionTable, lambdautil.SessionSocketIndex, loader, notifier)
+	publish := lambdautil.NewWebhookPublisher(sess)
+	notifyDeparture := func(ctx context.Context, sess session.CompleteSessionView, departed session.User) error {
+		return publish(ctx, webhook.NewParticipantLeftEvent(sess, departed))
+	}
+	disconnect := session.NewDisconnector(dynamo, lambdautil.SessionTable, lambdautil.SessionSocketIndex, lambdautil.ResumeGracePeriod, loader, notifier, notifyDeparture)
 
 	lambda.Start(NewHandler(logPreparer, disconnect))
 }
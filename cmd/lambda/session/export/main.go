@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/rs/zerolog"
+
+	"github.com/jonsabados/pointypoints/api"
+	"github.com/jonsabados/pointypoints/cors"
+	"github.com/jonsabados/pointypoints/lambdautil"
+	"github.com/jonsabados/pointypoints/logging"
+	"github.com/jonsabados/pointypoints/session"
+)
+
+func NewHandler(prepareLogs logging.Preparer, corsHeaders cors.ResponseHeaderBuilder, loadSession session.Loader) func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		ctx = prepareLogs(ctx)
+
+		// an explicit format wins over whatever the client sent for an accept header, which makes plain links work
+		format := session.ExportFormat(request.QueryStringParameters["format"])
+		if format == "" {
+			format = session.ExportFormatForContentType(api.NegotiateContentType(request.Headers, session.ExportContentTypes()...))
+		}
+		if !format.Valid() {
+			return api.NewValidationFailureResponse(ctx, corsHeaders(ctx, request.Headers), api.ValidationError{
+				FieldErrors: []api.FieldValidationError{
+					{
+						Field: "format",
+						Error: "format must be one of csv, json or md",
+					},
+				},
+			}), nil
+		}
+
+		sessionID := request.PathParameters["session"]
+		sess, err := loadSession(ctx, sessionID)
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("error reading session")
+			return api.NewPermissionDeniedResponse(ctx, corsHeaders(ctx, request.Headers)), nil
+		}
+		if sess == nil {
+			zerolog.Ctx(ctx).Warn().Str("sessionID", sessionID).Msg("session not found")
+			return api.NewPermissionDeniedResponse(ctx, corsHeaders(ctx, request.Headers)), nil
+		}
+
+		if facilitatorKey := api.FacilitatorKey(request.Headers); sess.FacilitatorSessionKey != facilitatorKey {
+			zerolog.Ctx(ctx).Warn().Str("sessionID", sessionID).Msg("attempt to export session with incorrect facilitator key")
+			return api.NewPermissionDeniedResponse(ctx, corsHeaders(ctx, request.Headers)), nil
+		}
+
+		body, err := session.RenderExport(session.NewSessionExport(*sess, time.Now()), format)
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("error rendering export")
+			return api.NewInternalServerError(ctx, corsHeaders(ctx, request.Headers)), nil
+		}
+
+		headers := corsHeaders(ctx, request.Headers)
+		headers["Vary"] = "Origin, Accept"
+		headers["Content-Disposition"] = fmt.Sprintf("attachment; filename=\"session-%s.%s\"", sessionID, format)
+		return api.NewContentResponse(ctx, headers, format.ContentType(), body), nil
+	}
+}
+
+func main() {
+	lambdautil.CoreStartup()
+
+	logPreparer := logging.NewPreparer()
+	sess := lambdautil.DefaultAWSConfig()
+
+	dynamo := lambdautil.NewDynamoClient(sess)
+	loader := session.NewLoader(dynamo, lambdautil.SessionTable)
+
+	allowedDomains := lambdautil.AllowedCORSOrigins()
+
+	lambda.Start(NewHandler(logPreparer, cors.NewResponseHeaderBuilder(allowedDomains), loader))
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/google/uuid"
 	"github.com/jonsabados/goauth/aws"
 	"github.com/rs/zerolog"
 
@@ -17,9 +18,10 @@ import (
 	"github.com/jonsabados/pointypoints/logging"
 	"github.com/jonsabados/pointypoints/profile"
 	"github.com/jonsabados/pointypoints/session"
+	"github.com/jonsabados/pointypoints/webhook"
 )
 
-func NewHandler(prepareLogs logging.Preparer, corsHeaders cors.ResponseHeaderBuilder, loadSession session.Loader, saveJoin session.JoinSaver, notifyParticipants session.ChangeNotifier) func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+func NewHandler(prepareLogs logging.Preparer, corsHeaders cors.ResponseHeaderBuilder, loadSession session.Loader, saveJoin session.JoinSaver, resume session.Resumer, notifyParticipants session.ChangeNotifier, publish webhook.Publisher) func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
 	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
 		ctx = prepareLogs(ctx)
 		var joinRequest session.JoinSessionRequest
@@ -43,11 +45,27 @@ func NewHandler(prepareLogs logging.Preparer, corsHeaders cors.ResponseHeaderBui
 		}
 
 		sessionID := request.PathParameters["session"]
+		userID := request.PathParameters["user"]
+
+		if joinRequest.ResumeToken != "" {
+			resumed, err := resume(ctx, sessionID, userID, joinRequest.ResumeToken, joinRequest.ConnectionID)
+			if err == nil {
+				return notifyResumed(ctx, request, corsHeaders, loadSession, notifyParticipants, sessionID, resumed)
+			}
+			// a token that no longer works, say because the old record already timed out, just means a fresh join
+			if err != session.ErrorInvalidResumeToken {
+				zerolog.Ctx(ctx).Error().Err(err).Msg("error resuming session")
+				return api.NewInternalServerError(ctx, corsHeaders(ctx, request.Headers)), nil
+			}
+			zerolog.Ctx(ctx).Info().Str("sessionID", sessionID).Str("userID", userID).Msg("resume token rejected, joining fresh")
+		}
+
 		user := session.User{
-			UserID:   request.PathParameters["user"],
-			Name:     joinRequest.Name,
-			Handle:   joinRequest.Handle,
-			SocketID: joinRequest.ConnectionID,
+			UserID:      userID,
+			Name:        joinRequest.Name,
+			Handle:      joinRequest.Handle,
+			SocketID:    joinRequest.ConnectionID,
+			ResumeToken: uuid.New().String(),
 		}
 
 		principal, err := aws.ExtractPrincipal(request)
@@ -73,8 +91,36 @@ func NewHandler(prepareLogs logging.Preparer, corsHeaders cors.ResponseHeaderBui
 			return api.NewPermissionDeniedResponse(ctx, corsHeaders(ctx, request.Headers)), nil
 		}
 
-		return api.NewNoContentResponse(ctx, corsHeaders(ctx, request.Headers)), nil
+		err = publish(ctx, webhook.NewParticipantJoinedEvent(*sess, user))
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("error publishing webhook event")
+		}
+
+		return api.NewSuccessResponse(ctx, corsHeaders(ctx, request.Headers), session.JoinSessionResponse{
+			ResumeToken: user.ResumeToken,
+		}), nil
+	}
+}
+
+func notifyResumed(ctx context.Context, request events.APIGatewayProxyRequest, corsHeaders cors.ResponseHeaderBuilder, loadSession session.Loader, notifyParticipants session.ChangeNotifier, sessionID string, resumed session.User) (events.APIGatewayProxyResponse, error) {
+	sess, err := loadSession(ctx, sessionID)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("error reading session")
+		return api.NewInternalServerError(ctx, corsHeaders(ctx, request.Headers)), nil
+	}
+	if sess == nil {
+		zerolog.Ctx(ctx).Warn().Str("sessionID", sessionID).Msg("session vanished after resume")
+		return api.NewPermissionDeniedResponse(ctx, corsHeaders(ctx, request.Headers)), nil
+	}
+	// the participant never really left, so there is no join to tell webhooks about
+	err = notifyParticipants(ctx, *sess)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("error notifying participants of change")
+		return api.NewInternalServerError(ctx, corsHeaders(ctx, request.Headers)), nil
 	}
+	return api.NewSuccessResponse(ctx, corsHeaders(ctx, request.Headers), session.JoinSessionResponse{
+		ResumeToken: resumed.ResumeToken,
+	}), nil
 }
 
 func main() {
@@ -88,9 +134,10 @@ func main() {
 	dynamo := lambdautil.NewDynamoClient(sess)
 	loader := session.NewLoader(dynamo, lambdautil.SessionTable)
 	joiner := session.NewJoinSaver(dynamo, lambdautil.SessionTable, lambdautil.SessionTimeout, statsFactory)
+	resumer := session.NewResumer(dynamo, lambdautil.SessionTable, lambdautil.SessionTimeout)
 	notifier := session.NewChangeNotifier(dynamo, lambdautil.SessionTable, lambdautil.NewProdMessageDispatcher())
 
 	allowedDomains := strings.Split(os.Getenv("ALLOWED_ORIGINS"), ",")
 
-	lambda.Start(NewHandler(logPreparer, cors.NewResponseHeaderBuilder(allowedDomains), loader, joiner, notifier))
+	lambda.Start(NewHandler(logPreparer, cors.NewResponseHeaderBuilder(allowedDomains), loader, joiner, resumer, notifier, lambdautil.NewWebhookPublisher(sess)))
 }
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/jonsabados/goauth/aws"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+
+	"github.com/jonsabados/pointypoints/api"
+	"github.com/jonsabados/pointypoints/cors"
+	"github.com/jonsabados/pointypoints/lambdautil"
+	"github.com/jonsabados/pointypoints/logging"
+	"github.com/jonsabados/pointypoints/session"
+)
+
+const defaultPageSize = 20
+
+func NewHandler(prepareLogs logging.Preparer, corsHeaders cors.ResponseHeaderBuilder, listHistory session.HistoryLister) func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		ctx = prepareLogs(ctx)
+
+		principal, err := aws.ExtractPrincipal(request)
+		if err != nil {
+			zerolog.Ctx(ctx).Warn().Err(err).Msg("error extracting principal")
+			return api.NewInternalServerError(ctx, corsHeaders(ctx, request.Headers)), nil
+		}
+
+		limit := defaultPageSize
+		if l, ok := request.QueryStringParameters["limit"]; ok {
+			limit, err = strconv.Atoi(l)
+			if err != nil || limit < 1 {
+				return api.NewValidationFailureResponse(ctx, corsHeaders(ctx, request.Headers), api.ValidationError{
+					FieldErrors: []api.FieldValidationError{
+						{Field: "limit", Error: "must be a positive number"},
+					},
+				}), nil
+			}
+		}
+
+		page, err := listHistory(ctx, principal.UserID, request.QueryStringParameters["cursor"], limit)
+		if errors.Is(err, session.ErrorInvalidCursor) {
+			return api.NewValidationFailureResponse(ctx, corsHeaders(ctx, request.Headers), api.ValidationError{
+				FieldErrors: []api.FieldValidationError{
+					{Field: "cursor", Error: "is not valid"},
+				},
+			}), nil
+		}
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("error reading session history")
+			return api.NewInternalServerError(ctx, corsHeaders(ctx, request.Headers)), nil
+		}
+		return api.NewSuccessResponse(ctx, corsHeaders(ctx, request.Headers), page), nil
+	}
+}
+
+func main() {
+	lambdautil.CoreStartup()
+
+	logPreparer := logging.NewPreparer()
+	sess := lambdautil.DefaultAWSConfig()
+
+	dynamo := lambdautil.NewDynamoClient(sess)
+	lister := session.NewHistoryLister(dynamo, lambdautil.SessionTable, lambdautil.SessionHistoryIndex, lambdautil.SessionTimeout)
+
+	allowedDomains := lambdautil.AllowedCORSOrigins()
+
+	lambda.Start(NewHandler(logPreparer, cors.NewResponseHeaderBuilder(allowedDomains), lister))
+}
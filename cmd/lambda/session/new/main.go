@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"time"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
@@ -12,12 +13,14 @@ import (
 	"github.com/jonsabados/pointypoints/api"
 	"github.com/jonsabados/pointypoints/cors"
 	"github.com/jonsabados/pointypoints/lambdautil"
+	"github.com/jonsabados/pointypoints/launch"
 	"github.com/jonsabados/pointypoints/logging"
 	"github.com/jonsabados/pointypoints/profile"
 	"github.com/jonsabados/pointypoints/session"
+	"github.com/jonsabados/pointypoints/team"
 )
 
-func NewHandler(prepareLogs logging.Preparer, corsHeaders cors.ResponseHeaderBuilder, startSession session.Starter) func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+func NewHandler(prepareLogs logging.Preparer, corsHeaders cors.ResponseHeaderBuilder, loadTeam team.Loader, startSession launch.Starter) func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
 	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
 		ctx = prepareLogs(ctx)
 		toStart := new(session.StartRequest)
@@ -26,31 +29,32 @@ func NewHandler(prepareLogs logging.Preparer, corsHeaders cors.ResponseHeaderBui
 			zerolog.Ctx(ctx).Warn().Err(err).Msg("error session start reading request body")
 			return api.NewInternalServerError(ctx, corsHeaders(ctx, request.Headers)), nil
 		}
-		errors := make([]string, 0)
-		if toStart.Facilitator.Name == "" {
-			errors = append(errors, "facilitator name is required")
-		}
-		if toStart.Facilitator.UserID == "" {
-			errors = append(errors, "facilitator user id is required")
-		}
-		if toStart.ConnectionID == "" {
-			errors = append(errors, "connection id is required")
-		}
-		if len(errors) > 0 {
+		if errors := session.ValidateStart(toStart, time.Now()); len(errors) > 0 {
 			return api.NewValidationFailureResponse(ctx, corsHeaders(ctx, request.Headers), api.ValidationError{
 				Errors: errors,
 			}), nil
 		}
 
-		toStart.Facilitator.SocketID = toStart.ConnectionID
-
 		principal, err := aws.ExtractPrincipal(request)
 		if err != nil {
 			zerolog.Ctx(ctx).Warn().Err(err).Msg("error extracting principal")
 			return api.NewInternalServerError(ctx, corsHeaders(ctx, request.Headers)), nil
 		}
 
-		sess, err := startSession(ctx, principal, *toStart)
+		var t *team.Team
+		if toStart.TeamID != "" {
+			t, err = loadTeam(ctx, toStart.TeamID)
+			if err != nil {
+				zerolog.Ctx(ctx).Error().Err(err).Msg("error reading team")
+				return api.NewInternalServerError(ctx, corsHeaders(ctx, request.Headers)), nil
+			}
+			if t == nil || !t.CanFacilitate(principal.Email) {
+				zerolog.Ctx(ctx).Warn().Str("teamID", toStart.TeamID).Str("userID", principal.UserID).Msg("attempt to start team session by non facilitator")
+				return api.NewPermissionDeniedResponse(ctx, corsHeaders(ctx, request.Headers)), nil
+			}
+		}
+
+		sess, err := startSession(ctx, principal, t, *toStart)
 		if err != nil {
 			zerolog.Ctx(ctx).Error().Err(err).Msg("error starting session")
 			return api.NewInternalServerError(ctx, corsHeaders(ctx, request.Headers)), nil
@@ -69,9 +73,10 @@ func main() {
 	statsFactory := profile.NewStatsUpdateFactory(lambdautil.ProfileTable)
 
 	dynamo := lambdautil.NewDynamoClient(sess)
-	starter := session.NewStarter(dynamo, lambdautil.SessionTable, lambdautil.SessionTimeout, statsFactory)
+	loader := team.NewLoader(dynamo, lambdautil.TeamTable)
+	starter := launch.NewStarter(session.NewStarter(dynamo, lambdautil.SessionTable, lambdautil.SessionTimeout, statsFactory), lambdautil.NewWebhookPublisher(sess), lambdautil.NewNotifier(dynamo))
 
 	allowedDomains := lambdautil.AllowedCORSOrigins()
 
-	lambda.Start(NewHandler(logPreparer, cors.NewResponseHeaderBuilder(allowedDomains), starter))
+	lambda.Start(NewHandler(logPreparer, cors.NewResponseHeaderBuilder(allowedDomains), loader, starter))
 }
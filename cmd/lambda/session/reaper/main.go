@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/rs/zerolog"
+
+	"github.com/jonsabados/pointypoints/lambdautil"
+	"github.com/jonsabados/pointypoints/logging"
+	"github.com/jonsabados/pointypoints/session"
+	"github.com/jonsabados/pointypoints/webhook"
+)
+
+func NewHandler(prepareLogs logging.Preparer, reap session.StaleConnectionReaper) func(ctx context.Context, event events.CloudWatchEvent) error {
+	return func(ctx context.Context, event events.CloudWatchEvent) error {
+		ctx = prepareLogs(ctx)
+		results, err := reap(ctx)
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("error reaping stale connections")
+		}
+		for _, r := range results {
+			if r.Err != nil {
+				zerolog.Ctx(ctx).Warn().Err(r.Err).Str("sessionID", r.SessionID).Msg("error notifying session of reaped connection")
+			}
+		}
+		// anything missed will be picked up on the next run, no point in having lambda retry
+		return nil
+	}
+}
+
+func main() {
+	lambdautil.CoreStartup()
+
+	logPreparer := logging.NewPreparer()
+	sess := lambdautil.DefaultAWSConfig()
+
+	dynamo := lambdautil.NewDynamoClient(sess)
+	loader := session.NewLoader(dynamo, lambdautil.SessionTable)
+	notifier := session.NewChangeNotifier(dynamo, lambdautil.SessionTable, lambdautil.NewProdMessageDispatcher())
+	publish := lambdautil.NewWebhookPublisher(sess)
+	notifyDeparture := func(ctx context.Context, sess session.CompleteSessionView, departed session.User) error {
+		return publish(ctx, webhook.NewParticipantLeftEvent(sess, departed))
+	}
+	disconnect := session.NewDisconnector(dynamo, lambdautil.SessionTable, lambdautil.SessionSocketIndex, lambdautil.ResumeGracePeriod, loader, notifier, notifyDeparture)
+	reaper := session.NewStaleConnectionReaper(dynamo, lambdautil.SessionTable, lambdautil.SessionSocketIndex, lambdautil.NewProdConnectionProber(), disconnect)
+
+	lambda.Start(NewHandler(logPreparer, reaper))
+}
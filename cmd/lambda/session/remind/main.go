@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/rs/zerolog"
+
+	"github.com/jonsabados/pointypoints/api"
+	"github.com/jonsabados/pointypoints/cors"
+	"github.com/jonsabados/pointypoints/lambdautil"
+	"github.com/jonsabados/pointypoints/logging"
+	"github.com/jonsabados/pointypoints/notify"
+	"github.com/jonsabados/pointypoints/session"
+	"github.com/jonsabados/pointypoints/team"
+)
+
+func NewHandler(prepareLogs logging.Preparer, corsHeaders cors.ResponseHeaderBuilder, loadSession session.Loader, loadTeam team.Loader, sendNotifications notify.Notifier) func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		ctx = prepareLogs(ctx)
+
+		sessionID := request.PathParameters["session"]
+		sess, err := loadSession(ctx, sessionID)
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("error reading session")
+			return api.NewPermissionDeniedResponse(ctx, corsHeaders(ctx, request.Headers)), nil
+		}
+		if sess == nil {
+			zerolog.Ctx(ctx).Warn().Str("sessionID", sessionID).Msg("session not found")
+			return api.NewPermissionDeniedResponse(ctx, corsHeaders(ctx, request.Headers)), nil
+		}
+
+		if facilitatorKey := api.FacilitatorKey(request.Headers); sess.FacilitatorSessionKey != facilitatorKey {
+			zerolog.Ctx(ctx).Warn().Str("sessionID", sessionID).Msg("attempt to send reminders with incorrect facilitator key")
+			return api.NewPermissionDeniedResponse(ctx, corsHeaders(ctx, request.Headers)), nil
+		}
+
+		// the team roster is the only way to know who hasn't voted yet
+		if sess.Deadline == nil || sess.TeamID == "" {
+			return api.NewValidationFailureResponse(ctx, corsHeaders(ctx, request.Headers), api.ValidationError{
+				Errors: []string{"reminders can only be sent for asynchronous team sessions"},
+			}), nil
+		}
+		if session.AsyncVotingClosed(*sess, time.Now()) {
+			return api.NewValidationFailureResponse(ctx, corsHeaders(ctx, request.Headers), api.ValidationError{
+				Errors: []string{"voting has closed"},
+			}), nil
+		}
+
+		t, err := loadTeam(ctx, sess.TeamID)
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("error reading team")
+			return api.NewInternalServerError(ctx, corsHeaders(ctx, request.Headers)), nil
+		}
+		if t == nil {
+			zerolog.Ctx(ctx).Warn().Str("teamID", sess.TeamID).Msg("team not found")
+			return api.NewNoContentResponse(ctx, corsHeaders(ctx, request.Headers)), nil
+		}
+
+		err = sendNotifications(ctx, notify.KindReminder, *sess, notify.AwaitingVotes(*sess, *t))
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("error sending reminders")
+			return api.NewInternalServerError(ctx, corsHeaders(ctx, request.Headers)), nil
+		}
+
+		return api.NewNoContentResponse(ctx, corsHeaders(ctx, request.Headers)), nil
+	}
+}
+
+func main() {
+	lambdautil.CoreStartup()
+
+	logPreparer := logging.NewPreparer()
+	sess := lambdautil.DefaultAWSConfig()
+
+	dynamo := lambdautil.NewDynamoClient(sess)
+	loader := session.NewLoader(dynamo, lambdautil.SessionTable)
+	teamLoader := team.NewLoader(dynamo, lambdautil.TeamTable)
+
+	allowedDomains := lambdautil.AllowedCORSOrigins()
+
+	lambda.Start(NewHandler(logPreparer, cors.NewResponseHeaderBuilder(allowedDomains), loader, teamLoader, lambdautil.NewNotifier(dynamo)))
+}
@@ -56,14 +56,15 @@ func NewHandler(prepareLogs logging.Preparer, corsHeaders cors.ResponseHeaderBui
 			return api.NewInternalServerError(ctx, corsHeaders(ctx, request.Headers)), nil
 		}
 
+		view := session.ToFacilitatorView(*sess)
 		err = dispatch(ctx, l.ConnectionID, api.Message{
 			Type: api.SessionUpdated,
-			Body: *sess,
+			Body: view,
 		})
 		if err != nil {
 			zerolog.Ctx(ctx).Error().Err(err).Msg("error dispatching message")
 		}
-		return api.NewSuccessResponse(ctx, corsHeaders(ctx, request.Headers), sess), nil
+		return api.NewSuccessResponse(ctx, corsHeaders(ctx, request.Headers), view), nil
 	}
 }
 
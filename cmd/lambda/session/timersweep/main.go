@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/rs/zerolog"
+
+	"github.com/jonsabados/pointypoints/lambdautil"
+	"github.com/jonsabados/pointypoints/logging"
+	"github.com/jonsabados/pointypoints/session"
+	"github.com/jonsabados/pointypoints/tracker"
+	"github.com/jonsabados/pointypoints/webhook"
+)
+
+func NewHandler(prepareLogs logging.Preparer, sweep session.ExpiredTimerSweeper) func(ctx context.Context, event events.CloudWatchEvent) error {
+	return func(ctx context.Context, event events.CloudWatchEvent) error {
+		ctx = prepareLogs(ctx)
+		err := sweep(ctx, time.Now())
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("error sweeping expired timers")
+		}
+		// anything missed will be picked up on the next run, no point in having lambda retry
+		return nil
+	}
+}
+
+func main() {
+	lambdautil.CoreStartup()
+
+	logPreparer := logging.NewPreparer()
+	sess := lambdautil.DefaultAWSConfig()
+
+	dynamo := lambdautil.NewDynamoClient(sess)
+	loader := session.NewLoader(dynamo, lambdautil.SessionTable)
+	notifier := session.NewChangeNotifier(dynamo, lambdautil.SessionTable, lambdautil.NewProdMessageDispatcher())
+	writeEstimate := tracker.NewEstimateWriter(lambdautil.NewTrackers())
+	publish := lambdautil.NewWebhookPublisher(sess)
+	updateIntegration := lambdautil.NewIntegrationNotifier()
+	// sweeping reveals votes, so anything agreed upon needs to make its way back to the tracker and out to webhooks
+	notifyRevealed := func(ctx context.Context, sess session.CompleteSessionView) error {
+		err := notifier(ctx, sess)
+		if updateErr := updateIntegration(ctx, sess); updateErr != nil {
+			zerolog.Ctx(ctx).Error().Err(updateErr).Str("sessionID", sess.SessionID).Msg("error updating integration message")
+		}
+		if writeErr := writeEstimate(ctx, sess); writeErr != nil {
+			zerolog.Ctx(ctx).Error().Err(writeErr).Str("sessionID", sess.SessionID).Msg("error writing estimate to tracker")
+		}
+		if publishErr := publish(ctx, webhook.NewVotesRevealedEvent(sess)); publishErr != nil {
+			zerolog.Ctx(ctx).Error().Err(publishErr).Str("sessionID", sess.SessionID).Msg("error publishing webhook event")
+		}
+		return err
+	}
+	sweeper := session.NewExpiredTimerSweeper(dynamo, lambdautil.SessionTable, lambdautil.SessionScheduleIndex, loader, notifyRevealed)
+
+	lambda.Start(NewHandler(logPreparer, sweeper))
+}
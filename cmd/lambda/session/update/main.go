@@ -12,10 +12,14 @@ import (
 	"github.com/jonsabados/pointypoints/cors"
 	"github.com/jonsabados/pointypoints/lambdautil"
 	"github.com/jonsabados/pointypoints/logging"
+	"github.com/jonsabados/pointypoints/notify"
 	"github.com/jonsabados/pointypoints/session"
+	"github.com/jonsabados/pointypoints/team"
+	"github.com/jonsabados/pointypoints/tracker"
+	"github.com/jonsabados/pointypoints/webhook"
 )
 
-func NewHandler(prepareLogs logging.Preparer, corsHeaders cors.ResponseHeaderBuilder, loadSession session.Loader, saveSession session.Saver) func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+func NewHandler(prepareLogs logging.Preparer, corsHeaders cors.ResponseHeaderBuilder, loadSession session.Loader, saveSettings session.SettingsSaver, updateIntegration session.ChangeNotifier, writeEstimate tracker.EstimateWriter, publish webhook.Publisher, loadTeam team.Loader, sendNotifications notify.Notifier) func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
 	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
 		ctx = prepareLogs(ctx)
 		r := new(session.UpdateRequest)
@@ -41,14 +45,66 @@ func NewHandler(prepareLogs logging.Preparer, corsHeaders cors.ResponseHeaderBui
 			return api.NewPermissionDeniedResponse(ctx, corsHeaders(ctx, request.Headers)), nil
 		}
 
-		sess.VotesShown = r.VotesShown
-		sess.FacilitatorPoints = r.FacilitatorPoints
+		if r.CurrentStory != nil && *r.CurrentStory != "" && session.FindStory(sess.Stories, *r.CurrentStory) == nil {
+			return api.NewValidationFailureResponse(ctx, corsHeaders(ctx, request.Headers), api.ValidationError{
+				FieldErrors: []api.FieldValidationError{
+					{
+						Field: "currentStory",
+						Error: "story is not part of this session",
+					},
+				},
+			}), nil
+		}
+
+		// hiding would reopen voting on stories everyone has already seen the results for
+		if sess.Deadline != nil && sess.VotesShown && r.VotesShown != nil && !*r.VotesShown {
+			return api.NewValidationFailureResponse(ctx, corsHeaders(ctx, request.Headers), api.ValidationError{
+				Errors: []string{"votes can't be hidden again in asynchronous sessions"},
+			}), nil
+		}
 
-		err = saveSession(ctx, *sess)
+		wasShown := sess.VotesShown
+		r.Apply(sess)
+
+		err = saveSettings(ctx, *sess, *r)
 		if err != nil {
 			zerolog.Ctx(ctx).Error().Err(err).Msg("error saving session")
 			return api.NewInternalServerError(ctx, corsHeaders(ctx, request.Headers)), nil
 		}
+
+		if wasShown != sess.VotesShown {
+			err = updateIntegration(ctx, *sess)
+			if err != nil {
+				zerolog.Ctx(ctx).Error().Err(err).Msg("error updating integration message")
+			}
+		}
+		if !wasShown && sess.VotesShown {
+			// the session change went through, so a tracker hiccup shouldn't fail the request
+			err = writeEstimate(ctx, *sess)
+			if err != nil {
+				zerolog.Ctx(ctx).Error().Err(err).Msg("error writing estimate to tracker")
+			}
+
+			err = publish(ctx, webhook.NewVotesRevealedEvent(*sess))
+			if err != nil {
+				zerolog.Ctx(ctx).Error().Err(err).Msg("error publishing webhook event")
+			}
+
+			// closing an async session early takes it off the deadline schedule, so the results go out from here instead
+			if sess.Deadline != nil {
+				var t *team.Team
+				if sess.TeamID != "" {
+					t, err = loadTeam(ctx, sess.TeamID)
+					if err != nil {
+						zerolog.Ctx(ctx).Error().Err(err).Msg("error reading team")
+					}
+				}
+				err = sendNotifications(ctx, notify.KindResults, *sess, notify.AsyncParticipants(*sess, t))
+				if err != nil {
+					zerolog.Ctx(ctx).Error().Err(err).Msg("error sending results")
+				}
+			}
+		}
 		return api.NewNoContentResponse(ctx, corsHeaders(ctx, request.Headers)), nil
 	}
 }
@@ -62,9 +118,9 @@ func main() {
 	dynamo := lambdautil.NewDynamoClient(sess)
 	loader := session.NewLoader(dynamo, lambdautil.SessionTable)
 	notifier := session.NewChangeNotifier(dynamo, lambdautil.SessionTable, lambdautil.NewProdMessageDispatcher())
-	saveSess := session.NewSaver(dynamo, lambdautil.SessionTable, notifier, lambdautil.SessionTimeout)
+	saveSettings := session.NewSettingsSaver(dynamo, lambdautil.SessionTable, notifier, lambdautil.SessionTimeout)
 
 	allowedDomains := lambdautil.AllowedCORSOrigins()
 
-	lambda.Start(NewHandler(logPreparer, cors.NewResponseHeaderBuilder(allowedDomains), loader, saveSess))
+	lambda.Start(NewHandler(logPreparer, cors.NewResponseHeaderBuilder(allowedDomains), loader, saveSettings, lambdautil.NewIntegrationNotifier(), tracker.NewEstimateWriter(lambdautil.NewTrackers()), lambdautil.NewWebhookPublisher(sess), team.NewLoader(dynamo, lambdautil.TeamTable), lambdautil.NewNotifier(dynamo)))
 }
@@ -3,8 +3,11 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"os"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
@@ -17,9 +20,11 @@ import (
 	"github.com/jonsabados/pointypoints/logging"
 	"github.com/jonsabados/pointypoints/profile"
 	"github.com/jonsabados/pointypoints/session"
+	"github.com/jonsabados/pointypoints/tracker"
+	"github.com/jonsabados/pointypoints/webhook"
 )
 
-func NewHandler(prepareLogs logging.Preparer, corsHeaders cors.ResponseHeaderBuilder, loadSession session.Loader, recordVote session.VoteRecorder, notifyParticipants session.ChangeNotifier) func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+func NewHandler(prepareLogs logging.Preparer, corsHeaders cors.ResponseHeaderBuilder, loadSession session.Loader, recordVote session.VoteRecorder, autoReveal session.AutoRevealer, notifyParticipants session.ChangeNotifier, updateIntegration session.ChangeNotifier, writeEstimate tracker.EstimateWriter, publish webhook.Publisher) func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
 	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
 		ctx = prepareLogs(ctx)
 		r := new(session.VoteRequest)
@@ -29,6 +34,20 @@ func NewHandler(prepareLogs logging.Preparer, corsHeaders cors.ResponseHeaderBui
 			return api.NewInternalServerError(ctx, corsHeaders(ctx, request.Headers)), nil
 		}
 
+		r.Rationale = strings.TrimSpace(r.Rationale)
+		var fieldErrors []api.FieldValidationError
+		if !r.Confidence.Valid() {
+			fieldErrors = append(fieldErrors, api.FieldValidationError{Field: "confidence", Error: "must be one of low, medium or high"})
+		}
+		if utf8.RuneCountInString(r.Rationale) > session.MaxRationaleLen {
+			fieldErrors = append(fieldErrors, api.FieldValidationError{Field: "rationale", Error: fmt.Sprintf("must be at most %d characters", session.MaxRationaleLen)})
+		}
+		if len(fieldErrors) > 0 {
+			return api.NewValidationFailureResponse(ctx, corsHeaders(ctx, request.Headers), api.ValidationError{
+				FieldErrors: fieldErrors,
+			}), nil
+		}
+
 		// if requests made it to the lambda without a session or connection path param things have gone wrong and a panic is OK
 		sessionID := request.PathParameters["session"]
 		sess, err := loadSession(ctx, sessionID)
@@ -42,6 +61,26 @@ func NewHandler(prepareLogs logging.Preparer, corsHeaders cors.ResponseHeaderBui
 		}
 		zerolog.Ctx(ctx).Debug().Interface("session", sess).Msg("loaded session")
 
+		// async sessions take their votes per story, through the async vote endpoint
+		if sess.Deadline != nil {
+			return api.NewValidationFailureResponse(ctx, corsHeaders(ctx, request.Headers), api.ValidationError{
+				Errors: []string{"live votes are not available for asynchronous sessions"},
+			}), nil
+		}
+		if session.VotingClosed(*sess, time.Now()) {
+			return api.NewValidationFailureResponse(ctx, corsHeaders(ctx, request.Headers), api.ValidationError{
+				Errors: []string{"voting has closed for this round"},
+			}), nil
+		}
+
+		if !session.ValidVote(*sess, r.Vote) {
+			return api.NewValidationFailureResponse(ctx, corsHeaders(ctx, request.Headers), api.ValidationError{
+				FieldErrors: []api.FieldValidationError{
+					{Field: "vote", Error: fmt.Sprintf("must be one of %s", strings.Join(session.SessionDeck(*sess), ", "))},
+				},
+			}), nil
+		}
+
 		userID := request.PathParameters["user"]
 		var user *session.User
 		userType := session.Participant
@@ -60,7 +99,11 @@ func NewHandler(prepareLogs logging.Preparer, corsHeaders cors.ResponseHeaderBui
 			return api.NewPermissionDeniedResponse(ctx, corsHeaders(ctx, request.Headers)), nil
 		}
 
+		wasShown := sess.VotesShown
 		user.CurrentVote = &r.Vote
+		user.Confidence = r.Confidence
+		user.Rationale = r.Rationale
+		user.HasVoted = true
 
 		principal, err := aws.ExtractPrincipal(request)
 		if err != nil {
@@ -73,11 +116,39 @@ func NewHandler(prepareLogs logging.Preparer, corsHeaders cors.ResponseHeaderBui
 			zerolog.Ctx(ctx).Error().Err(err).Msg("error saving session")
 			return api.NewInternalServerError(ctx, corsHeaders(ctx, request.Headers)), nil
 		}
+		sess, err = autoReveal(ctx, sessionID)
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("error checking for auto reveal")
+			return api.NewInternalServerError(ctx, corsHeaders(ctx, request.Headers)), nil
+		}
+		if sess == nil {
+			zerolog.Ctx(ctx).Warn().Str("sessionID", sessionID).Msg("session vanished after vote")
+			return api.NewNoContentResponse(ctx, corsHeaders(ctx, request.Headers)), nil
+		}
+
 		err = notifyParticipants(ctx, *sess)
 		if err != nil {
 			zerolog.Ctx(ctx).Error().Err(err).Msg("error notifying participants")
 			return api.NewInternalServerError(ctx, corsHeaders(ctx, request.Headers)), nil
 		}
+		// messages posted by integrations show who has voted as well as the results, so every vote is worth passing on
+		err = updateIntegration(ctx, *sess)
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("error updating integration message")
+		}
+
+		if !wasShown && sess.VotesShown {
+			// the vote itself went through, so a tracker hiccup shouldn't fail the request
+			err = writeEstimate(ctx, *sess)
+			if err != nil {
+				zerolog.Ctx(ctx).Error().Err(err).Msg("error writing estimate to tracker")
+			}
+
+			err = publish(ctx, webhook.NewVotesRevealedEvent(*sess))
+			if err != nil {
+				zerolog.Ctx(ctx).Error().Err(err).Msg("error publishing webhook event")
+			}
+		}
 
 		return api.NewNoContentResponse(ctx, corsHeaders(ctx, request.Headers)), nil
 	}
@@ -94,9 +165,10 @@ func main() {
 	dynamo := lambdautil.NewDynamoClient(sess)
 	loader := session.NewLoader(dynamo, lambdautil.SessionTable)
 	voteRecorder := session.NewVoteRecorder(dynamo, lambdautil.SessionTable, lambdautil.SessionTimeout, statsFactory)
+	autoRevealer := session.NewAutoRevealer(dynamo, lambdautil.SessionTable, loader)
 	notifier := session.NewChangeNotifier(dynamo, lambdautil.SessionTable, lambdautil.NewProdMessageDispatcher())
 
 	allowedDomains := strings.Split(os.Getenv("ALLOWED_ORIGINS"), ",")
 
-	lambda.Start(NewHandler(logPreparer, cors.NewResponseHeaderBuilder(allowedDomains), loader, voteRecorder, notifier))
+	lambda.Start(NewHandler(logPreparer, cors.NewResponseHeaderBuilder(allowedDomains), loader, voteRecorder, autoRevealer, notifier, lambdautil.NewIntegrationNotifier(), tracker.NewEstimateWriter(lambdautil.NewTrackers()), lambdautil.NewWebhookPublisher(sess)))
 }
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-xray-sdk-go/xray"
+	"github.com/rs/zerolog"
+
+	"github.com/jonsabados/pointypoints/api"
+	"github.com/jonsabados/pointypoints/lambdautil"
+	"github.com/jonsabados/pointypoints/logging"
+	"github.com/jonsabados/pointypoints/profile"
+	"github.com/jonsabados/pointypoints/session"
+	"github.com/jonsabados/pointypoints/slack"
+)
+
+// slack has no way of sending our auth tokens, so instead of the authorizer every request is checked against the app's
+// signing secret
+func NewHandler(prepareLogs logging.Preparer, signingSecret string, handleCommand slack.CommandHandler, handleAction slack.ActionHandler) func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		ctx = prepareLogs(ctx)
+		headers := make(map[string]string)
+
+		body := request.Body
+		if request.IsBase64Encoded {
+			decoded, err := base64.StdEncoding.DecodeString(body)
+			if err != nil {
+				zerolog.Ctx(ctx).Warn().Err(err).Msg("error decoding request body")
+				return api.NewInternalServerError(ctx, headers), nil
+			}
+			body = string(decoded)
+		}
+
+		err := slack.VerifySignature(signingSecret, request.Headers, body, time.Now())
+		if err != nil {
+			zerolog.Ctx(ctx).Warn().Err(err).Msg("rejecting request")
+			return api.NewPermissionDeniedResponse(ctx, headers), nil
+		}
+
+		form, err := url.ParseQuery(body)
+		if err != nil {
+			zerolog.Ctx(ctx).Warn().Err(err).Msg("error reading request body")
+			return api.NewInternalServerError(ctx, headers), nil
+		}
+
+		// button clicks come in with a json payload, slash commands are plain form fields
+		if form.Get("payload") != "" {
+			interaction, err := slack.ParseInteraction(form)
+			if err != nil {
+				zerolog.Ctx(ctx).Warn().Err(err).Msg("error reading interaction")
+				return api.NewInternalServerError(ctx, headers), nil
+			}
+			err = handleAction(ctx, interaction)
+			if err != nil {
+				zerolog.Ctx(ctx).Error().Err(err).Msg("error handling slack action")
+				return api.NewInternalServerError(ctx, headers), nil
+			}
+			return api.NewContentResponse(ctx, headers, "text/plain", ""), nil
+		}
+
+		res, err := handleCommand(ctx, slack.ParseSlashCommand(form))
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("error handling slack command")
+			return api.NewInternalServerError(ctx, headers), nil
+		}
+		out, err := json.Marshal(res)
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("error writing slack response")
+			return api.NewInternalServerError(ctx, headers), nil
+		}
+		return api.NewContentResponse(ctx, headers, "application/json", string(out)), nil
+	}
+}
+
+func main() {
+	lambdautil.CoreStartup()
+
+	logPreparer := logging.NewPreparer()
+	sess := lambdautil.DefaultAWSConfig()
+
+	statsFactory := profile.NewStatsUpdateFactory(lambdautil.ProfileTable)
+
+	dynamo := lambdautil.NewDynamoClient(sess)
+	loader := session.NewLoader(dynamo, lambdautil.SessionTable)
+	notifier := session.NewChangeNotifier(dynamo, lambdautil.SessionTable, lambdautil.NewProdMessageDispatcher())
+	starter := session.NewStarter(dynamo, lambdautil.SessionTable, lambdautil.SessionTimeout, statsFactory)
+	saver := session.NewSaver(dynamo, lambdautil.SessionTable, notifier, lambdautil.SessionTimeout)
+	votesShownSaver := session.NewVotesShownSaver(dynamo, lambdautil.SessionTable, notifier)
+	joinSaver := session.NewJoinSaver(dynamo, lambdautil.SessionTable, lambdautil.SessionTimeout, statsFactory)
+	voteRecorder := session.NewVoteRecorder(dynamo, lambdautil.SessionTable, lambdautil.SessionTimeout, statsFactory)
+	autoRevealer := session.NewAutoRevealer(dynamo, lambdautil.SessionTable, loader)
+	publisher := lambdautil.NewWebhookPublisher(sess)
+
+	client := slack.NewClient(os.Getenv("SLACK_API_URL"), os.Getenv("SLACK_BOT_TOKEN"), xray.Client(&http.Client{
+		Timeout: 5 * time.Second,
+	}))
+
+	lambda.Start(NewHandler(logPreparer, os.Getenv("SLACK_SIGNING_SECRET"),
+		slack.NewCommandHandler(client, starter, saver, publisher),
+		slack.NewActionHandler(client, loader, joinSaver, voteRecorder, autoRevealer, votesShownSaver, notifier, publisher)))
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/jonsabados/goauth/aws"
+	"github.com/rs/zerolog"
+
+	"github.com/jonsabados/pointypoints/api"
+	"github.com/jonsabados/pointypoints/cors"
+	"github.com/jonsabados/pointypoints/lambdautil"
+	"github.com/jonsabados/pointypoints/logging"
+	"github.com/jonsabados/pointypoints/team"
+)
+
+func NewHandler(prepareLogs logging.Preparer, corsHeaders cors.ResponseHeaderBuilder, loadTeam team.Loader, saveTeam team.Saver) func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		ctx = prepareLogs(ctx)
+		r := new(team.SaveRequest)
+		err := json.Unmarshal([]byte(request.Body), r)
+		if err != nil {
+			zerolog.Ctx(ctx).Warn().Err(err).Msg("error reading team request body")
+			return api.NewInternalServerError(ctx, corsHeaders(ctx, request.Headers)), nil
+		}
+		if fieldErrors := team.Validate(*r); len(fieldErrors) > 0 {
+			return api.NewValidationFailureResponse(ctx, corsHeaders(ctx, request.Headers), api.ValidationError{
+				FieldErrors: fieldErrors,
+			}), nil
+		}
+
+		principal, err := aws.ExtractPrincipal(request)
+		if err != nil {
+			zerolog.Ctx(ctx).Warn().Err(err).Msg("error extracting principal")
+			return api.NewInternalServerError(ctx, corsHeaders(ctx, request.Headers)), nil
+		}
+
+		teamID := request.PathParameters["team"]
+		existing, err := loadTeam(ctx, teamID)
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("error reading team")
+			return api.NewInternalServerError(ctx, corsHeaders(ctx, request.Headers)), nil
+		}
+		if existing == nil || !existing.CanFacilitate(principal.Email) {
+			zerolog.Ctx(ctx).Warn().Str("teamID", teamID).Str("userID", principal.UserID).Msg("attempt to update team by non facilitator")
+			return api.NewPermissionDeniedResponse(ctx, corsHeaders(ctx, request.Headers)), nil
+		}
+
+		updated := team.Apply(*existing, *r)
+		// a team nobody can manage is stuck forever, so don't let the last facilitator step down
+		if !hasFacilitator(updated) {
+			return api.NewValidationFailureResponse(ctx, corsHeaders(ctx, request.Headers), api.ValidationError{
+				FieldErrors: []api.FieldValidationError{
+					{Field: "members", Error: "at least one facilitator is required"},
+				},
+			}), nil
+		}
+
+		err = saveTeam(ctx, updated, team.RemovedMembers(*existing, updated))
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("error saving team")
+			return api.NewInternalServerError(ctx, corsHeaders(ctx, request.Headers)), nil
+		}
+		return api.NewSuccessResponse(ctx, corsHeaders(ctx, request.Headers), updated), nil
+	}
+}
+
+func hasFacilitator(t team.Team) bool {
+	for _, m := range t.Members {
+		if m.Role == team.RoleFacilitator {
+			return true
+		}
+	}
+	return false
+}
+
+func main() {
+	lambdautil.CoreStartup()
+
+	logPreparer := logging.NewPreparer()
+	sess := lambdautil.DefaultAWSConfig()
+
+	dynamo := lambdautil.NewDynamoClient(sess)
+	loader := team.NewLoader(dynamo, lambdautil.TeamTable)
+	saver := team.NewSaver(dynamo, lambdautil.TeamTable)
+
+	allowedDomains := lambdautil.AllowedCORSOrigins()
+
+	lambda.Start(NewHandler(logPreparer, cors.NewResponseHeaderBuilder(allowedDomains), loader, saver))
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-xray-sdk-go/xray"
+	"github.com/rs/zerolog"
+
+	"github.com/jonsabados/pointypoints/lambdautil"
+	"github.com/jonsabados/pointypoints/logging"
+	"github.com/jonsabados/pointypoints/webhook"
+)
+
+func NewHandler(prepareLogs logging.Preparer, dispatch webhook.Publisher) func(ctx context.Context, event webhook.Event) error {
+	return func(ctx context.Context, event webhook.Event) error {
+		ctx = prepareLogs(ctx)
+		err := dispatch(ctx, event)
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Str("eventID", event.ID).Msg("error dispatching webhook event")
+		}
+		// retries already happened per webhook, having lambda retry the whole event would re-deliver to everyone
+		return nil
+	}
+}
+
+func main() {
+	lambdautil.CoreStartup()
+
+	logPreparer := logging.NewPreparer()
+	sess := lambdautil.DefaultAWSConfig()
+
+	dynamo := lambdautil.NewDynamoClient(sess)
+	client := xray.Client(&http.Client{
+		Timeout: 10 * time.Second,
+	})
+	dispatcher := webhook.NewDispatcher(
+		webhook.NewLister(dynamo, lambdautil.WebhookTable),
+		webhook.NewDeliverer(client, webhook.DefaultBackoff),
+		webhook.NewDeliveryLogger(dynamo, lambdautil.WebhookTable),
+	)
+
+	lambda.Start(NewHandler(logPreparer, dispatcher))
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/rs/zerolog"
+
+	"github.com/jonsabados/pointypoints/api"
+	"github.com/jonsabados/pointypoints/cors"
+	"github.com/jonsabados/pointypoints/lambdautil"
+	"github.com/jonsabados/pointypoints/logging"
+	"github.com/jonsabados/pointypoints/session"
+	"github.com/jonsabados/pointypoints/team"
+	"github.com/jonsabados/pointypoints/webhook"
+)
+
+const recentDeliveryCount = 50
+
+type listResponse struct {
+	Webhooks   []webhook.Registration `json:"webhooks"`
+	Deliveries []webhook.Delivery     `json:"deliveries"`
+}
+
+func NewHandler(prepareLogs logging.Preparer, corsHeaders cors.ResponseHeaderBuilder, resolveOwner lambdautil.WebhookOwnerResolver, listRegistrations webhook.Lister, listDeliveries webhook.DeliveryLister) func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		ctx = prepareLogs(ctx)
+
+		owner, _, err := resolveOwner(ctx, request)
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("error working out webhook owner")
+			return api.NewPermissionDeniedResponse(ctx, corsHeaders(ctx, request.Headers)), nil
+		}
+		if owner == "" {
+			return api.NewPermissionDeniedResponse(ctx, corsHeaders(ctx, request.Headers)), nil
+		}
+
+		registrations, err := listRegistrations(ctx, owner)
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("error reading webhooks")
+			return api.NewInternalServerError(ctx, corsHeaders(ctx, request.Headers)), nil
+		}
+		// secrets are only handed out at registration
+		for i := range registrations {
+			registrations[i].Secret = ""
+		}
+
+		deliveries, err := listDeliveries(ctx, owner, recentDeliveryCount)
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("error reading webhook deliveries")
+			return api.NewInternalServerError(ctx, corsHeaders(ctx, request.Headers)), nil
+		}
+
+		return api.NewSuccessResponse(ctx, corsHeaders(ctx, request.Headers), listResponse{
+			Webhooks:   registrations,
+			Deliveries: deliveries,
+		}), nil
+	}
+}
+
+func main() {
+	lambdautil.CoreStartup()
+
+	logPreparer := logging.NewPreparer()
+	sess := lambdautil.DefaultAWSConfig()
+
+	dynamo := lambdautil.NewDynamoClient(sess)
+	resolver := lambdautil.NewWebhookOwnerResolver(session.NewLoader(dynamo, lambdautil.SessionTable), team.NewLoader(dynamo, lambdautil.TeamTable))
+	lister := webhook.NewLister(dynamo, lambdautil.WebhookTable)
+	deliveryLister := webhook.NewDeliveryLister(dynamo, lambdautil.WebhookTable)
+
+	allowedDomains := lambdautil.AllowedCORSOrigins()
+
+	lambda.Start(NewHandler(logPreparer, cors.NewResponseHeaderBuilder(allowedDomains), resolver, lister, deliveryLister))
+}
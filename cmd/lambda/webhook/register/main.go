@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/rs/zerolog"
+
+	"github.com/jonsabados/pointypoints/api"
+	"github.com/jonsabados/pointypoints/cors"
+	"github.com/jonsabados/pointypoints/lambdautil"
+	"github.com/jonsabados/pointypoints/logging"
+	"github.com/jonsabados/pointypoints/session"
+	"github.com/jonsabados/pointypoints/team"
+	"github.com/jonsabados/pointypoints/webhook"
+)
+
+func NewHandler(prepareLogs logging.Preparer, corsHeaders cors.ResponseHeaderBuilder, resolveOwner lambdautil.WebhookOwnerResolver, register webhook.Registrar) func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		ctx = prepareLogs(ctx)
+		r := new(webhook.RegisterRequest)
+		err := json.Unmarshal([]byte(request.Body), r)
+		if err != nil {
+			zerolog.Ctx(ctx).Warn().Err(err).Msg("error reading webhook registration body")
+			return api.NewInternalServerError(ctx, corsHeaders(ctx, request.Headers)), nil
+		}
+
+		fieldErrors := make([]api.FieldValidationError, 0)
+		if u, err := url.Parse(r.URL); err != nil || u.Scheme != "https" || u.Host == "" {
+			fieldErrors = append(fieldErrors, api.FieldValidationError{
+				Field: "url",
+				Error: "must be a valid https url",
+			})
+		}
+		for _, e := range r.Events {
+			if !e.Valid() {
+				fieldErrors = append(fieldErrors, api.FieldValidationError{
+					Field: "events",
+					Error: "unknown event " + string(e),
+				})
+			}
+		}
+		if len(fieldErrors) > 0 {
+			return api.NewValidationFailureResponse(ctx, corsHeaders(ctx, request.Headers), api.ValidationError{
+				FieldErrors: fieldErrors,
+			}), nil
+		}
+
+		owner, expiration, err := resolveOwner(ctx, request)
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("error working out webhook owner")
+			return api.NewPermissionDeniedResponse(ctx, corsHeaders(ctx, request.Headers)), nil
+		}
+		if owner == "" {
+			return api.NewPermissionDeniedResponse(ctx, corsHeaders(ctx, request.Headers)), nil
+		}
+
+		registration, err := webhook.NewRegistration(owner, *r, time.Now())
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("error creating webhook registration")
+			return api.NewInternalServerError(ctx, corsHeaders(ctx, request.Headers)), nil
+		}
+		err = register(ctx, registration, expiration)
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("error saving webhook registration")
+			return api.NewInternalServerError(ctx, corsHeaders(ctx, request.Headers)), nil
+		}
+		return api.NewSuccessResponse(ctx, corsHeaders(ctx, request.Headers), registration), nil
+	}
+}
+
+func main() {
+	lambdautil.CoreStartup()
+
+	logPreparer := logging.NewPreparer()
+	sess := lambdautil.DefaultAWSConfig()
+
+	dynamo := lambdautil.NewDynamoClient(sess)
+	resolver := lambdautil.NewWebhookOwnerResolver(session.NewLoader(dynamo, lambdautil.SessionTable), team.NewLoader(dynamo, lambdautil.TeamTable))
+	registrar := webhook.NewRegistrar(dynamo, lambdautil.WebhookTable)
+
+	allowedDomains := lambdautil.AllowedCORSOrigins()
+
+	lambda.Start(NewHandler(logPreparer, cors.NewResponseHeaderBuilder(allowedDomains), resolver, registrar))
+}
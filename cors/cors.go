@@ -22,7 +22,7 @@ func NewResponseHeaderBuilder(allowedDomains []string) ResponseHeaderBuilder {
 		if origin != "" && isOriginAllowed(origin, allowedDomains) {
 			headers["Access-Control-Allow-Origin"] = origin
 			headers["Access-Control-Allow-Headers"] = "Authorization,Content-Type,X-Facilitator-Key"
-			headers["Access-Control-Expose-Headers"] = "Location"
+			headers["Access-Control-Expose-Headers"] = "Location,Content-Disposition"
 			headers["Access-Control-Allow-Methods"] = "OPTIONS,HEAD,GET,POST,PUT,DELETE"
 		} else {
 			zerolog.Ctx(ctx).Warn().Interface("allowedDomains", allowedDomains).Str("origin", origin).Msg("disallowed origin")
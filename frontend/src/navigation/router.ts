@@ -3,6 +3,7 @@ import Home from '@/home/Home.vue'
 import NewSession from '@/pointing/NewSession.vue'
 import Session from '@/pointing/Session.vue'
 import Facilitate from '@/pointing/FacilitateSession.vue'
+import AsyncSession from '@/pointing/AsyncSession.vue'
 import Profile from '@/profile/Profile.vue'
 import Privacy from '@/Privacy.vue'
 
@@ -10,6 +11,7 @@ export const HOME_ROUTE_NAME = 'home'
 export const NEW_SESSION_ROUTE_NAME = 'newSession'
 export const SESSION_ROUTE_NAME = 'session'
 export const FACILITATE_ROUTE_NAME = 'facilitate'
+export const ASYNC_SESSION_ROUTE_NAME = 'asyncSession'
 export const PROFILE_ROUTE_NAME = 'profile'
 export const PRIVACY_ROUTE_NAME = 'privacy'
 
@@ -24,6 +26,11 @@ const routes = [
     name: NEW_SESSION_ROUTE_NAME,
     component: NewSession
   },
+  {
+    path: '/session/:sessionId/async',
+    name: ASYNC_SESSION_ROUTE_NAME,
+    component: AsyncSession
+  },
   {
     path: '/session/:sessionId',
     name: SESSION_ROUTE_NAME,
@@ -4,13 +4,96 @@ import { User } from '@/user/user'
 
 const SESSION_UPDATED = 'SESSION_UPDATED'
 const PING = 'PING'
+const CHAT = 'CHAT'
+const REACTION = 'REACTION'
+const CHAT_CLEARED = 'CHAT_CLEARED'
+const RATE_LIMITED = 'RATE_LIMITED'
+const ANNOUNCEMENT = 'ANNOUNCEMENT'
+
+const CHAT_HISTORY_SIZE = 50
+const REACTION_DISPLAY_MS = 5000
+
+export interface ChatEntry {
+  id: string
+  userId: string
+  name: string
+  text: string
+  sentAt: string
+}
+
+export interface Announcement {
+  sessionId: string
+  text: string
+  sentAt: string
+}
+
+export interface Reaction {
+  userId: string
+  name: string
+  reaction: string
+}
+
+export type SessionType = 'estimate' | 'fist-of-five' | 'thumbs'
+
+export interface VoteStats {
+  votes: number
+  average?: number
+  weightedAverage?: number
+  confidence?: { [level: string]: number }
+  counts?: { [vote: string]: number }
+  consensus?: boolean
+}
+
+export interface Story {
+  key: string
+  title: string
+}
+
+export interface AsyncVote {
+  storyKey: string
+  userId: string
+  name?: string
+  handle?: string
+  vote?: string
+  confidence?: string
+  rationale?: string
+  votedAt: string
+}
+
+export interface AsyncStory extends Story {
+  voteCount: number
+  myVote?: AsyncVote
+  votes?: Array<AsyncVote>
+  stats?: VoteStats
+}
+
+export interface AsyncSession {
+  sessionId: string
+  type: SessionType
+  deadline: string
+  votesShown: boolean
+  facilitator: User
+  deck: Array<string>
+  stories: Array<AsyncStory>
+}
 
 export interface PointingSession {
   facilitatorPoints: boolean
   sessionId: string
+  type: SessionType
   facilitator: User
   participants: Array<User>
   votesShown: boolean
+  autoReveal: boolean
+  anonymousReveal: boolean
+  anonymizeFacilitatorView: boolean
+  skipIdle: boolean
+  stats?: VoteStats
+  chat?: Array<ChatEntry>
+  banner?: Announcement
+  teamId?: string
+  deadline?: string
+  asyncVotes?: Array<AsyncVote>
 }
 
 export interface PointingSessionState {
@@ -18,6 +101,8 @@ export interface PointingSessionState {
   connectionId: string | null
   sessionId: string | null
   currentSession: PointingSession | null
+  reactions: Array<Reaction>
+  announcement: Announcement | null
 }
 
 function sendMessage(socket: WebSocket, message: any) {
@@ -47,6 +132,13 @@ export class PointingSessionStore extends VuexModule<PointingSessionState> {
   static MUTATION_SET_SESSION_ID = 'setSessionId'
   static MUTATION_END_SESSION = 'clearSession'
   static MUTATION_SET_CONNECTION_ID = 'setConnectionId'
+  static MUTATION_ADD_CHAT = 'addChat'
+  static MUTATION_CLEAR_CHAT = 'clearChat'
+  static MUTATION_ADD_REACTION = 'addReaction'
+  static MUTATION_REMOVE_REACTION = 'removeReaction'
+  static MUTATION_SET_ANNOUNCEMENT = 'setAnnouncement'
+  static ACTION_SEND_CHAT = 'sendChat'
+  static ACTION_SEND_REACTION = 'sendReaction'
 
   facilitating: boolean = false
 
@@ -56,6 +148,10 @@ export class PointingSessionStore extends VuexModule<PointingSessionState> {
 
   currentSession: PointingSession | null = null
 
+  reactions: Array<Reaction> = []
+
+  announcement: Announcement | null = null
+
   socket: WebSocket = new WebSocket(`${process.env['VUE_APP_POINTING_SOCKET_URL']}/`)
 
   @Mutation
@@ -84,12 +180,73 @@ export class PointingSessionStore extends VuexModule<PointingSessionState> {
     this.sessionId = sessionId
   }
 
+  @Mutation
+  addChat(entry: ChatEntry) {
+    if (!this.currentSession) {
+      return
+    }
+    const chat = (this.currentSession.chat || []).concat(entry)
+    this.currentSession.chat = chat.slice(-CHAT_HISTORY_SIZE)
+  }
+
+  @Mutation
+  clearChat() {
+    if (this.currentSession) {
+      this.currentSession.chat = []
+    }
+  }
+
+  @Mutation
+  addReaction(reaction: Reaction) {
+    this.reactions.push(reaction)
+  }
+
+  @Mutation
+  removeReaction(reaction: Reaction) {
+    this.reactions = this.reactions.filter((r) => {
+      return r !== reaction
+    })
+  }
+
+  @Mutation
+  setAnnouncement(announcement: Announcement | null) {
+    this.announcement = announcement
+  }
+
+  @Action
+  sendChat(text: string) {
+    sendMessage(this.socket, {
+      action: 'chat',
+      sessionId: this.sessionId,
+      text
+    })
+  }
+
+  @Action
+  sendReaction(reaction: string) {
+    sendMessage(this.socket, {
+      action: 'reaction',
+      sessionId: this.sessionId,
+      reaction
+    })
+  }
+
   @Action
   initialize() {
+    // pings keep the connection open regardless, active tells the backend someone is actually using the page
+    let active = true
+    const markActive = () => {
+      active = true
+    }
+    ['mousemove', 'keydown', 'touchstart', 'focus'].forEach((e) => {
+      window.addEventListener(e, markActive)
+    })
     const ping = () => {
       sendMessage(this.socket, {
-        action: 'ping'
+        action: 'ping',
+        active
       })
+      active = false
     }
     setInterval(ping, 30000)
     ping()
@@ -111,6 +268,35 @@ export class PointingSessionStore extends VuexModule<PointingSessionState> {
           }
           break
         }
+        case CHAT: {
+          this.context.commit(PointingSessionStore.MUTATION_ADD_CHAT, eventData.body as ChatEntry)
+          break
+        }
+        case CHAT_CLEARED: {
+          if (eventData.body.sessionId === this.sessionId) {
+            this.context.commit(PointingSessionStore.MUTATION_CLEAR_CHAT)
+          }
+          break
+        }
+        case REACTION: {
+          const reaction = eventData.body as Reaction
+          this.context.commit(PointingSessionStore.MUTATION_ADD_REACTION, reaction)
+          setTimeout(() => {
+            this.context.commit(PointingSessionStore.MUTATION_REMOVE_REACTION, reaction)
+          }, REACTION_DISPLAY_MS)
+          break
+        }
+        case ANNOUNCEMENT: {
+          const announcement = eventData.body as Announcement
+          if (announcement.sessionId === this.sessionId) {
+            this.context.commit(PointingSessionStore.MUTATION_SET_ANNOUNCEMENT, announcement)
+          }
+          break
+        }
+        case RATE_LIMITED: {
+          this.context.dispatch(AppStore.ACTION_REGISTER_REMOTE_ERROR, new Error('Slow down, you are sending messages too quickly'))
+          break
+        }
         case PING: {
           this.context.commit(PointingSessionStore.MUTATION_SET_CONNECTION_ID, eventData.body.connectionId)
           break
@@ -1,18 +1,32 @@
 import { apiBase } from '@/api/api'
 import axios from 'axios'
-import { User } from '@/user/user'
-import { PointingSession } from '@/pointing/PointingSessionStore'
+import { Confidence, User } from '@/user/user'
+import { AsyncSession, PointingSession, SessionType } from '@/pointing/PointingSessionStore'
 
 export interface StartSessionRequest {
   connectionId: string,
   facilitator: User
   facilitatorPoints: boolean
+  type?: SessionType
+  async?: AsyncRequest
+}
+
+export interface AsyncRequest {
+  deadline: string
+  stories: Array<{ key?: string, title: string }>
+}
+
+export interface NotificationPreferences {
+  invites: boolean
+  reminders: boolean
+  results: boolean
 }
 
 export interface Profile {
   email: string
   name: string
   handle: string
+  notifications: NotificationPreferences
 }
 
 export async function getProfile(authHeader: string):Promise<Profile> {
@@ -40,9 +54,9 @@ export async function updateProfile(authHeader: string, profile: Profile) {
   }
 }
 
-export async function vote(authHeader: string, session: string, userID: string, vote: string) {
+export async function vote(authHeader: string, session: string, userID: string, vote: string, confidence?: Confidence, rationale?: string) {
   const url = `${apiBase()}/session/${session}/user/${userID}/vote`
-  const body = { vote }
+  const body = { vote, confidence, rationale }
   const res = await axios.put(url, body, {
     headers: {
       Authorization: authHeader
@@ -53,6 +67,46 @@ export async function vote(authHeader: string, session: string, userID: string,
   }
 }
 
+export async function asyncVote(authHeader: string, session: string, story: string, user: User, vote: string, confidence?: Confidence, rationale?: string) {
+  const url = `${apiBase()}/session/${session}/stories/${encodeURIComponent(story)}/vote`
+  const body = { userId: user.userId, name: user.name, handle: user.handle, vote, confidence, rationale }
+  const res = await axios.put(url, body, {
+    headers: {
+      Authorization: authHeader
+    }
+  })
+  if (res.status !== 204) {
+    throw new Error(`unexpected response code ${res.status}`)
+  }
+}
+
+export async function getAsyncSession(authHeader: string, session: string, userID: string): Promise<AsyncSession> {
+  const url = `${apiBase()}/session/${session}/async`
+  const res = await axios.get(url, {
+    params: { userId: userID },
+    headers: {
+      Authorization: authHeader
+    }
+  })
+  if (res.status !== 200) {
+    throw new Error(`unexpected response code ${res.status}`)
+  }
+  return res.data.result
+}
+
+export async function sendReminders(authHeader: string, session: string, facilitatorKey: string) {
+  const url = `${apiBase()}/session/${session}/remind`
+  const res = await axios.post(url, {}, {
+    headers: {
+      Authorization: authHeader,
+      'X-Facilitator-Key': facilitatorKey
+    }
+  })
+  if (res.status !== 204) {
+    throw new Error(`unexpected response code ${res.status}`)
+  }
+}
+
 export async function facilitateSession(authHeader: string, session: string, connectionId: string, facilitatorKey: string): Promise<PointingSession> {
   const url = `${apiBase()}/session/${session}/facilitator`
   const res = await axios.put(url, { connectionId }, {
@@ -67,14 +121,18 @@ export async function facilitateSession(authHeader: string, session: string, con
   return res.data.result
 }
 
-export async function joinSession(authHeader: string, session: string, userID: string, user: User) {
+export interface JoinSessionResponse {
+  resumeToken: string
+}
+
+export async function joinSession(authHeader: string, session: string, userID: string, user: User, resumeToken?: string): Promise<JoinSessionResponse> {
   const url = `${apiBase()}/session/${session}/user/${userID}`
-  const res = await axios.put(url, user, {
+  const res = await axios.put(url, { ...user, resumeToken }, {
     headers: {
       Authorization: authHeader
     }
   })
-  if (res.status !== 204) {
+  if (res.status !== 200) {
     throw new Error(`unexpected response code ${res.status}`)
   }
   return res.data.result
@@ -93,10 +151,20 @@ export async function watchSession(authHeader: string, session: string, connecti
   return res.data.result
 }
 
-export async function updateSession(authHeader: string, session: string, facilitatorKey: string, votesShown: boolean, facilitatorPoints: boolean) {
+export interface SessionUpdate {
+  votesShown?: boolean
+  facilitatorPoints?: boolean
+  autoReveal?: boolean
+  anonymousReveal?: boolean
+  anonymizeFacilitatorView?: boolean
+  skipIdle?: boolean
+  currentStory?: string
+}
+
+// only the settings present in the update are changed
+export async function updateSession(authHeader: string, session: string, facilitatorKey: string, update: SessionUpdate) {
   const url = `${apiBase()}/session/${session}`
-  const request = { votesShown, facilitatorPoints }
-  const res = await axios.put(url, request, {
+  const res = await axios.put(url, update, {
     headers: {
       Authorization: authHeader,
       'X-Facilitator-Key': facilitatorKey
@@ -120,6 +188,45 @@ export async function clearVotes(authHeader: string, session: string, facilitato
   }
 }
 
+export async function clearChat(authHeader: string, session: string, facilitatorKey: string) {
+  const url = `${apiBase()}/session/${session}/chat`
+  const res = await axios.delete(url, {
+    headers: {
+      Authorization: authHeader,
+      'X-Facilitator-Key': facilitatorKey
+    }
+  })
+  if (res.status !== 204) {
+    throw new Error(`unexpected response code ${res.status}`)
+  }
+}
+
+export async function announce(authHeader: string, session: string, facilitatorKey: string, text: string, banner: boolean) {
+  const url = `${apiBase()}/session/${session}/announcement`
+  const res = await axios.post(url, { text, banner }, {
+    headers: {
+      Authorization: authHeader,
+      'X-Facilitator-Key': facilitatorKey
+    }
+  })
+  if (res.status !== 204) {
+    throw new Error(`unexpected response code ${res.status}`)
+  }
+}
+
+export async function clearBanner(authHeader: string, session: string, facilitatorKey: string) {
+  const url = `${apiBase()}/session/${session}/announcement`
+  const res = await axios.delete(url, {
+    headers: {
+      Authorization: authHeader,
+      'X-Facilitator-Key': facilitatorKey
+    }
+  })
+  if (res.status !== 204) {
+    throw new Error(`unexpected response code ${res.status}`)
+  }
+}
+
 export async function createSession(authHeader: string, request: StartSessionRequest): Promise<PointingSession> {
   const url = `${apiBase()}/session`
   const res = await axios.post(url, request, {
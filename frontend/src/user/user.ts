@@ -1,11 +1,16 @@
 import { v4 as uuidv4 } from 'uuid'
 
+export type Confidence = 'low' | 'medium' | 'high'
+
 export interface User {
   userId?: string
   connectionId: string
   name: string
   handle?: string
   currentVote?: string
+  confidence?: Confidence
+  rationale?: string
+  status?: 'active' | 'idle' | 'away'
 }
 
 export function newUser(name: string, handle?: string):User {
@@ -0,0 +1,45 @@
+resource "aws_api_gateway_resource" "session_announcement_resource" {
+  rest_api_id = aws_api_gateway_rest_api.rest_pointing.id
+  parent_id   = aws_api_gateway_resource.session_var.id
+  path_part   = "announcement"
+}
+
+module "announce_lambda" {
+  source = "./rest-endpoint"
+
+  aws_region    = var.aws_region
+  api_id        = aws_api_gateway_rest_api.rest_pointing.id
+  authorizer_id = aws_api_gateway_authorizer.authorizer.id
+
+  name       = "announce"
+  policy     = data.aws_iam_policy_document.session_modifying_lambda_policy.json
+  lambda_env = local.session_modifying_lambda_env
+
+  http_method = "POST"
+  resource_id = aws_api_gateway_resource.session_announcement_resource.id
+  full_path   = aws_api_gateway_resource.session_announcement_resource.path
+
+  request_parameters = {
+    "method.request.path.session" = true
+  }
+}
+
+module "clearBanner_lambda" {
+  source = "./rest-endpoint"
+
+  aws_region    = var.aws_region
+  api_id        = aws_api_gateway_rest_api.rest_pointing.id
+  authorizer_id = aws_api_gateway_authorizer.authorizer.id
+
+  name       = "clearBanner"
+  policy     = data.aws_iam_policy_document.session_modifying_lambda_policy.json
+  lambda_env = local.session_modifying_lambda_env
+
+  http_method = "DELETE"
+  resource_id = aws_api_gateway_resource.session_announcement_resource.id
+  full_path   = aws_api_gateway_resource.session_announcement_resource.path
+
+  request_parameters = {
+    "method.request.path.session" = true
+  }
+}
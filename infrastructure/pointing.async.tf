@@ -0,0 +1,70 @@
+resource "aws_api_gateway_resource" "session_story_var" {
+  rest_api_id = aws_api_gateway_rest_api.rest_pointing.id
+  parent_id   = aws_api_gateway_resource.session_stories_resource.id
+  path_part   = "{story}"
+}
+
+resource "aws_api_gateway_resource" "session_story_vote_resource" {
+  rest_api_id = aws_api_gateway_rest_api.rest_pointing.id
+  parent_id   = aws_api_gateway_resource.session_story_var.id
+  path_part   = "vote"
+}
+
+module "asyncVote_lambda" {
+  source = "./rest-endpoint"
+
+  aws_region    = var.aws_region
+  api_id        = aws_api_gateway_rest_api.rest_pointing.id
+  authorizer_id = aws_api_gateway_authorizer.authorizer.id
+
+  name       = "asyncVote"
+  policy     = data.aws_iam_policy_document.session_modifying_lambda_policy.json
+  lambda_env = local.session_modifying_lambda_env
+
+  http_method = "PUT"
+  resource_id = aws_api_gateway_resource.session_story_vote_resource.id
+  full_path   = aws_api_gateway_resource.session_story_vote_resource.path
+
+  request_parameters = {
+    "method.request.path.session" = true
+    "method.request.path.story"   = true
+  }
+}
+
+resource "aws_api_gateway_resource" "session_async_resource" {
+  rest_api_id = aws_api_gateway_rest_api.rest_pointing.id
+  parent_id   = aws_api_gateway_resource.session_var.id
+  path_part   = "async"
+}
+
+module "asyncSession_lambda" {
+  source = "./rest-endpoint"
+
+  aws_region    = var.aws_region
+  api_id        = aws_api_gateway_rest_api.rest_pointing.id
+  authorizer_id = aws_api_gateway_authorizer.authorizer.id
+
+  name       = "asyncSession"
+  policy     = data.aws_iam_policy_document.session_modifying_lambda_policy.json
+  lambda_env = local.session_modifying_lambda_env
+
+  http_method = "GET"
+  resource_id = aws_api_gateway_resource.session_async_resource.id
+  full_path   = aws_api_gateway_resource.session_async_resource.path
+
+  request_parameters = {
+    "method.request.path.session"       = true
+    "method.request.querystring.userId" = false
+  }
+}
+
+module "deadlineSweep_lambda" {
+  source = "./scheduled-lambda"
+
+  aws_region = var.aws_region
+
+  name                = "deadlineSweep"
+  policy              = data.aws_iam_policy_document.session_modifying_lambda_policy.json
+  lambda_env          = local.notify_lambda_env
+  schedule_expression = "rate(1 minute)"
+}
@@ -0,0 +1,51 @@
+module "sendChat_lambda" {
+  source = "./websocket-route"
+
+  aws_region = var.aws_region
+
+  api_id = aws_apigatewayv2_api.websockets_pointing.id
+  name   = "sendChat"
+  route  = "chat"
+
+  policy     = data.aws_iam_policy_document.session_modifying_lambda_policy.json
+  lambda_env = local.session_modifying_lambda_env
+}
+
+module "sendReaction_lambda" {
+  source = "./websocket-route"
+
+  aws_region = var.aws_region
+
+  api_id = aws_apigatewayv2_api.websockets_pointing.id
+  name   = "sendReaction"
+  route  = "reaction"
+
+  policy     = data.aws_iam_policy_document.session_modifying_lambda_policy.json
+  lambda_env = local.session_modifying_lambda_env
+}
+
+resource "aws_api_gateway_resource" "session_chat_resource" {
+  rest_api_id = aws_api_gateway_rest_api.rest_pointing.id
+  parent_id   = aws_api_gateway_resource.session_var.id
+  path_part   = "chat"
+}
+
+module "clearChat_lambda" {
+  source = "./rest-endpoint"
+
+  aws_region    = var.aws_region
+  api_id        = aws_api_gateway_rest_api.rest_pointing.id
+  authorizer_id = aws_api_gateway_authorizer.authorizer.id
+
+  name       = "clearChat"
+  policy     = data.aws_iam_policy_document.session_modifying_lambda_policy.json
+  lambda_env = local.session_modifying_lambda_env
+
+  http_method = "DELETE"
+  resource_id = aws_api_gateway_resource.session_chat_resource.id
+  full_path   = aws_api_gateway_resource.session_chat_resource.path
+
+  request_parameters = {
+    "method.request.path.session" = true
+  }
+}
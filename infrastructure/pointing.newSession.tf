@@ -7,7 +7,7 @@ module "newSession_lambda" {
 
   name       = "newSession"
   policy     = data.aws_iam_policy_document.session_modifying_lambda_policy.json
-  lambda_env = local.session_modifying_lambda_env
+  lambda_env = local.notify_lambda_env
 
   http_method = "POST"
   resource_id = aws_api_gateway_resource.session_path.id
@@ -15,3 +15,24 @@ module "newSession_lambda" {
 
   request_parameters = {}
 }
+
+module "listSessions_lambda" {
+  source = "./rest-endpoint"
+
+  aws_region    = var.aws_region
+  api_id        = aws_api_gateway_rest_api.rest_pointing.id
+  authorizer_id = aws_api_gateway_authorizer.authorizer.id
+
+  name       = "listSessions"
+  policy     = data.aws_iam_policy_document.session_modifying_lambda_policy.json
+  lambda_env = local.session_modifying_lambda_env
+
+  http_method = "GET"
+  resource_id = aws_api_gateway_resource.session_path.id
+  full_path   = aws_api_gateway_resource.session_path.path
+
+  request_parameters = {
+    "method.request.querystring.cursor" = false
+    "method.request.querystring.limit"  = false
+  }
+}
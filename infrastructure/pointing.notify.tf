@@ -0,0 +1,63 @@
+variable "smtp_host" {
+  type    = string
+  default = ""
+}
+
+variable "smtp_port" {
+  type    = string
+  default = "587"
+}
+
+variable "smtp_user" {
+  type    = string
+  default = ""
+}
+
+variable "smtp_password" {
+  type      = string
+  default   = ""
+  sensitive = true
+}
+
+variable "mail_from" {
+  type    = string
+  default = ""
+}
+
+locals {
+  notify_lambda_env = merge(local.session_modifying_lambda_env, {
+    PROFILE_EMAIL_INDEX = local.profile_email_index_name
+    SMTP_HOST           = var.smtp_host
+    SMTP_PORT           = var.smtp_port
+    SMTP_USER           = var.smtp_user
+    SMTP_PASSWORD       = var.smtp_password
+    MAIL_FROM           = var.mail_from
+    APP_BASE_URL        = "https://${module.ui_cert.distinct_domain_names[0]}"
+  })
+}
+
+resource "aws_api_gateway_resource" "session_remind_resource" {
+  rest_api_id = aws_api_gateway_rest_api.rest_pointing.id
+  parent_id   = aws_api_gateway_resource.session_var.id
+  path_part   = "remind"
+}
+
+module "remind_lambda" {
+  source = "./rest-endpoint"
+
+  aws_region    = var.aws_region
+  api_id        = aws_api_gateway_rest_api.rest_pointing.id
+  authorizer_id = aws_api_gateway_authorizer.authorizer.id
+
+  name       = "remind"
+  policy     = data.aws_iam_policy_document.session_modifying_lambda_policy.json
+  lambda_env = local.notify_lambda_env
+
+  http_method = "POST"
+  resource_id = aws_api_gateway_resource.session_remind_resource.id
+  full_path   = aws_api_gateway_resource.session_remind_resource.path
+
+  request_parameters = {
+    "method.request.path.session" = true
+  }
+}
@@ -37,6 +37,29 @@ data "aws_iam_policy_document" "ping_lambda_policy" {
       "arn:aws:execute-api:${var.aws_region}:${data.aws_caller_identity.current.account_id}:${aws_apigatewayv2_api.websockets_pointing.id}/*"
     ]
   }
+
+  statement {
+    sid    = "AllowSessionAccess"
+    effect = "Allow"
+    actions = [
+      "dynamodb:Query",
+      "dynamodb:UpdateItem"
+    ]
+    resources = [
+      "arn:aws:dynamodb:*:*:table/${aws_dynamodb_table.session_store.name}"
+    ]
+  }
+
+  statement {
+    sid    = "AllowSessionSocketIndexQuery"
+    effect = "Allow"
+    actions = [
+      "dynamodb:Query"
+    ]
+    resources = [
+      "arn:aws:dynamodb:*:*:table/${aws_dynamodb_table.session_store.name}/index/${local.session_socket_index_name}"
+    ]
+  }
 }
 
 module "ping_lambda" {
@@ -51,8 +74,10 @@ module "ping_lambda" {
   policy = data.aws_iam_policy_document.ping_lambda_policy.json
 
   lambda_env = {
-    LOG_LEVEL        = "info"
-    REGION           = var.aws_region
-    GATEWAY_ENDPOINT = "https://${aws_apigatewayv2_api.websockets_pointing.id}.execute-api.${var.aws_region}.amazonaws.com/${local.workspace_prefix}pointing-main/"
+    LOG_LEVEL            = "info"
+    REGION               = var.aws_region
+    GATEWAY_ENDPOINT     = "https://${aws_apigatewayv2_api.websockets_pointing.id}.execute-api.${var.aws_region}.amazonaws.com/${local.workspace_prefix}pointing-main/"
+    SESSION_TABLE        = aws_dynamodb_table.session_store.name
+    SESSION_SOCKET_INDEX = local.session_socket_index_name
   }
 }
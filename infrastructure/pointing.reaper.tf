@@ -0,0 +1,25 @@
+data "aws_iam_policy_document" "connection_reaper_lambda_policy" {
+  source_json = data.aws_iam_policy_document.session_modifying_lambda_policy.json
+
+  statement {
+    sid    = "AllowSessionSocketIndexScan"
+    effect = "Allow"
+    actions = [
+      "dynamodb:Scan"
+    ]
+    resources = [
+      "arn:aws:dynamodb:*:*:table/${aws_dynamodb_table.session_store.name}/index/${local.session_socket_index_name}"
+    ]
+  }
+}
+
+module "connectionReaper_lambda" {
+  source = "./scheduled-lambda"
+
+  aws_region = var.aws_region
+
+  name                = "connectionReaper"
+  policy              = data.aws_iam_policy_document.connection_reaper_lambda_policy.json
+  lambda_env          = local.session_modifying_lambda_env
+  schedule_expression = "rate(5 minutes)"
+}
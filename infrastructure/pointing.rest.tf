@@ -49,6 +49,31 @@ resource "aws_api_gateway_deployment" "rest_api" {
       module.clearVotes_lambda.change_keys,
       module.profileRead_lambda.change_keys,
       module.profileWrite_lambda.change_keys,
+      module.startTimer_lambda.change_keys,
+      module.revote_lambda.change_keys,
+      module.exportSession_lambda.change_keys,
+      module.importStories_lambda.change_keys,
+      module.registerWebhook_lambda.change_keys,
+      module.listWebhooks_lambda.change_keys,
+      module.removeWebhook_lambda.change_keys,
+      module.slack_lambda.change_keys,
+      module.createTeam_lambda.change_keys,
+      module.listTeams_lambda.change_keys,
+      module.listSessions_lambda.change_keys,
+      module.readTeam_lambda.change_keys,
+      module.updateTeam_lambda.change_keys,
+      module.registerTeamWebhook_lambda.change_keys,
+      module.listTeamWebhooks_lambda.change_keys,
+      module.removeTeamWebhook_lambda.change_keys,
+      module.createRoom_lambda.change_keys,
+      module.readRoom_lambda.change_keys,
+      module.startRoomSession_lambda.change_keys,
+      module.clearChat_lambda.change_keys,
+      module.announce_lambda.change_keys,
+      module.clearBanner_lambda.change_keys,
+      module.asyncVote_lambda.change_keys,
+      module.asyncSession_lambda.change_keys,
+      module.remind_lambda.change_keys,
     )))
   }
 
@@ -0,0 +1,75 @@
+resource "aws_api_gateway_resource" "room_path" {
+  rest_api_id = aws_api_gateway_rest_api.rest_pointing.id
+  parent_id   = aws_api_gateway_rest_api.rest_pointing.root_resource_id
+  path_part   = "room"
+}
+
+resource "aws_api_gateway_resource" "room_var" {
+  rest_api_id = aws_api_gateway_rest_api.rest_pointing.id
+  parent_id   = aws_api_gateway_resource.room_path.id
+  path_part   = "{room}"
+}
+
+resource "aws_api_gateway_resource" "room_session_path" {
+  rest_api_id = aws_api_gateway_rest_api.rest_pointing.id
+  parent_id   = aws_api_gateway_resource.room_var.id
+  path_part   = "session"
+}
+
+module "createRoom_lambda" {
+  source = "./rest-endpoint"
+
+  aws_region    = var.aws_region
+  api_id        = aws_api_gateway_rest_api.rest_pointing.id
+  authorizer_id = aws_api_gateway_authorizer.authorizer.id
+
+  name       = "createRoom"
+  policy     = data.aws_iam_policy_document.session_modifying_lambda_policy.json
+  lambda_env = local.session_modifying_lambda_env
+
+  http_method = "POST"
+  resource_id = aws_api_gateway_resource.room_path.id
+  full_path   = aws_api_gateway_resource.room_path.path
+
+  request_parameters = {}
+}
+
+module "readRoom_lambda" {
+  source = "./rest-endpoint"
+
+  aws_region    = var.aws_region
+  api_id        = aws_api_gateway_rest_api.rest_pointing.id
+  authorizer_id = aws_api_gateway_authorizer.authorizer.id
+
+  name       = "readRoom"
+  policy     = data.aws_iam_policy_document.session_modifying_lambda_policy.json
+  lambda_env = local.session_modifying_lambda_env
+
+  http_method = "GET"
+  resource_id = aws_api_gateway_resource.room_var.id
+  full_path   = aws_api_gateway_resource.room_var.path
+
+  request_parameters = {
+    "method.request.path.room" = true
+  }
+}
+
+module "startRoomSession_lambda" {
+  source = "./rest-endpoint"
+
+  aws_region    = var.aws_region
+  api_id        = aws_api_gateway_rest_api.rest_pointing.id
+  authorizer_id = aws_api_gateway_authorizer.authorizer.id
+
+  name       = "startRoomSession"
+  policy     = data.aws_iam_policy_document.session_modifying_lambda_policy.json
+  lambda_env = local.notify_lambda_env
+
+  http_method = "POST"
+  resource_id = aws_api_gateway_resource.room_session_path.id
+  full_path   = aws_api_gateway_resource.room_session_path.path
+
+  request_parameters = {
+    "method.request.path.room" = true
+  }
+}
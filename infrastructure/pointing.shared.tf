@@ -35,6 +35,9 @@ data "aws_iam_policy_document" "session_modifying_lambda_policy" {
       "dynamodb:Query",
       "dynamodb:DeleteItem",
       "dynamodb:PutItem",
+      "dynamodb:UpdateItem",
+      "dynamodb:BatchGetItem",
+      "dynamodb:BatchWriteItem",
       "dynamodb:DescribeStream",
       "dynamodb:DescribeTable"
     ]
@@ -54,6 +57,17 @@ data "aws_iam_policy_document" "session_modifying_lambda_policy" {
     ]
   }
 
+  statement {
+    sid    = "AllowProfileEmailIndexQuery"
+    effect = "Allow"
+    actions = [
+      "dynamodb:Query"
+    ]
+    resources = [
+      "arn:aws:dynamodb:*:*:table/${aws_dynamodb_table.profile_store.name}/index/${local.profile_email_index_name}"
+    ]
+  }
+
   statement {
     sid    = "AllowSessionSocketIndexQuery"
     effect = "Allow"
@@ -65,6 +79,89 @@ data "aws_iam_policy_document" "session_modifying_lambda_policy" {
     ]
   }
 
+  statement {
+    sid    = "AllowSessionScheduleIndexQuery"
+    effect = "Allow"
+    actions = [
+      "dynamodb:Query"
+    ]
+    resources = [
+      "arn:aws:dynamodb:*:*:table/${aws_dynamodb_table.session_store.name}/index/${local.session_schedule_index_name}"
+    ]
+  }
+
+  statement {
+    sid    = "AllowSessionHistoryIndexQuery"
+    effect = "Allow"
+    actions = [
+      "dynamodb:Query"
+    ]
+    resources = [
+      "arn:aws:dynamodb:*:*:table/${aws_dynamodb_table.session_store.name}/index/${local.session_history_index_name}"
+    ]
+  }
+
+  statement {
+    sid    = "AllowWebhookAccess"
+    effect = "Allow"
+    actions = [
+      "dynamodb:Query",
+      "dynamodb:DeleteItem",
+      "dynamodb:PutItem"
+    ]
+    resources = [
+      "arn:aws:dynamodb:*:*:table/${aws_dynamodb_table.webhook_store.name}"
+    ]
+  }
+
+  statement {
+    sid    = "AllowTeamAccess"
+    effect = "Allow"
+    actions = [
+      "dynamodb:Query",
+      "dynamodb:DeleteItem",
+      "dynamodb:PutItem"
+    ]
+    resources = [
+      "arn:aws:dynamodb:*:*:table/${aws_dynamodb_table.team_store.name}"
+    ]
+  }
+
+  statement {
+    sid    = "AllowTeamMemberIndexQuery"
+    effect = "Allow"
+    actions = [
+      "dynamodb:Query"
+    ]
+    resources = [
+      "arn:aws:dynamodb:*:*:table/${aws_dynamodb_table.team_store.name}/index/${local.team_member_index_name}"
+    ]
+  }
+
+  statement {
+    sid    = "AllowRoomAccess"
+    effect = "Allow"
+    actions = [
+      "dynamodb:GetItem",
+      "dynamodb:PutItem",
+      "dynamodb:UpdateItem"
+    ]
+    resources = [
+      "arn:aws:dynamodb:*:*:table/${aws_dynamodb_table.room_store.name}"
+    ]
+  }
+
+  statement {
+    sid    = "AllowWebhookDeliveryInvoke"
+    effect = "Allow"
+    actions = [
+      "lambda:InvokeFunction"
+    ]
+    resources = [
+      "arn:aws:lambda:${var.aws_region}:${data.aws_caller_identity.current.account_id}:function:${local.webhook_delivery_function_name}"
+    ]
+  }
+
   statement {
     sid    = "AllowMessages"
     effect = "Allow"
@@ -79,12 +176,19 @@ data "aws_iam_policy_document" "session_modifying_lambda_policy" {
 
 locals {
   session_modifying_lambda_env = {
-    REGION               = var.aws_region
-    GATEWAY_ENDPOINT     = "https://${aws_apigatewayv2_api.websockets_pointing.id}.execute-api.${var.aws_region}.amazonaws.com/${local.workspace_prefix}pointing-main/"
-    SESSION_TABLE        = aws_dynamodb_table.session_store.name
-    PROFILE_TABLE        = aws_dynamodb_table.profile_store.name
-    SESSION_SOCKET_INDEX = local.session_socket_index_name
-    LOG_LEVEL            = "info"
-    ALLOWED_ORIGINS      = "https://${module.ui_cert.distinct_domain_names[0]},https://${module.ui_cert.distinct_domain_names[1]},http://localhost:8080"
+    REGION                    = var.aws_region
+    GATEWAY_ENDPOINT          = "https://${aws_apigatewayv2_api.websockets_pointing.id}.execute-api.${var.aws_region}.amazonaws.com/${local.workspace_prefix}pointing-main/"
+    SESSION_TABLE             = aws_dynamodb_table.session_store.name
+    PROFILE_TABLE             = aws_dynamodb_table.profile_store.name
+    SESSION_SOCKET_INDEX      = local.session_socket_index_name
+    SESSION_SCHEDULE_INDEX    = local.session_schedule_index_name
+    SESSION_HISTORY_INDEX     = local.session_history_index_name
+    WEBHOOK_TABLE             = aws_dynamodb_table.webhook_store.name
+    WEBHOOK_DELIVERY_FUNCTION = local.webhook_delivery_function_name
+    TEAM_TABLE                = aws_dynamodb_table.team_store.name
+    TEAM_MEMBER_INDEX         = local.team_member_index_name
+    ROOM_TABLE                = aws_dynamodb_table.room_store.name
+    LOG_LEVEL                 = "info"
+    ALLOWED_ORIGINS           = "https://${module.ui_cert.distinct_domain_names[0]},https://${module.ui_cert.distinct_domain_names[1]},http://localhost:8080"
   }
 }
\ No newline at end of file
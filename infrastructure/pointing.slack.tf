@@ -0,0 +1,38 @@
+variable "slack_signing_secret" {
+  type      = string
+  default   = ""
+  sensitive = true
+}
+
+variable "slack_bot_token" {
+  type      = string
+  default   = ""
+  sensitive = true
+}
+
+resource "aws_api_gateway_resource" "slack_resource" {
+  rest_api_id = aws_api_gateway_rest_api.rest_pointing.id
+  parent_id   = aws_api_gateway_rest_api.rest_pointing.root_resource_id
+  path_part   = "slack"
+}
+
+// slack can't send our tokens so there is no authorizer, requests are checked against the signing secret instead
+module "slack_lambda" {
+  source = "./rest-endpoint"
+
+  aws_region = var.aws_region
+  api_id     = aws_api_gateway_rest_api.rest_pointing.id
+
+  name   = "slack"
+  policy = data.aws_iam_policy_document.session_modifying_lambda_policy.json
+  lambda_env = merge(local.session_modifying_lambda_env, {
+    SLACK_SIGNING_SECRET = var.slack_signing_secret
+    SLACK_BOT_TOKEN      = var.slack_bot_token
+  })
+
+  http_method = "POST"
+  resource_id = aws_api_gateway_resource.slack_resource.id
+  full_path   = aws_api_gateway_resource.slack_resource.path
+
+  request_parameters = {}
+}
@@ -0,0 +1,161 @@
+resource "aws_api_gateway_resource" "team_path" {
+  rest_api_id = aws_api_gateway_rest_api.rest_pointing.id
+  parent_id   = aws_api_gateway_rest_api.rest_pointing.root_resource_id
+  path_part   = "team"
+}
+
+resource "aws_api_gateway_resource" "team_var" {
+  rest_api_id = aws_api_gateway_rest_api.rest_pointing.id
+  parent_id   = aws_api_gateway_resource.team_path.id
+  path_part   = "{team}"
+}
+
+resource "aws_api_gateway_resource" "team_webhook_path" {
+  rest_api_id = aws_api_gateway_rest_api.rest_pointing.id
+  parent_id   = aws_api_gateway_resource.team_var.id
+  path_part   = "webhook"
+}
+
+resource "aws_api_gateway_resource" "team_webhook_var" {
+  rest_api_id = aws_api_gateway_rest_api.rest_pointing.id
+  parent_id   = aws_api_gateway_resource.team_webhook_path.id
+  path_part   = "{webhook}"
+}
+
+module "createTeam_lambda" {
+  source = "./rest-endpoint"
+
+  aws_region    = var.aws_region
+  api_id        = aws_api_gateway_rest_api.rest_pointing.id
+  authorizer_id = aws_api_gateway_authorizer.authorizer.id
+
+  name       = "createTeam"
+  policy     = data.aws_iam_policy_document.session_modifying_lambda_policy.json
+  lambda_env = local.session_modifying_lambda_env
+
+  http_method = "POST"
+  resource_id = aws_api_gateway_resource.team_path.id
+  full_path   = aws_api_gateway_resource.team_path.path
+
+  request_parameters = {}
+}
+
+module "listTeams_lambda" {
+  source = "./rest-endpoint"
+
+  aws_region    = var.aws_region
+  api_id        = aws_api_gateway_rest_api.rest_pointing.id
+  authorizer_id = aws_api_gateway_authorizer.authorizer.id
+
+  name       = "listTeams"
+  policy     = data.aws_iam_policy_document.session_modifying_lambda_policy.json
+  lambda_env = local.session_modifying_lambda_env
+
+  http_method = "GET"
+  resource_id = aws_api_gateway_resource.team_path.id
+  full_path   = aws_api_gateway_resource.team_path.path
+
+  request_parameters = {}
+}
+
+module "readTeam_lambda" {
+  source = "./rest-endpoint"
+
+  aws_region    = var.aws_region
+  api_id        = aws_api_gateway_rest_api.rest_pointing.id
+  authorizer_id = aws_api_gateway_authorizer.authorizer.id
+
+  name       = "readTeam"
+  policy     = data.aws_iam_policy_document.session_modifying_lambda_policy.json
+  lambda_env = local.session_modifying_lambda_env
+
+  http_method = "GET"
+  resource_id = aws_api_gateway_resource.team_var.id
+  full_path   = aws_api_gateway_resource.team_var.path
+
+  request_parameters = {
+    "method.request.path.team" = true
+  }
+}
+
+module "updateTeam_lambda" {
+  source = "./rest-endpoint"
+
+  aws_region    = var.aws_region
+  api_id        = aws_api_gateway_rest_api.rest_pointing.id
+  authorizer_id = aws_api_gateway_authorizer.authorizer.id
+
+  name       = "updateTeam"
+  policy     = data.aws_iam_policy_document.session_modifying_lambda_policy.json
+  lambda_env = local.session_modifying_lambda_env
+
+  http_method = "PUT"
+  resource_id = aws_api_gateway_resource.team_var.id
+  full_path   = aws_api_gateway_resource.team_var.path
+
+  request_parameters = {
+    "method.request.path.team" = true
+  }
+}
+
+// team webhooks are served by the same code as session webhooks, which tells them apart by path
+module "registerTeamWebhook_lambda" {
+  source = "./rest-endpoint"
+
+  aws_region    = var.aws_region
+  api_id        = aws_api_gateway_rest_api.rest_pointing.id
+  authorizer_id = aws_api_gateway_authorizer.authorizer.id
+
+  name       = "registerTeamWebhook"
+  policy     = data.aws_iam_policy_document.session_modifying_lambda_policy.json
+  lambda_env = local.session_modifying_lambda_env
+
+  http_method = "POST"
+  resource_id = aws_api_gateway_resource.team_webhook_path.id
+  full_path   = aws_api_gateway_resource.team_webhook_path.path
+
+  request_parameters = {
+    "method.request.path.team" = true
+  }
+}
+
+module "listTeamWebhooks_lambda" {
+  source = "./rest-endpoint"
+
+  aws_region    = var.aws_region
+  api_id        = aws_api_gateway_rest_api.rest_pointing.id
+  authorizer_id = aws_api_gateway_authorizer.authorizer.id
+
+  name       = "listTeamWebhooks"
+  policy     = data.aws_iam_policy_document.session_modifying_lambda_policy.json
+  lambda_env = local.session_modifying_lambda_env
+
+  http_method = "GET"
+  resource_id = aws_api_gateway_resource.team_webhook_path.id
+  full_path   = aws_api_gateway_resource.team_webhook_path.path
+
+  request_parameters = {
+    "method.request.path.team" = true
+  }
+}
+
+module "removeTeamWebhook_lambda" {
+  source = "./rest-endpoint"
+
+  aws_region    = var.aws_region
+  api_id        = aws_api_gateway_rest_api.rest_pointing.id
+  authorizer_id = aws_api_gateway_authorizer.authorizer.id
+
+  name       = "removeTeamWebhook"
+  policy     = data.aws_iam_policy_document.session_modifying_lambda_policy.json
+  lambda_env = local.session_modifying_lambda_env
+
+  http_method = "DELETE"
+  resource_id = aws_api_gateway_resource.team_webhook_var.id
+  full_path   = aws_api_gateway_resource.team_webhook_var.path
+
+  request_parameters = {
+    "method.request.path.team"    = true
+    "method.request.path.webhook" = true
+  }
+}
@@ -0,0 +1,36 @@
+resource "aws_api_gateway_resource" "session_timer_resource" {
+  rest_api_id = aws_api_gateway_rest_api.rest_pointing.id
+  parent_id   = aws_api_gateway_resource.session_var.id
+  path_part   = "timer"
+}
+
+module "startTimer_lambda" {
+  source = "./rest-endpoint"
+
+  aws_region    = var.aws_region
+  api_id        = aws_api_gateway_rest_api.rest_pointing.id
+  authorizer_id = aws_api_gateway_authorizer.authorizer.id
+
+  name       = "startTimer"
+  policy     = data.aws_iam_policy_document.session_modifying_lambda_policy.json
+  lambda_env = local.session_modifying_lambda_env
+
+  http_method = "PUT"
+  resource_id = aws_api_gateway_resource.session_timer_resource.id
+  full_path   = aws_api_gateway_resource.session_timer_resource.path
+
+  request_parameters = {
+    "method.request.path.session" = true
+  }
+}
+
+module "timerSweep_lambda" {
+  source = "./scheduled-lambda"
+
+  aws_region = var.aws_region
+
+  name                = "timerSweep"
+  policy              = data.aws_iam_policy_document.session_modifying_lambda_policy.json
+  lambda_env          = local.tracker_lambda_env
+  schedule_expression = "rate(1 minute)"
+}
@@ -0,0 +1,77 @@
+variable "jira_base_url" {
+  type    = string
+  default = ""
+}
+
+variable "jira_user" {
+  type    = string
+  default = ""
+}
+
+variable "jira_api_token" {
+  type      = string
+  default   = ""
+  sensitive = true
+}
+
+variable "jira_story_point_field" {
+  type    = string
+  default = "customfield_10016"
+}
+
+variable "github_owner" {
+  type    = string
+  default = ""
+}
+
+variable "github_repo" {
+  type    = string
+  default = ""
+}
+
+variable "github_token" {
+  type      = string
+  default   = ""
+  sensitive = true
+}
+
+locals {
+  // trackers are only enabled when their settings are provided, so an unconfigured deploy just won't offer them. The
+  // slack token is here so reveals can refresh the voting message of sessions started from slack.
+  tracker_lambda_env = merge(local.session_modifying_lambda_env, {
+    JIRA_BASE_URL          = var.jira_base_url
+    JIRA_USER              = var.jira_user
+    JIRA_API_TOKEN         = var.jira_api_token
+    JIRA_STORY_POINT_FIELD = var.jira_story_point_field
+    GITHUB_OWNER           = var.github_owner
+    GITHUB_REPO            = var.github_repo
+    GITHUB_TOKEN           = var.github_token
+    SLACK_BOT_TOKEN        = var.slack_bot_token
+  })
+}
+
+resource "aws_api_gateway_resource" "session_stories_resource" {
+  rest_api_id = aws_api_gateway_rest_api.rest_pointing.id
+  parent_id   = aws_api_gateway_resource.session_var.id
+  path_part   = "stories"
+}
+
+module "importStories_lambda" {
+  source = "./rest-endpoint"
+
+  aws_region    = var.aws_region
+  api_id        = aws_api_gateway_rest_api.rest_pointing.id
+  authorizer_id = aws_api_gateway_authorizer.authorizer.id
+
+  name       = "importStories"
+  policy     = data.aws_iam_policy_document.session_modifying_lambda_policy.json
+  lambda_env = local.tracker_lambda_env
+
+  http_method = "POST"
+  resource_id = aws_api_gateway_resource.session_stories_resource.id
+  full_path   = aws_api_gateway_resource.session_stories_resource.path
+
+  request_parameters = {
+    "method.request.path.session" = true
+  }
+}
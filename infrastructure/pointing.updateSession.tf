@@ -7,7 +7,8 @@ module "updateSession_lambda" {
 
   name       = "updateSession"
   policy     = data.aws_iam_policy_document.session_modifying_lambda_policy.json
-  lambda_env = local.session_modifying_lambda_env
+  // closing an async session early emails out the results, on top of the usual reveal handling
+  lambda_env = merge(local.tracker_lambda_env, local.notify_lambda_env)
 
   http_method = "PUT"
   resource_id = aws_api_gateway_resource.session_var.id
@@ -13,7 +13,7 @@ module "vote_lambda" {
 
   name       = "vote"
   policy     = data.aws_iam_policy_document.session_modifying_lambda_policy.json
-  lambda_env = local.session_modifying_lambda_env
+  lambda_env = local.tracker_lambda_env
 
   http_method = "PUT"
   resource_id = aws_api_gateway_resource.vote_resource.id
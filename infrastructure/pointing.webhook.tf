@@ -0,0 +1,134 @@
+locals {
+  // referenced by name rather than through the module to keep the session lambdas free of a dependency on it
+  webhook_delivery_function_name = "${local.workspace_prefix}deliverWebhooks"
+}
+
+data "aws_iam_policy_document" "webhook_delivery_lambda_policy" {
+  statement {
+    sid    = "AllowLogging"
+    effect = "Allow"
+    actions = [
+      "logs:CreateLogGroup",
+      "logs:CreateLogStream",
+      "logs:PutLogEvents"
+    ]
+    resources = [
+      "arn:aws:logs:*:*:*"
+    ]
+  }
+
+  statement {
+    sid    = "AllowXRayWrite"
+    effect = "Allow"
+    actions = [
+      "xray:PutTraceSegments",
+      "xray:PutTelemetryRecords",
+      "xray:GetSamplingRules",
+      "xray:GetSamplingTargets",
+      "xray:GetSamplingStatisticSummaries"
+    ]
+    resources = [
+      "*"
+    ]
+  }
+
+  statement {
+    sid    = "AllowWebhookAccess"
+    effect = "Allow"
+    actions = [
+      "dynamodb:Query",
+      "dynamodb:PutItem"
+    ]
+    resources = [
+      "arn:aws:dynamodb:*:*:table/${aws_dynamodb_table.webhook_store.name}"
+    ]
+  }
+}
+
+module "deliverWebhooks_lambda" {
+  source = "./worker-lambda"
+
+  aws_region = var.aws_region
+
+  name   = "deliverWebhooks"
+  policy = data.aws_iam_policy_document.webhook_delivery_lambda_policy.json
+  lambda_env = {
+    LOG_LEVEL     = "info"
+    WEBHOOK_TABLE = aws_dynamodb_table.webhook_store.name
+  }
+  // enough to work through the retries for a handful of slow webhooks
+  timeout = 120
+}
+
+resource "aws_api_gateway_resource" "session_webhook_path" {
+  rest_api_id = aws_api_gateway_rest_api.rest_pointing.id
+  parent_id   = aws_api_gateway_resource.session_var.id
+  path_part   = "webhook"
+}
+
+resource "aws_api_gateway_resource" "session_webhook_var" {
+  rest_api_id = aws_api_gateway_rest_api.rest_pointing.id
+  parent_id   = aws_api_gateway_resource.session_webhook_path.id
+  path_part   = "{webhook}"
+}
+
+module "registerWebhook_lambda" {
+  source = "./rest-endpoint"
+
+  aws_region    = var.aws_region
+  api_id        = aws_api_gateway_rest_api.rest_pointing.id
+  authorizer_id = aws_api_gateway_authorizer.authorizer.id
+
+  name       = "registerWebhook"
+  policy     = data.aws_iam_policy_document.session_modifying_lambda_policy.json
+  lambda_env = local.session_modifying_lambda_env
+
+  http_method = "POST"
+  resource_id = aws_api_gateway_resource.session_webhook_path.id
+  full_path   = aws_api_gateway_resource.session_webhook_path.path
+
+  request_parameters = {
+    "method.request.path.session" = true
+  }
+}
+
+module "listWebhooks_lambda" {
+  source = "./rest-endpoint"
+
+  aws_region    = var.aws_region
+  api_id        = aws_api_gateway_rest_api.rest_pointing.id
+  authorizer_id = aws_api_gateway_authorizer.authorizer.id
+
+  name       = "listWebhooks"
+  policy     = data.aws_iam_policy_document.session_modifying_lambda_policy.json
+  lambda_env = local.session_modifying_lambda_env
+
+  http_method = "GET"
+  resource_id = aws_api_gateway_resource.session_webhook_path.id
+  full_path   = aws_api_gateway_resource.session_webhook_path.path
+
+  request_parameters = {
+    "method.request.path.session" = true
+  }
+}
+
+module "removeWebhook_lambda" {
+  source = "./rest-endpoint"
+
+  aws_region    = var.aws_region
+  api_id        = aws_api_gateway_rest_api.rest_pointing.id
+  authorizer_id = aws_api_gateway_authorizer.authorizer.id
+
+  name       = "removeWebhook"
+  policy     = data.aws_iam_policy_document.session_modifying_lambda_policy.json
+  lambda_env = local.session_modifying_lambda_env
+
+  http_method = "DELETE"
+  resource_id = aws_api_gateway_resource.session_webhook_var.id
+  full_path   = aws_api_gateway_resource.session_webhook_var.path
+
+  request_parameters = {
+    "method.request.path.session" = true
+    "method.request.path.webhook" = true
+  }
+}
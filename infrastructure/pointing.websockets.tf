@@ -35,6 +35,8 @@ resource "aws_apigatewayv2_deployment" "websockets_pointing" {
       module.connect_lambda.change_keys,
       module.disconnect_lambda.change_keys,
       module.ping_lambda.change_keys,
+      module.sendChat_lambda.change_keys,
+      module.sendReaction_lambda.change_keys,
     )))
   }
 
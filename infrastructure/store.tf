@@ -1,5 +1,9 @@
 locals {
-  session_socket_index_name = "${local.workspace_prefix}SessionSockets"
+  session_socket_index_name   = "${local.workspace_prefix}SessionSockets"
+  session_schedule_index_name = "${local.workspace_prefix}SessionSchedule"
+  session_history_index_name  = "${local.workspace_prefix}SessionHistory"
+  team_member_index_name      = "${local.workspace_prefix}TeamMembers"
+  profile_email_index_name    = "${local.workspace_prefix}ProfileEmail"
 }
 
 resource "aws_dynamodb_table" "session_store" {
@@ -23,12 +27,47 @@ resource "aws_dynamodb_table" "session_store" {
     type = "S"
   }
 
+  attribute {
+    name = "ScheduleShard"
+    type = "S"
+  }
+
+  attribute {
+    name = "ScheduledAt"
+    type = "N"
+  }
+
+  attribute {
+    name = "HistoryUserID"
+    type = "S"
+  }
+
+  attribute {
+    name = "JoinedAt"
+    type = "N"
+  }
+
   global_secondary_index {
     name            = local.session_socket_index_name
     hash_key        = "SocketID"
     projection_type = "KEYS_ONLY"
   }
 
+  global_secondary_index {
+    name            = local.session_schedule_index_name
+    hash_key        = "ScheduleShard"
+    range_key       = "ScheduledAt"
+    projection_type = "KEYS_ONLY"
+  }
+
+  global_secondary_index {
+    name               = local.session_history_index_name
+    hash_key           = "HistoryUserID"
+    range_key          = "JoinedAt"
+    projection_type    = "INCLUDE"
+    non_key_attributes = ["Role"]
+  }
+
   ttl {
     enabled        = "true"
     attribute_name = "Expiration"
@@ -49,7 +88,92 @@ resource "aws_dynamodb_table" "profile_store" {
     type = "S"
   }
 
+  attribute {
+    name = "EmailLookup"
+    type = "S"
+  }
+
+  global_secondary_index {
+    name            = local.profile_email_index_name
+    hash_key        = "EmailLookup"
+    projection_type = "ALL"
+  }
+
   tags = {
     Workspace = terraform.workspace
   }
-}
\ No newline at end of file
+}
+resource "aws_dynamodb_table" "webhook_store" {
+  hash_key     = "OwnerID"
+  range_key    = "RangeKey"
+  name         = "${local.workspace_prefix}Webhook"
+  billing_mode = "PAY_PER_REQUEST"
+
+  attribute {
+    name = "OwnerID"
+    type = "S"
+  }
+
+  attribute {
+    name = "RangeKey"
+    type = "S"
+  }
+
+  ttl {
+    enabled        = "true"
+    attribute_name = "Expiration"
+  }
+
+  tags = {
+    Workspace = terraform.workspace
+  }
+}
+
+resource "aws_dynamodb_table" "team_store" {
+  hash_key     = "TeamID"
+  range_key    = "RangeKey"
+  name         = "${local.workspace_prefix}Team"
+  billing_mode = "PAY_PER_REQUEST"
+
+  attribute {
+    name = "TeamID"
+    type = "S"
+  }
+
+  attribute {
+    name = "RangeKey"
+    type = "S"
+  }
+
+  attribute {
+    name = "Email"
+    type = "S"
+  }
+
+  global_secondary_index {
+    name               = local.team_member_index_name
+    hash_key           = "Email"
+    range_key          = "TeamID"
+    projection_type    = "INCLUDE"
+    non_key_attributes = ["TeamName", "Role"]
+  }
+
+  tags = {
+    Workspace = terraform.workspace
+  }
+}
+
+resource "aws_dynamodb_table" "room_store" {
+  hash_key     = "Slug"
+  name         = "${local.workspace_prefix}Room"
+  billing_mode = "PAY_PER_REQUEST"
+
+  attribute {
+    name = "Slug"
+    type = "S"
+  }
+
+  tags = {
+    Workspace = terraform.workspace
+  }
+}
@@ -0,0 +1,64 @@
+data "aws_caller_identity" "current" {}
+
+locals {
+  workspace_prefix = terraform.workspace == "default" ? "" : "${terraform.workspace}-"
+}
+
+data "aws_iam_policy_document" "assume_lambda_role_policy" {
+  statement {
+    actions = ["sts:AssumeRole"]
+
+    principals {
+      identifiers = [
+        "lambda.amazonaws.com"
+      ]
+
+      type = "Service"
+    }
+
+    effect = "Allow"
+    sid    = "AllowLambdaAssumeRole"
+  }
+}
+
+resource "aws_iam_role" "lambda_role" {
+  name               = "${local.workspace_prefix}${var.name}PointingLambdaRole"
+  assume_role_policy = data.aws_iam_policy_document.assume_lambda_role_policy.json
+
+  tags = {
+    Workspace = terraform.workspace
+  }
+}
+
+resource "aws_iam_role_policy" "lambda_role_policy" {
+  role   = aws_iam_role.lambda_role.name
+  policy = var.policy
+}
+
+resource "aws_lambda_function" "lambda" {
+  filename         = "../dist/${var.name}Lambda.zip"
+  source_code_hash = filebase64sha256("../dist/${var.name}Lambda.zip")
+  runtime          = "provided.al2"
+  handler          = "bootstrap"
+  architectures    = ["arm64"]
+  function_name    = "${local.workspace_prefix}${var.name}"
+  role             = aws_iam_role.lambda_role.arn
+  timeout          = var.timeout
+
+  tracing_config {
+    mode = "Active"
+  }
+
+  environment {
+    variables = var.lambda_env
+  }
+
+  tags = {
+    Workspace = terraform.workspace
+  }
+}
+
+resource "aws_cloudwatch_log_group" "lambda_logs" {
+  name              = "/aws/lambda/${aws_lambda_function.lambda.function_name}"
+  retention_in_days = 7
+}
@@ -0,0 +1,19 @@
+variable "aws_region" {
+  type = string
+}
+
+variable "policy" {
+  type = string
+}
+
+variable "name" {
+  type = string
+}
+
+variable "lambda_env" {
+  type = map(string)
+}
+
+variable "timeout" {
+  type = number
+}
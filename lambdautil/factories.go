@@ -1,6 +1,10 @@
 package lambdautil
 
 import (
+	"context"
+	"net"
+	"net/http"
+	"net/smtp"
 	"os"
 	"strings"
 	"time"
@@ -9,16 +13,33 @@ import (
 	awssession "github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/apigatewaymanagementapi"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
+	awslambda "github.com/aws/aws-sdk-go/service/lambda"
 	"github.com/aws/aws-xray-sdk-go/xray"
 
 	"github.com/jonsabados/pointypoints/api"
+	"github.com/jonsabados/pointypoints/notify"
+	"github.com/jonsabados/pointypoints/profile"
+	"github.com/jonsabados/pointypoints/session"
+	"github.com/jonsabados/pointypoints/slack"
+	"github.com/jonsabados/pointypoints/tracker"
+	"github.com/jonsabados/pointypoints/webhook"
 )
 
 const SessionTimeout = time.Hour * 72
 
+// ResumeGracePeriod is how long a participant who lost their connection has to come back before their vote is gone
+const ResumeGracePeriod = time.Minute * 30
+
 var SessionTable = os.Getenv("SESSION_TABLE")
 var ProfileTable = os.Getenv("PROFILE_TABLE")
+var ProfileEmailIndex = os.Getenv("PROFILE_EMAIL_INDEX")
 var SessionSocketIndex = os.Getenv("SESSION_SOCKET_INDEX")
+var SessionScheduleIndex = os.Getenv("SESSION_SCHEDULE_INDEX")
+var SessionHistoryIndex = os.Getenv("SESSION_HISTORY_INDEX")
+var WebhookTable = os.Getenv("WEBHOOK_TABLE")
+var TeamTable = os.Getenv("TEAM_TABLE")
+var TeamMemberIndex = os.Getenv("TEAM_MEMBER_INDEX")
+var RoomTable = os.Getenv("ROOM_TABLE")
 
 func AllowedCORSOrigins() []string {
 	return strings.Split(os.Getenv("ALLOWED_ORIGINS"), ",")
@@ -34,6 +55,14 @@ func CoreStartup() {
 }
 
 func NewProdMessageDispatcher() api.MessageDispatcher {
+	return api.NewMessageDispatcher(newGatewayClient())
+}
+
+func NewProdConnectionProber() api.ConnectionProber {
+	return api.NewConnectionProber(newGatewayClient())
+}
+
+func newGatewayClient() *apigatewaymanagementapi.ApiGatewayManagementApi {
 	gatewaysession, err := awssession.NewSession(&aws.Config{
 		Region:   aws.String(os.Getenv("REGION")),
 		Endpoint: aws.String(os.Getenv("GATEWAY_ENDPOINT")),
@@ -43,7 +72,7 @@ func NewProdMessageDispatcher() api.MessageDispatcher {
 	}
 	gateway := apigatewaymanagementapi.New(gatewaysession)
 	xray.AWS(gateway.Client)
-	return api.NewMessageDispatcher(gateway)
+	return gateway
 }
 
 func DefaultAWSConfig() *awssession.Session {
@@ -59,3 +88,69 @@ func NewDynamoClient(sess *awssession.Session) *dynamodb.DynamoDB {
 	xray.AWS(dynamo.Client)
 	return dynamo
 }
+
+// NewTrackers returns the issue trackers that have been configured for this deployment, keyed by source
+func NewTrackers() map[string]tracker.Tracker {
+	client := xray.Client(&http.Client{
+		Timeout: 10 * time.Second,
+	})
+	ret := make(map[string]tracker.Tracker)
+	if baseURL := os.Getenv("JIRA_BASE_URL"); baseURL != "" {
+		ret[tracker.SourceJira] = tracker.NewJira(tracker.JiraConfig{
+			BaseURL:         baseURL,
+			User:            os.Getenv("JIRA_USER"),
+			APIToken:        os.Getenv("JIRA_API_TOKEN"),
+			StoryPointField: os.Getenv("JIRA_STORY_POINT_FIELD"),
+		}, client)
+	}
+	if repo := os.Getenv("GITHUB_REPO"); repo != "" {
+		ret[tracker.SourceGitHub] = tracker.NewGitHub(tracker.GitHubConfig{
+			APIURL:              os.Getenv("GITHUB_API_URL"),
+			Owner:               os.Getenv("GITHUB_OWNER"),
+			Repo:                repo,
+			Token:               os.Getenv("GITHUB_TOKEN"),
+			EstimateLabelPrefix: os.Getenv("GITHUB_ESTIMATE_LABEL_PREFIX"),
+		}, client)
+	}
+	return ret
+}
+
+// NewIntegrationNotifier keeps messages posted by integrations, like the slack voting message, up to date. Deployments
+// without slack configured get a notifier that does nothing.
+func NewIntegrationNotifier() session.ChangeNotifier {
+	token := os.Getenv("SLACK_BOT_TOKEN")
+	if token == "" {
+		return func(ctx context.Context, updated session.CompleteSessionView) error {
+			return nil
+		}
+	}
+	return slack.NewMessageNotifier(slack.NewClient(os.Getenv("SLACK_API_URL"), token, xray.Client(&http.Client{
+		Timeout: 5 * time.Second,
+	})))
+}
+
+func NewWebhookPublisher(sess *awssession.Session) webhook.Publisher {
+	invoker := awslambda.New(sess)
+	xray.AWS(invoker.Client)
+	return webhook.NewPublisher(invoker, os.Getenv("WEBHOOK_DELIVERY_FUNCTION"))
+}
+
+// NewNotifier emails through the configured SMTP server, deployments without one just don't send email
+func NewNotifier(dynamo *dynamodb.DynamoDB) notify.Notifier {
+	host := os.Getenv("SMTP_HOST")
+	if host == "" {
+		return func(ctx context.Context, kind notify.Kind, sess session.CompleteSessionView, emails []string) error {
+			return nil
+		}
+	}
+	port := os.Getenv("SMTP_PORT")
+	if port == "" {
+		port = "587"
+	}
+	var auth smtp.Auth
+	if user := os.Getenv("SMTP_USER"); user != "" {
+		auth = smtp.PlainAuth("", user, os.Getenv("SMTP_PASSWORD"), host)
+	}
+	mailer := notify.NewSMTPMailer(net.JoinHostPort(host, port), os.Getenv("MAIL_FROM"), auth)
+	return notify.NewNotifier(mailer, profile.NewEmailFetcher(dynamo, ProfileTable, ProfileEmailIndex), os.Getenv("APP_BASE_URL"))
+}
@@ -0,0 +1,58 @@
+package lambdautil
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jonsabados/goauth/aws"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+
+	"github.com/jonsabados/pointypoints/api"
+	"github.com/jonsabados/pointypoints/session"
+	"github.com/jonsabados/pointypoints/team"
+	"github.com/jonsabados/pointypoints/webhook"
+)
+
+// WebhookOwnerResolver works out whose webhooks a request is managing, returning an empty owner if the caller isn't
+// allowed to. Registrations should be cleaned up at the returned expiration, unless it is zero.
+type WebhookOwnerResolver func(ctx context.Context, request events.APIGatewayProxyRequest) (string, time.Time, error)
+
+// NewWebhookOwnerResolver handles both team webhooks, which live under /team/{team} and are managed by the team's
+// facilitators, and session webhooks which are managed by whoever holds the facilitator key
+func NewWebhookOwnerResolver(loadSession session.Loader, loadTeam team.Loader) WebhookOwnerResolver {
+	return func(ctx context.Context, request events.APIGatewayProxyRequest) (string, time.Time, error) {
+		if teamID, ok := request.PathParameters["team"]; ok {
+			t, err := loadTeam(ctx, teamID)
+			if err != nil {
+				return "", time.Time{}, errors.WithStack(err)
+			}
+			principal, err := aws.ExtractPrincipal(request)
+			if err != nil {
+				return "", time.Time{}, errors.WithStack(err)
+			}
+			if t == nil || !t.CanFacilitate(principal.Email) {
+				zerolog.Ctx(ctx).Warn().Str("teamID", teamID).Str("userID", principal.UserID).Msg("attempt to manage team webhooks by non facilitator")
+				return "", time.Time{}, nil
+			}
+			return webhook.TeamOwner(teamID), time.Time{}, nil
+		}
+
+		sessionID := request.PathParameters["session"]
+		sess, err := loadSession(ctx, sessionID)
+		if err != nil {
+			return "", time.Time{}, errors.WithStack(err)
+		}
+		if sess == nil {
+			zerolog.Ctx(ctx).Warn().Str("sessionID", sessionID).Msg("session not found")
+			return "", time.Time{}, nil
+		}
+		if facilitatorKey := api.FacilitatorKey(request.Headers); sess.FacilitatorSessionKey != facilitatorKey {
+			zerolog.Ctx(ctx).Warn().Str("sessionID", sessionID).Msg("attempt to manage webhooks with incorrect facilitator key")
+			return "", time.Time{}, nil
+		}
+		// session webhooks have no use once the session is gone
+		return webhook.SessionOwner(sessionID), time.Now().Add(SessionTimeout), nil
+	}
+}
@@ -0,0 +1,54 @@
+package launch
+
+import (
+	"context"
+
+	"github.com/jonsabados/goauth"
+	"github.com/rs/zerolog"
+
+	"github.com/jonsabados/pointypoints/notify"
+	"github.com/jonsabados/pointypoints/session"
+	"github.com/jonsabados/pointypoints/team"
+	"github.com/jonsabados/pointypoints/webhook"
+)
+
+// Starter starts a session on behalf of a team, or nobody in particular when t is nil, and lets everyone interested
+// know about it. Callers are expected to have validated the request and checked the initiator is allowed to start
+// sessions for the team.
+type Starter func(ctx context.Context, initiator goauth.Principal, t *team.Team, toStart session.StartRequest) (session.CompleteSessionView, error)
+
+func NewStarter(startSession session.Starter, publish webhook.Publisher, sendNotifications notify.Notifier) Starter {
+	return func(ctx context.Context, initiator goauth.Principal, t *team.Team, toStart session.StartRequest) (session.CompleteSessionView, error) {
+		toStart.Facilitator.SocketID = toStart.ConnectionID
+		// the team passed in decides which team a session belongs to, never the request
+		toStart.TeamID = ""
+		if t != nil {
+			team.ApplyDefaults(*t, &toStart)
+		}
+
+		sess, err := startSession(ctx, initiator, toStart)
+		if err != nil {
+			return session.CompleteSessionView{}, err
+		}
+
+		// the session has started at this point, so failures letting folks know about it are only logged
+		err = publish(ctx, webhook.NewSessionStartedEvent(sess))
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("error publishing webhook event")
+		}
+
+		// the rest of the team gets told about sessions started for them, async ones come with the stories to point
+		if t != nil {
+			kind := notify.KindStarted
+			if sess.Deadline != nil {
+				kind = notify.KindInvite
+			}
+			err = sendNotifications(ctx, kind, sess, notify.TeamEmails(*t, initiator.Email))
+			if err != nil {
+				zerolog.Ctx(ctx).Error().Err(err).Msg("error notifying team")
+			}
+		}
+
+		return sess, nil
+	}
+}
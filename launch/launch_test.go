@@ -0,0 +1,149 @@
+package launch_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jonsabados/goauth"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jonsabados/pointypoints/launch"
+	"github.com/jonsabados/pointypoints/notify"
+	"github.com/jonsabados/pointypoints/session"
+	"github.com/jonsabados/pointypoints/session/testutil"
+	"github.com/jonsabados/pointypoints/team"
+	"github.com/jonsabados/pointypoints/webhook"
+)
+
+type notification struct {
+	kind   notify.Kind
+	emails []string
+}
+
+func TestNewStarter(t *testing.T) {
+	deadline := time.Now().Add(time.Hour)
+	initiator := goauth.Principal{UserID: "123", Email: "bob@example.com"}
+	theTeam := &team.Team{
+		ID:                "t1",
+		DefaultDeck:       []string{"S", "M", "L"},
+		FacilitatorPoints: true,
+		Members: []team.Member{
+			{Email: "bob@example.com", Role: team.RoleFacilitator},
+			{Email: "sue@example.com", Role: team.RoleMember},
+		},
+	}
+
+	testCases := []struct {
+		desc                  string
+		team                  *team.Team
+		request               session.StartRequest
+		startErr              error
+		expectedStart         session.StartRequest
+		expectedErr           bool
+		expectedEvents        []webhook.EventType
+		expectedNotifications []notification
+	}{
+		{
+			desc:    "no team",
+			request: session.StartRequest{ConnectionID: "abc", TeamID: "sneaky", Facilitator: session.User{UserID: "f"}},
+			expectedStart: session.StartRequest{
+				ConnectionID: "abc",
+				Facilitator:  session.User{UserID: "f", SocketID: "abc"},
+			},
+			expectedEvents:        []webhook.EventType{webhook.SessionStarted},
+			expectedNotifications: []notification{},
+		},
+		{
+			desc:    "team",
+			team:    theTeam,
+			request: session.StartRequest{ConnectionID: "abc", TeamID: "sneaky", Facilitator: session.User{UserID: "f"}},
+			expectedStart: session.StartRequest{
+				ConnectionID:      "abc",
+				TeamID:            "t1",
+				Deck:              []string{"S", "M", "L"},
+				FacilitatorPoints: &theTeam.FacilitatorPoints,
+				Facilitator:       session.User{UserID: "f", SocketID: "abc"},
+			},
+			expectedEvents:        []webhook.EventType{webhook.SessionStarted},
+			expectedNotifications: []notification{{kind: notify.KindStarted, emails: []string{"sue@example.com"}}},
+		},
+		{
+			desc: "async team",
+			team: theTeam,
+			request: session.StartRequest{
+				ConnectionID: "abc",
+				Facilitator:  session.User{UserID: "f"},
+				Async:        &session.AsyncRequest{Deadline: deadline},
+			},
+			expectedStart: session.StartRequest{
+				ConnectionID:      "abc",
+				TeamID:            "t1",
+				Deck:              []string{"S", "M", "L"},
+				FacilitatorPoints: &theTeam.FacilitatorPoints,
+				Facilitator:       session.User{UserID: "f", SocketID: "abc"},
+				Async:             &session.AsyncRequest{Deadline: deadline},
+			},
+			expectedEvents:        []webhook.EventType{webhook.SessionStarted},
+			expectedNotifications: []notification{{kind: notify.KindInvite, emails: []string{"sue@example.com"}}},
+		},
+		{
+			desc:     "start fails",
+			team:     theTeam,
+			request:  session.StartRequest{ConnectionID: "abc", Facilitator: session.User{UserID: "f"}},
+			startErr: errors.New("whoops"),
+			expectedStart: session.StartRequest{
+				ConnectionID:      "abc",
+				TeamID:            "t1",
+				Deck:              []string{"S", "M", "L"},
+				FacilitatorPoints: &theTeam.FacilitatorPoints,
+				Facilitator:       session.User{UserID: "f", SocketID: "abc"},
+			},
+			expectedErr:           true,
+			expectedEvents:        []webhook.EventType{},
+			expectedNotifications: []notification{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			asserter := assert.New(t)
+			ctx := testutil.NewTestContext()
+
+			var started []session.StartRequest
+			startSession := session.Starter(func(ctx context.Context, p goauth.Principal, toStart session.StartRequest) (session.CompleteSessionView, error) {
+				asserter.Equal(initiator, p)
+				started = append(started, toStart)
+				if tc.startErr != nil {
+					return session.CompleteSessionView{}, tc.startErr
+				}
+				var d *time.Time
+				if toStart.Async != nil {
+					d = &toStart.Async.Deadline
+				}
+				return session.CompleteSessionView{SessionID: "s1", Deadline: d}, nil
+			})
+			events := make([]webhook.EventType, 0)
+			publish := webhook.Publisher(func(ctx context.Context, event webhook.Event) error {
+				events = append(events, event.Type)
+				return nil
+			})
+			notifications := make([]notification, 0)
+			sendNotifications := notify.Notifier(func(ctx context.Context, kind notify.Kind, sess session.CompleteSessionView, emails []string) error {
+				notifications = append(notifications, notification{kind: kind, emails: emails})
+				return nil
+			})
+
+			sess, err := launch.NewStarter(startSession, publish, sendNotifications)(ctx, initiator, tc.team, tc.request)
+			if tc.expectedErr {
+				asserter.Error(err)
+			} else {
+				asserter.NoError(err)
+				asserter.Equal("s1", sess.SessionID)
+			}
+			asserter.Equal([]session.StartRequest{tc.expectedStart}, started)
+			asserter.Equal(tc.expectedEvents, events)
+			asserter.Equal(tc.expectedNotifications, notifications)
+		})
+	}
+}
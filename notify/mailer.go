@@ -0,0 +1,80 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"net/smtp"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type Mailer interface {
+	Send(ctx context.Context, m Message) error
+}
+
+type sendMailFunc func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+
+type SMTPMailer struct {
+	addr     string
+	from     string
+	auth     smtp.Auth
+	sendMail sendMailFunc
+}
+
+// NewSMTPMailer sends plain text mail through the server at addr, auth may be nil for servers that don't need it
+func NewSMTPMailer(addr string, from string, auth smtp.Auth) *SMTPMailer {
+	return &SMTPMailer{
+		addr:     addr,
+		from:     from,
+		auth:     auth,
+		sendMail: smtp.SendMail,
+	}
+}
+
+func (s *SMTPMailer) Send(ctx context.Context, m Message) error {
+	err := s.sendMail(s.addr, s.auth, s.from, []string{m.To}, formatMessage(s.from, m))
+	return errors.Wrapf(err, "error sending mail to %s", m.To)
+}
+
+func formatMessage(from string, m Message) []byte {
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "From: %s\r\n", from)
+	fmt.Fprintf(buf, "To: %s\r\n", m.To)
+	// facilitator names end up in subjects, so they can't be assumed to be plain ascii
+	fmt.Fprintf(buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", m.Subject))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("\r\n")
+	buf.WriteString(m.Body)
+	return buf.Bytes()
+}
+
+// FakeMailer keeps messages in memory rather than sending them, for tests and running without a mail server
+type FakeMailer struct {
+	lock sync.Mutex
+	sent []Message
+}
+
+func (f *FakeMailer) Send(ctx context.Context, m Message) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.sent = append(f.sent, m)
+	return nil
+}
+
+func (f *FakeMailer) Sent() []Message {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	ret := make([]Message, len(f.sent))
+	copy(ret, f.sent)
+	return ret
+}
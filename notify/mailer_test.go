@@ -0,0 +1,47 @@
+package notify
+
+import (
+	"errors"
+	"net/smtp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jonsabados/pointypoints/session/testutil"
+)
+
+func Test_SMTPMailer_Send(t *testing.T) {
+	asserter := assert.New(t)
+
+	var sentAddr, sentFrom string
+	var sentTo []string
+	var sentMsg []byte
+	mailer := NewSMTPMailer("mail.example.com:587", "points@example.com", nil)
+	mailer.sendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		sentAddr, sentFrom, sentTo, sentMsg = addr, from, to, msg
+		return nil
+	}
+
+	err := mailer.Send(testutil.NewTestContext(), Message{To: "alice@example.com", Subject: "Zoë wants points", Body: "hi\n"})
+	asserter.NoError(err)
+	asserter.Equal("mail.example.com:587", sentAddr)
+	asserter.Equal("points@example.com", sentFrom)
+	asserter.Equal([]string{"alice@example.com"}, sentTo)
+	asserter.Equal("From: points@example.com\r\n"+
+		"To: alice@example.com\r\n"+
+		"Subject: =?utf-8?q?Zo=C3=AB_wants_points?=\r\n"+
+		"MIME-Version: 1.0\r\n"+
+		"Content-Type: text/plain; charset=utf-8\r\n"+
+		"\r\n"+
+		"hi\n", string(sentMsg))
+}
+
+func Test_SMTPMailer_Send_Error(t *testing.T) {
+	mailer := NewSMTPMailer("mail.example.com:587", "points@example.com", nil)
+	mailer.sendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		return errors.New("connection refused")
+	}
+
+	err := mailer.Send(testutil.NewTestContext(), Message{To: "alice@example.com"})
+	assert.EqualError(t, err, "error sending mail to alice@example.com: connection refused")
+}
@@ -0,0 +1,202 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+
+	"github.com/jonsabados/pointypoints/profile"
+	"github.com/jonsabados/pointypoints/session"
+	"github.com/jonsabados/pointypoints/team"
+)
+
+type Kind string
+
+const (
+	// KindStarted lets a team know a live session has started for them
+	KindStarted  = Kind("started")
+	KindInvite   = Kind("invite")
+	KindReminder = Kind("reminder")
+	KindResults  = Kind("results")
+)
+
+const deadlineFormat = "Mon Jan 2 15:04 MST"
+
+// Allowed reports if someone has opted in to the kind of email
+func (k Kind) Allowed(prefs profile.NotificationPreferences) bool {
+	switch k {
+	case KindStarted, KindInvite:
+		return prefs.Invites
+	case KindReminder:
+		return prefs.Reminders
+	case KindResults:
+		return prefs.Results
+	default:
+		return false
+	}
+}
+
+// Notifier emails the given people about a session. Only people with a profile get anything, and only if they have
+// opted in to that kind of email.
+type Notifier func(ctx context.Context, kind Kind, sess session.CompleteSessionView, emails []string) error
+
+func NewNotifier(mailer Mailer, fetchProfile profile.EmailFetcher, baseURL string) Notifier {
+	return func(ctx context.Context, kind Kind, sess session.CompleteSessionView, emails []string) error {
+		var sendErr error
+		for _, email := range dedupe(emails) {
+			p, err := fetchProfile(ctx, email)
+			if err != nil {
+				zerolog.Ctx(ctx).Error().Err(err).Str("sessionID", sess.SessionID).Msg("error fetching profile to notify")
+				sendErr = err
+				continue
+			}
+			if p == nil || p.Email == "" || !kind.Allowed(p.Notifications) {
+				continue
+			}
+
+			m := render(kind, sess, baseURL)
+			m.To = p.Email
+			err = mailer.Send(ctx, m)
+			if err != nil {
+				// keep going so one bad address doesn't keep everyone else from hearing about it
+				zerolog.Ctx(ctx).Error().Err(err).Str("sessionID", sess.SessionID).Str("kind", string(kind)).Msg("error sending notification")
+				sendErr = err
+			}
+		}
+		return errors.WithStack(sendErr)
+	}
+}
+
+// TeamEmails is everyone on a team other than the given email, which is normally whoever is doing the notifying
+func TeamEmails(t team.Team, except string) []string {
+	ret := make([]string, 0, len(t.Members))
+	for _, m := range t.Members {
+		if profile.NormalizeEmail(m.Email) != profile.NormalizeEmail(except) {
+			ret = append(ret, m.Email)
+		}
+	}
+	return ret
+}
+
+// AsyncParticipants is everyone with a stake in an async session, the team it was started for, if any, and anyone who
+// voted while signed in
+func AsyncParticipants(sess session.CompleteSessionView, t *team.Team) []string {
+	ret := make([]string, 0)
+	if t != nil {
+		ret = append(ret, TeamEmails(*t, "")...)
+	}
+	for _, v := range sess.AsyncVotes {
+		if v.Email != "" && v.Vote != nil {
+			ret = append(ret, v.Email)
+		}
+	}
+	return dedupe(ret)
+}
+
+// AwaitingVotes is the members of a team who have yet to vote on every story in an async session
+func AwaitingVotes(sess session.CompleteSessionView, t team.Team) []string {
+	voted := make(map[string]int)
+	for _, v := range sess.AsyncVotes {
+		if v.Email != "" && v.Vote != nil {
+			voted[profile.NormalizeEmail(v.Email)]++
+		}
+	}
+	ret := make([]string, 0)
+	for _, m := range t.Members {
+		if voted[profile.NormalizeEmail(m.Email)] < len(sess.Stories) {
+			ret = append(ret, m.Email)
+		}
+	}
+	return ret
+}
+
+func dedupe(emails []string) []string {
+	seen := make(map[string]bool, len(emails))
+	ret := make([]string, 0, len(emails))
+	for _, e := range emails {
+		normalized := profile.NormalizeEmail(e)
+		if normalized == "" || seen[normalized] {
+			continue
+		}
+		seen[normalized] = true
+		ret = append(ret, normalized)
+	}
+	return ret
+}
+
+func render(kind Kind, sess session.CompleteSessionView, baseURL string) Message {
+	facilitator := sess.Facilitator.Name
+	if sess.Facilitator.Handle != "" {
+		facilitator = sess.Facilitator.Handle
+	}
+	deadline := ""
+	if sess.Deadline != nil {
+		deadline = sess.Deadline.UTC().Format(deadlineFormat)
+	}
+	link := fmt.Sprintf("%s/session/%s/async", strings.TrimSuffix(baseURL, "/"), sess.SessionID)
+
+	body := new(strings.Builder)
+	var subject string
+	switch kind {
+	case KindStarted:
+		subject = fmt.Sprintf("%s has started a pointing session", facilitator)
+		fmt.Fprintf(body, "%s has started a pointing session for your team.\n\n", facilitator)
+		fmt.Fprintf(body, "Join in at %s/session/%s\n", strings.TrimSuffix(baseURL, "/"), sess.SessionID)
+	case KindInvite:
+		subject = fmt.Sprintf("%s has asked you to point %d stories", facilitator, len(sess.Stories))
+		fmt.Fprintf(body, "%s has asked you to point the following stories before %s:\n\n", facilitator, deadline)
+		for _, s := range sess.Stories {
+			fmt.Fprintf(body, "  %s: %s\n", s.Key, s.Title)
+		}
+		fmt.Fprintf(body, "\nVote whenever you get a chance at %s\n", link)
+	case KindReminder:
+		subject = fmt.Sprintf("Pointing closes %s", deadline)
+		fmt.Fprintf(body, "There are still stories waiting on your vote in the session %s is facilitating, voting closes %s.\n\n", facilitator, deadline)
+		fmt.Fprintf(body, "Vote at %s\n", link)
+	case KindResults:
+		subject = fmt.Sprintf("Pointing results from %s", facilitator)
+		fmt.Fprintf(body, "Voting has closed, here is how it went:\n\n")
+		for _, s := range session.ToAsyncView(sess, "").Stories {
+			fmt.Fprintf(body, "  %s: %s - %s\n", s.Key, s.Title, summarize(s))
+		}
+		fmt.Fprintf(body, "\nSee everyone's votes at %s\n", link)
+	}
+	return Message{
+		Subject: subject,
+		Body:    body.String(),
+	}
+}
+
+func summarize(s session.AsyncStory) string {
+	if s.Stats == nil || s.Stats.Votes == 0 {
+		return "no votes"
+	}
+	ret := fmt.Sprintf("%d votes", s.Stats.Votes)
+	if s.Stats.Average != nil {
+		ret = fmt.Sprintf("%s, average %.1f", ret, *s.Stats.Average)
+	}
+	if len(s.Stats.Counts) > 0 {
+		votes := make([]string, 0, len(s.Stats.Counts))
+		for v := range s.Stats.Counts {
+			votes = append(votes, v)
+		}
+		sort.Strings(votes)
+		counts := make([]string, len(votes))
+		for i, v := range votes {
+			counts[i] = fmt.Sprintf("%s x%d", v, s.Stats.Counts[v])
+		}
+		ret = fmt.Sprintf("%s (%s)", ret, strings.Join(counts, ", "))
+	}
+	if s.Stats.Consensus != nil {
+		if *s.Stats.Consensus {
+			ret += ", consensus reached"
+		} else {
+			ret += ", no consensus"
+		}
+	}
+	return ret
+}
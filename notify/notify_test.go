@@ -0,0 +1,199 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jonsabados/pointypoints/profile"
+	"github.com/jonsabados/pointypoints/session"
+	"github.com/jonsabados/pointypoints/session/testutil"
+	"github.com/jonsabados/pointypoints/team"
+)
+
+type failingMailer struct {
+	FakeMailer
+	failFor string
+}
+
+func (f *failingMailer) Send(ctx context.Context, m Message) error {
+	if m.To == f.failFor {
+		return errors.New("mailbox full")
+	}
+	return f.FakeMailer.Send(ctx, m)
+}
+
+func asyncSession() session.CompleteSessionView {
+	deadline := time.Date(2026, 10, 20, 17, 0, 0, 0, time.UTC)
+	return session.CompleteSessionView{
+		SessionID:   "s1",
+		Type:        session.SessionTypeEstimate,
+		Facilitator: session.User{UserID: "f", Name: "Fred", Handle: "Freddy"},
+		Deadline:    &deadline,
+		Stories:     []session.Story{{Key: "PP-1", Title: "Login"}, {Key: "PP-2", Title: "Logout"}},
+		AsyncVotes: []session.AsyncVote{
+			{StoryKey: "PP-1", UserID: "a", Email: "alice@example.com", Vote: aws.String("3")},
+			{StoryKey: "PP-2", UserID: "a", Email: "alice@example.com", Vote: aws.String("5")},
+			{StoryKey: "PP-1", UserID: "b", Email: "Bob@Example.com", Vote: aws.String("5")},
+			{StoryKey: "PP-2", UserID: "b", Email: "bob@example.com"},
+			{StoryKey: "PP-2", UserID: "c", Vote: aws.String("8")},
+		},
+	}
+}
+
+func Test_NewNotifier(t *testing.T) {
+	profiles := map[string]profile.Profile{
+		"alice@example.com": {UserID: "a", Email: "Alice@example.com", Notifications: profile.NotificationPreferences{Invites: true, Results: true}},
+		"bob@example.com":   {UserID: "b", Email: "bob@example.com", Notifications: profile.NotificationPreferences{Reminders: true}},
+		"carol@example.com": {UserID: "c", Email: "carol@example.com", Notifications: profile.NotificationPreferences{Invites: true, Reminders: true, Results: true}},
+	}
+	fetchProfile := profile.EmailFetcher(func(ctx context.Context, email string) (*profile.Profile, error) {
+		if email == "broken@example.com" {
+			return nil, errors.New("boom")
+		}
+		p, ok := profiles[email]
+		if !ok {
+			return nil, nil
+		}
+		return &p, nil
+	})
+
+	testCases := []struct {
+		desc          string
+		kind          Kind
+		emails        []string
+		failFor       string
+		expectedTo    []string
+		expectedError bool
+	}{
+		{
+			desc:       "invites go to those who want them",
+			kind:       KindInvite,
+			emails:     []string{"alice@example.com", "ALICE@example.com", "bob@example.com", "carol@example.com", "nobody@example.com"},
+			expectedTo: []string{"Alice@example.com", "carol@example.com"},
+		},
+		{
+			desc:       "reminders",
+			kind:       KindReminder,
+			emails:     []string{"alice@example.com", "bob@example.com"},
+			expectedTo: []string{"bob@example.com"},
+		},
+		{
+			desc:          "one failure doesn't stop the rest",
+			kind:          KindResults,
+			emails:        []string{"broken@example.com", "alice@example.com", "carol@example.com"},
+			failFor:       "Alice@example.com",
+			expectedTo:    []string{"carol@example.com"},
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			asserter := assert.New(t)
+			mailer := &failingMailer{failFor: tc.failFor}
+
+			err := NewNotifier(mailer, fetchProfile, "https://points.example.com/")(testutil.NewTestContext(), tc.kind, asyncSession(), tc.emails)
+			if tc.expectedError {
+				asserter.Error(err)
+			} else {
+				asserter.NoError(err)
+			}
+
+			to := make([]string, 0)
+			for _, m := range mailer.Sent() {
+				to = append(to, m.To)
+			}
+			asserter.Equal(tc.expectedTo, to)
+		})
+	}
+}
+
+func Test_render(t *testing.T) {
+	sess := asyncSession()
+
+	t.Run("started", func(t *testing.T) {
+		m := render(KindStarted, session.CompleteSessionView{SessionID: "s3", Facilitator: session.User{Name: "Fred"}}, "https://points.example.com/")
+		assert.Equal(t, Message{
+			Subject: "Fred has started a pointing session",
+			Body: "Fred has started a pointing session for your team.\n\n" +
+				"Join in at https://points.example.com/session/s3\n",
+		}, m)
+	})
+
+	t.Run("invite", func(t *testing.T) {
+		m := render(KindInvite, sess, "https://points.example.com/")
+		assert.Equal(t, Message{
+			Subject: "Freddy has asked you to point 2 stories",
+			Body: "Freddy has asked you to point the following stories before Tue Oct 20 17:00 UTC:\n\n" +
+				"  PP-1: Login\n" +
+				"  PP-2: Logout\n" +
+				"\nVote whenever you get a chance at https://points.example.com/session/s1/async\n",
+		}, m)
+	})
+
+	t.Run("reminder", func(t *testing.T) {
+		m := render(KindReminder, sess, "https://points.example.com")
+		assert.Equal(t, "Pointing closes Tue Oct 20 17:00 UTC", m.Subject)
+		assert.Contains(t, m.Body, "https://points.example.com/session/s1/async")
+	})
+
+	t.Run("results", func(t *testing.T) {
+		sess.VotesShown = true
+		m := render(KindResults, sess, "https://points.example.com")
+		assert.Equal(t, Message{
+			Subject: "Pointing results from Freddy",
+			Body: "Voting has closed, here is how it went:\n\n" +
+				"  PP-1: Login - 2 votes, average 4.0\n" +
+				"  PP-2: Logout - 2 votes, average 6.5\n" +
+				"\nSee everyone's votes at https://points.example.com/session/s1/async\n",
+		}, m)
+	})
+
+	t.Run("poll results", func(t *testing.T) {
+		poll := session.CompleteSessionView{
+			SessionID:   "s2",
+			Type:        session.SessionTypeThumbs,
+			VotesShown:  true,
+			Facilitator: session.User{Name: "Fred"},
+			Stories:     []session.Story{{Key: "1", Title: "Ship it?"}, {Key: "2", Title: "Lunch?"}},
+			AsyncVotes: []session.AsyncVote{
+				{StoryKey: "1", UserID: "a", Vote: aws.String("up")},
+				{StoryKey: "1", UserID: "b", Vote: aws.String("sideways")},
+			},
+		}
+		m := render(KindResults, poll, "https://points.example.com")
+		assert.Contains(t, m.Body, "  1: Ship it? - 2 votes (sideways x1, up x1), consensus reached\n")
+		assert.Contains(t, m.Body, "  2: Lunch? - no votes\n")
+	})
+}
+
+func Test_AsyncParticipants(t *testing.T) {
+	asserter := assert.New(t)
+	sess := asyncSession()
+
+	asserter.Equal([]string{"alice@example.com", "bob@example.com"}, AsyncParticipants(sess, nil))
+
+	squad := team.Team{Members: []team.Member{{Email: "carol@example.com"}, {Email: "alice@example.com"}}}
+	asserter.Equal([]string{"carol@example.com", "alice@example.com", "bob@example.com"}, AsyncParticipants(sess, &squad))
+}
+
+func Test_AwaitingVotes(t *testing.T) {
+	squad := team.Team{Members: []team.Member{
+		{Email: "alice@example.com"},
+		{Email: "bob@example.com"},
+		{Email: "carol@example.com"},
+	}}
+
+	assert.Equal(t, []string{"bob@example.com", "carol@example.com"}, AwaitingVotes(asyncSession(), squad))
+}
+
+func Test_TeamEmails(t *testing.T) {
+	squad := team.Team{Members: []team.Member{{Email: "alice@example.com"}, {Email: "bob@example.com"}}}
+
+	assert.Equal(t, []string{"bob@example.com"}, TeamEmails(squad, "Alice@Example.com"))
+}
@@ -3,6 +3,7 @@ package profile
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
@@ -14,6 +15,12 @@ const (
 	fieldEmail  = "Email"
 	fieldName   = "UserName" // Name is reserved
 	fieldHandle = "Handle"
+	// EmailLookup is the normalized email, which is what the email index is keyed on
+	fieldEmailLookup = "EmailLookup"
+
+	fieldNotifyInvites   = "NotifyInvites"
+	fieldNotifyReminders = "NotifyReminders"
+	fieldNotifyResults   = "NotifyResults"
 
 	fieldSessionStartCount = "SessionStartCount"
 	fieldSessionWatchCount = "SessionWatchCount"
@@ -21,17 +28,26 @@ const (
 	fieldVoteCount         = "VoteCount"
 )
 
+// NotificationPreferences are the emails a user has opted in to, everything is off until they say otherwise
+type NotificationPreferences struct {
+	Invites   bool `json:"invites"`
+	Reminders bool `json:"reminders"`
+	Results   bool `json:"results"`
+}
+
 type Profile struct {
-	UserID string
-	Email  string
-	Name   string
-	Handle *string
+	UserID        string
+	Email         string
+	Name          string
+	Handle        *string
+	Notifications NotificationPreferences
 }
 
 type UserView struct {
-	Email  string  `json:"email"`
-	Name   string  `json:"name"`
-	Handle *string `json:"handle"`
+	Email         string                  `json:"email"`
+	Name          string                  `json:"name"`
+	Handle        *string                 `json:"handle"`
+	Notifications NotificationPreferences `json:"notifications"`
 }
 
 type Fetcher func(ctx context.Context, userID string) (*Profile, error)
@@ -43,7 +59,7 @@ func NewFetcher(dynamo *dynamodb.DynamoDB, tableName string) Fetcher {
 			Key: map[string]*dynamodb.AttributeValue{
 				"UserID": {S: aws.String(userID)},
 			},
-			ProjectionExpression: aws.String(fmt.Sprintf("%s,%s,%s", fieldName, fieldEmail, fieldHandle)),
+			ProjectionExpression: aws.String(strings.Join(profileFields, ",")),
 		})
 
 		if err != nil {
@@ -54,20 +70,71 @@ func NewFetcher(dynamo *dynamodb.DynamoDB, tableName string) Fetcher {
 			return nil, nil
 		}
 
-		ret := &Profile{
-			UserID: userID,
-			Email:  *res.Item[fieldEmail].S,
-			Name:   *res.Item[fieldName].S,
+		return readProfile(res.Item), nil
+	}
+}
+
+// EmailFetcher finds a profile by email, for when all that is known about someone is their email, like team members
+type EmailFetcher func(ctx context.Context, email string) (*Profile, error)
+
+func NewEmailFetcher(dynamo *dynamodb.DynamoDB, tableName string, indexName string) EmailFetcher {
+	return func(ctx context.Context, email string) (*Profile, error) {
+		res, err := dynamo.QueryWithContext(ctx, &dynamodb.QueryInput{
+			TableName: aws.String(tableName),
+			IndexName: aws.String(indexName),
+			KeyConditions: map[string]*dynamodb.Condition{
+				fieldEmailLookup: {
+					ComparisonOperator: aws.String("EQ"),
+					AttributeValueList: []*dynamodb.AttributeValue{
+						{S: aws.String(NormalizeEmail(email))},
+					},
+				},
+			},
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "error querying profile by email")
 		}
 
-		if i, ok := res.Item[fieldHandle]; ok {
-			ret.Handle = i.S
+		if len(res.Items) == 0 {
+			return nil, nil
 		}
 
-		return ret, nil
+		return readProfile(res.Items[0]), nil
 	}
 }
 
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+var profileFields = []string{fieldUserID, fieldName, fieldEmail, fieldHandle, fieldNotifyInvites, fieldNotifyReminders, fieldNotifyResults}
+
+func readProfile(item map[string]*dynamodb.AttributeValue) *Profile {
+	ret := &Profile{
+		UserID: *item[fieldUserID].S,
+		Email:  *item[fieldEmail].S,
+		Name:   *item[fieldName].S,
+		Notifications: NotificationPreferences{
+			Invites:   readBool(item, fieldNotifyInvites),
+			Reminders: readBool(item, fieldNotifyReminders),
+			Results:   readBool(item, fieldNotifyResults),
+		},
+	}
+
+	if i, ok := item[fieldHandle]; ok {
+		ret.Handle = i.S
+	}
+
+	return ret
+}
+
+func readBool(item map[string]*dynamodb.AttributeValue, field string) bool {
+	if v, ok := item[field]; ok && v.BOOL != nil {
+		return *v.BOOL
+	}
+	return false
+}
+
 type Writer func(ctx context.Context, profile Profile) error
 
 func NewWriter(dynamo *dynamodb.DynamoDB, tableName string) Writer {
@@ -76,6 +143,11 @@ func NewWriter(dynamo *dynamodb.DynamoDB, tableName string) Writer {
 			fieldUserID: {S: aws.String(profile.UserID)},
 			fieldName:   {S: aws.String(profile.Name)},
 			fieldEmail:  {S: aws.String(profile.Email)},
+
+			fieldEmailLookup:     {S: aws.String(NormalizeEmail(profile.Email))},
+			fieldNotifyInvites:   {BOOL: aws.Bool(profile.Notifications.Invites)},
+			fieldNotifyReminders: {BOOL: aws.Bool(profile.Notifications.Reminders)},
+			fieldNotifyResults:   {BOOL: aws.Bool(profile.Notifications.Results)},
 		}
 
 		if profile.Handle != nil {
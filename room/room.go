@@ -0,0 +1,71 @@
+package room
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jonsabados/goauth"
+
+	"github.com/jonsabados/pointypoints/api"
+	"github.com/jonsabados/pointypoints/team"
+)
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+const (
+	minSlugLength = 3
+	maxSlugLength = 64
+)
+
+type CreateRequest struct {
+	Slug   string `json:"slug"`
+	Name   string `json:"name"`
+	TeamID string `json:"teamId,omitempty"`
+}
+
+// Room is a stable, human readable address that always points at the current session for whoever owns it
+type Room struct {
+	Slug string `json:"slug"`
+	Name string `json:"name"`
+	// OwnerID is whoever created the room, team rooms can be run by any of the team's facilitators
+	OwnerID   string    `json:"-"`
+	TeamID    string    `json:"teamId,omitempty"`
+	SessionID string    `json:"sessionId,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func Validate(r CreateRequest) []api.FieldValidationError {
+	ret := make([]api.FieldValidationError, 0)
+	if len(r.Slug) < minSlugLength || len(r.Slug) > maxSlugLength || !slugPattern.MatchString(r.Slug) {
+		ret = append(ret, api.FieldValidationError{
+			Field: "slug",
+			Error: "must be 3 to 64 lower case letters, numbers and single dashes",
+		})
+	}
+	if strings.TrimSpace(r.Name) == "" {
+		ret = append(ret, api.FieldValidationError{
+			Field: "name",
+			Error: "is required",
+		})
+	}
+	return ret
+}
+
+func NewRoom(creator goauth.Principal, r CreateRequest, now time.Time) Room {
+	return Room{
+		Slug:      r.Slug,
+		Name:      strings.TrimSpace(r.Name),
+		OwnerID:   creator.UserID,
+		TeamID:    r.TeamID,
+		CreatedAt: now.UTC().Truncate(time.Second),
+	}
+}
+
+// CanStart tells if someone is allowed to start the room's next session. The team should be provided for team rooms.
+func CanStart(r Room, t *team.Team, principal goauth.Principal) bool {
+	if r.TeamID != "" {
+		return t != nil && t.ID == r.TeamID && t.CanFacilitate(principal.Email)
+	}
+	return r.OwnerID == principal.UserID
+}
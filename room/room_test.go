@@ -0,0 +1,95 @@
+package room_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jonsabados/goauth"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jonsabados/pointypoints/api"
+	"github.com/jonsabados/pointypoints/room"
+	"github.com/jonsabados/pointypoints/team"
+)
+
+func TestValidate(t *testing.T) {
+	slugError := api.FieldValidationError{Field: "slug", Error: "must be 3 to 64 lower case letters, numbers and single dashes"}
+
+	testCases := []struct {
+		name     string
+		slug     string
+		roomName string
+		expected []api.FieldValidationError
+	}{
+		{"valid", "sprint-planning-2", "Sprint Planning", []api.FieldValidationError{}},
+		{"too short", "ab", "Sprint Planning", []api.FieldValidationError{slugError}},
+		{"too long", strings.Repeat("a", 65), "Sprint Planning", []api.FieldValidationError{slugError}},
+		{"upper case", "Sprint", "Sprint Planning", []api.FieldValidationError{slugError}},
+		{"double dash", "sprint--planning", "Sprint Planning", []api.FieldValidationError{slugError}},
+		{"trailing dash", "sprint-", "Sprint Planning", []api.FieldValidationError{slugError}},
+		{"spaces", "sprint planning", "Sprint Planning", []api.FieldValidationError{slugError}},
+		{"no name", "sprint", " ", []api.FieldValidationError{{Field: "name", Error: "is required"}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, room.Validate(room.CreateRequest{Slug: tc.slug, Name: tc.roomName}))
+		})
+	}
+}
+
+func TestNewRoom(t *testing.T) {
+	result := room.NewRoom(goauth.Principal{UserID: "123"}, room.CreateRequest{
+		Slug:   "planning",
+		Name:   " Planning ",
+		TeamID: "team1",
+	}, time.Date(2021, 3, 4, 5, 6, 7, 8, time.UTC))
+
+	assert.Equal(t, room.Room{
+		Slug:      "planning",
+		Name:      "Planning",
+		OwnerID:   "123",
+		TeamID:    "team1",
+		CreatedAt: time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC),
+	}, result)
+}
+
+func TestCanStart(t *testing.T) {
+	owner := goauth.Principal{UserID: "123", Email: "bob@example.com"}
+	facilitator := goauth.Principal{UserID: "456", Email: "sue@example.com"}
+	member := goauth.Principal{UserID: "789", Email: "joe@example.com"}
+
+	tm := &team.Team{
+		ID: "team1",
+		Members: []team.Member{
+			{Email: "sue@example.com", Role: team.RoleFacilitator},
+			{Email: "joe@example.com", Role: team.RoleMember},
+		},
+	}
+	personal := room.Room{OwnerID: "123"}
+	teamRoom := room.Room{OwnerID: "123", TeamID: "team1"}
+
+	testCases := []struct {
+		name      string
+		room      room.Room
+		team      *team.Team
+		principal goauth.Principal
+		expected  bool
+	}{
+		{"personal owner", personal, nil, owner, true},
+		{"personal someone else", personal, nil, facilitator, false},
+		{"team facilitator", teamRoom, tm, facilitator, true},
+		{"team member", teamRoom, tm, member, false},
+		// the creator of a team room is bound by the team roster like everybody else
+		{"team room creator no longer facilitator", teamRoom, tm, owner, false},
+		{"team missing", teamRoom, nil, facilitator, false},
+		{"wrong team", teamRoom, &team.Team{ID: "team2", Members: tm.Members}, facilitator, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, room.CanStart(tc.room, tc.team, tc.principal))
+		})
+	}
+}
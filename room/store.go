@@ -0,0 +1,102 @@
+package room
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/pkg/errors"
+)
+
+var ErrorSlugTaken = errors.New("room slug already taken")
+
+type DynamoClient interface {
+	GetItemWithContext(ctx aws.Context, input *dynamodb.GetItemInput, opts ...request.Option) (*dynamodb.GetItemOutput, error)
+	PutItemWithContext(ctx aws.Context, input *dynamodb.PutItemInput, opts ...request.Option) (*dynamodb.PutItemOutput, error)
+	UpdateItemWithContext(ctx aws.Context, input *dynamodb.UpdateItemInput, opts ...request.Option) (*dynamodb.UpdateItemOutput, error)
+}
+
+// Creator saves a new room, returning ErrorSlugTaken if somebody already has it
+type Creator func(ctx context.Context, r Room) error
+
+func NewCreator(dynamo DynamoClient, tableName string) Creator {
+	return func(ctx context.Context, r Room) error {
+		item := map[string]*dynamodb.AttributeValue{
+			"Slug":      {S: aws.String(r.Slug)},
+			"RoomName":  {S: aws.String(r.Name)},
+			"OwnerID":   {S: aws.String(r.OwnerID)},
+			"CreatedAt": {N: aws.String(strconv.FormatInt(r.CreatedAt.Unix(), 10))},
+		}
+		if r.TeamID != "" {
+			item["TeamID"] = &dynamodb.AttributeValue{S: aws.String(r.TeamID)}
+		}
+		_, err := dynamo.PutItemWithContext(ctx, &dynamodb.PutItemInput{
+			TableName:           aws.String(tableName),
+			Item:                item,
+			ConditionExpression: aws.String("attribute_not_exists(Slug)"),
+		})
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+			return ErrorSlugTaken
+		}
+		return errors.Wrap(err, "error saving room")
+	}
+}
+
+type Loader func(ctx context.Context, slug string) (*Room, error)
+
+func NewLoader(dynamo DynamoClient, tableName string) Loader {
+	return func(ctx context.Context, slug string) (*Room, error) {
+		res, err := dynamo.GetItemWithContext(ctx, &dynamodb.GetItemInput{
+			TableName: aws.String(tableName),
+			Key: map[string]*dynamodb.AttributeValue{
+				"Slug": {S: aws.String(slug)},
+			},
+			ConsistentRead: aws.Bool(true),
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "error reading room")
+		}
+		if res.Item == nil {
+			return nil, nil
+		}
+		createdAt, _ := strconv.ParseInt(*res.Item["CreatedAt"].N, 10, 64)
+		ret := &Room{
+			Slug:      slug,
+			Name:      *res.Item["RoomName"].S,
+			OwnerID:   *res.Item["OwnerID"].S,
+			CreatedAt: time.Unix(createdAt, 0).UTC(),
+		}
+		if teamID, ok := res.Item["TeamID"]; ok {
+			ret.TeamID = *teamID.S
+		}
+		if sessionID, ok := res.Item["SessionID"]; ok {
+			ret.SessionID = *sessionID.S
+		}
+		return ret, nil
+	}
+}
+
+// SessionSwitcher points a room at a new session
+type SessionSwitcher func(ctx context.Context, slug string, sessionID string) error
+
+func NewSessionSwitcher(dynamo DynamoClient, tableName string) SessionSwitcher {
+	return func(ctx context.Context, slug string, sessionID string) error {
+		_, err := dynamo.UpdateItemWithContext(ctx, &dynamodb.UpdateItemInput{
+			TableName: aws.String(tableName),
+			Key: map[string]*dynamodb.AttributeValue{
+				"Slug": {S: aws.String(slug)},
+			},
+			UpdateExpression: aws.String("SET SessionID = :sessionID"),
+			// updating a missing key would otherwise create a room with nothing but a session
+			ConditionExpression: aws.String("attribute_exists(Slug)"),
+			ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+				":sessionID": {S: aws.String(sessionID)},
+			},
+		})
+		return errors.Wrap(err, "error switching room session")
+	}
+}
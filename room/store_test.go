@@ -0,0 +1,101 @@
+package room_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/jonsabados/pointypoints/room"
+	"github.com/jonsabados/pointypoints/session/testutil"
+)
+
+var emptyOpts []request.Option
+
+func TestRoom_RoundTrip(t *testing.T) {
+	asserter := assert.New(t)
+
+	ctx := testutil.NewTestContext()
+	dynamo := &testutil.MockDynamoClient{}
+	tableName := "rooms"
+
+	toCreate := room.Room{
+		Slug:      "planning",
+		Name:      "Planning",
+		OwnerID:   "123",
+		TeamID:    "team1",
+		CreatedAt: time.Unix(5000, 0).UTC(),
+	}
+
+	var saved map[string]*dynamodb.AttributeValue
+	dynamo.On("PutItemWithContext", ctx, mock.Anything, emptyOpts).Run(func(args mock.Arguments) {
+		input := args.Get(1).(*dynamodb.PutItemInput)
+		asserter.Equal(tableName, *input.TableName)
+		asserter.Equal("attribute_not_exists(Slug)", *input.ConditionExpression)
+		saved = input.Item
+	}).Return(&dynamodb.PutItemOutput{}, nil)
+
+	err := room.NewCreator(dynamo, tableName)(ctx, toCreate)
+	asserter.NoError(err)
+
+	saved["SessionID"] = &dynamodb.AttributeValue{S: aws.String("abcdefg")}
+	dynamo.On("GetItemWithContext", ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(tableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"Slug": {S: aws.String("planning")},
+		},
+		ConsistentRead: aws.Bool(true),
+	}, emptyOpts).Return(&dynamodb.GetItemOutput{Item: saved}, nil)
+
+	loaded, err := room.NewLoader(dynamo, tableName)(ctx, "planning")
+	asserter.NoError(err)
+	expected := toCreate
+	expected.SessionID = "abcdefg"
+	asserter.Equal(&expected, loaded)
+}
+
+func TestNewCreator_SlugTaken(t *testing.T) {
+	ctx := testutil.NewTestContext()
+	dynamo := &testutil.MockDynamoClient{}
+	dynamo.On("PutItemWithContext", ctx, mock.Anything, emptyOpts).Return(nil, awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "nope", nil))
+
+	err := room.NewCreator(dynamo, "rooms")(ctx, room.Room{Slug: "planning"})
+	assert.Equal(t, room.ErrorSlugTaken, err)
+}
+
+func TestNewLoader_NotFound(t *testing.T) {
+	asserter := assert.New(t)
+
+	ctx := testutil.NewTestContext()
+	dynamo := &testutil.MockDynamoClient{}
+	dynamo.On("GetItemWithContext", ctx, mock.Anything, emptyOpts).Return(&dynamodb.GetItemOutput{}, nil)
+
+	loaded, err := room.NewLoader(dynamo, "rooms")(ctx, "planning")
+	asserter.NoError(err)
+	asserter.Nil(loaded)
+}
+
+func TestNewSessionSwitcher(t *testing.T) {
+	ctx := testutil.NewTestContext()
+	dynamo := &testutil.MockDynamoClient{}
+	dynamo.On("UpdateItemWithContext", ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String("rooms"),
+		Key: map[string]*dynamodb.AttributeValue{
+			"Slug": {S: aws.String("planning")},
+		},
+		UpdateExpression:    aws.String("SET SessionID = :sessionID"),
+		ConditionExpression: aws.String("attribute_exists(Slug)"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":sessionID": {S: aws.String("abcdefg")},
+		},
+	}, emptyOpts).Return(&dynamodb.UpdateItemOutput{}, nil)
+
+	err := room.NewSessionSwitcher(dynamo, "rooms")(ctx, "planning", "abcdefg")
+	assert.NoError(t, err)
+	dynamo.AssertExpectations(t)
+}
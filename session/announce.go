@@ -0,0 +1,131 @@
+package session
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+
+	"github.com/jonsabados/pointypoints/api"
+)
+
+const MaxAnnouncementLen = 280
+
+var ErrorInvalidAnnouncement = errors.New("invalid announcement")
+
+type AnnouncementRequest struct {
+	Text string `json:"text"`
+	// Banner keeps the announcement on display in the session until it is cleared or replaced
+	Banner bool `json:"banner"`
+}
+
+type Announcement struct {
+	SessionID string    `json:"sessionId"`
+	Text      string    `json:"text"`
+	SentAt    time.Time `json:"sentAt"`
+}
+
+// Announcer sends an announcement from the facilitator to everyone in the session, watchers included
+type Announcer func(ctx context.Context, sess CompleteSessionView, toSend AnnouncementRequest) (Announcement, error)
+
+func NewAnnouncer(dynamo DynamoClient, tableName string, dispatchMessage api.MessageDispatcher, notifyObservers ChangeNotifier) Announcer {
+	return func(ctx context.Context, sess CompleteSessionView, toSend AnnouncementRequest) (Announcement, error) {
+		text := strings.TrimSpace(toSend.Text)
+		if text == "" || utf8.RuneCountInString(text) > MaxAnnouncementLen {
+			return Announcement{}, ErrorInvalidAnnouncement
+		}
+		ret := Announcement{
+			SessionID: sess.SessionID,
+			Text:      text,
+			SentAt:    time.Now(),
+		}
+
+		if toSend.Banner {
+			_, err := dynamo.UpdateItemWithContext(ctx, &dynamodb.UpdateItemInput{
+				TableName: aws.String(tableName),
+				Key: map[string]*dynamodb.AttributeValue{
+					"SessionID": {S: aws.String(sess.SessionID)},
+					"RangeKey":  {S: aws.String(sessionRecordRangeKeyValue)},
+				},
+				UpdateExpression:    aws.String("SET BannerText = :text, BannerSentAt = :sentAt"),
+				ConditionExpression: aws.String("attribute_exists(SessionID)"),
+				ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+					":text":   {S: aws.String(ret.Text)},
+					":sentAt": {N: aws.String(strconv.FormatInt(ret.SentAt.Unix(), 10))},
+				},
+			})
+			if err != nil {
+				return Announcement{}, errors.WithStack(err)
+			}
+		}
+
+		err := broadcast(ctx, dynamo, tableName, dispatchMessage, sess.SessionID, api.Message{
+			Type: api.Announcement,
+			Body: ret,
+		})
+		if err != nil {
+			return Announcement{}, err
+		}
+
+		if toSend.Banner {
+			// the announcement itself is out, the banner will show up with the next update if this fails
+			sess.Banner = &ret
+			err = notifyObservers(ctx, sess)
+			if err != nil {
+				zerolog.Ctx(ctx).Warn().Err(err).Str("sessionID", sess.SessionID).Msg("error notifying observers of banner")
+			}
+		}
+		return ret, nil
+	}
+}
+
+type BannerClearer func(ctx context.Context, sess CompleteSessionView) error
+
+func NewBannerClearer(dynamo DynamoClient, tableName string, notifyObservers ChangeNotifier) BannerClearer {
+	return func(ctx context.Context, sess CompleteSessionView) error {
+		_, err := dynamo.UpdateItemWithContext(ctx, &dynamodb.UpdateItemInput{
+			TableName: aws.String(tableName),
+			Key: map[string]*dynamodb.AttributeValue{
+				"SessionID": {S: aws.String(sess.SessionID)},
+				"RangeKey":  {S: aws.String(sessionRecordRangeKeyValue)},
+			},
+			UpdateExpression:    aws.String("REMOVE BannerText, BannerSentAt"),
+			ConditionExpression: aws.String("attribute_exists(SessionID)"),
+		})
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		sess.Banner = nil
+		return errors.WithStack(notifyObservers(ctx, sess))
+	}
+}
+
+func convertBanner(s CompleteSessionView, item map[string]*dynamodb.AttributeValue) {
+	if s.Banner == nil {
+		return
+	}
+	item["BannerText"] = &dynamodb.AttributeValue{S: aws.String(s.Banner.Text)}
+	item["BannerSentAt"] = &dynamodb.AttributeValue{N: aws.String(strconv.FormatInt(s.Banner.SentAt.Unix(), 10))}
+}
+
+func readBanner(item map[string]*dynamodb.AttributeValue) *Announcement {
+	text, ok := item["BannerText"]
+	if !ok {
+		return nil
+	}
+	ret := &Announcement{
+		SessionID: *item["SessionID"].S,
+		Text:      *text.S,
+	}
+	if sentAt, ok := item["BannerSentAt"]; ok {
+		epoch, _ := strconv.ParseInt(*sentAt.N, 10, 64)
+		ret.SentAt = time.Unix(epoch, 0)
+	}
+	return ret
+}
@@ -0,0 +1,138 @@
+package session
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/jonsabados/pointypoints/api"
+	"github.com/jonsabados/pointypoints/session/testutil"
+)
+
+func Test_NewAnnouncer(t *testing.T) {
+	sessionID := "abcdefg"
+	tableName := "sessions"
+
+	testCases := []struct {
+		desc         string
+		request      AnnouncementRequest
+		expectedErr  error
+		expectedText string
+		expectBanner bool
+	}{
+		{
+			desc:         "one off",
+			request:      AnnouncementRequest{Text: " 5 minute break "},
+			expectedText: "5 minute break",
+		},
+		{
+			desc:         "banner",
+			request:      AnnouncementRequest{Text: "discussing PROJ-123", Banner: true},
+			expectedText: "discussing PROJ-123",
+			expectBanner: true,
+		},
+		{
+			desc:        "empty",
+			request:     AnnouncementRequest{Text: "  ", Banner: true},
+			expectedErr: ErrorInvalidAnnouncement,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			asserter := assert.New(t)
+
+			ctx := testutil.NewTestContext()
+			dynamo := &testutil.MockDynamoClient{}
+			dynamo.On("UpdateItemWithContext", ctx, mock.Anything, emptyOpts).Run(func(args mock.Arguments) {
+				in := args.Get(1).(*dynamodb.UpdateItemInput)
+				asserter.Equal("session", *in.Key["RangeKey"].S)
+				asserter.Equal(tc.expectedText, *in.ExpressionAttributeValues[":text"].S)
+			}).Return(&dynamodb.UpdateItemOutput{}, nil)
+			dynamo.On("QueryWithContext", ctx, mock.Anything, emptyOpts).Return(&dynamodb.QueryOutput{
+				Items: []map[string]*dynamodb.AttributeValue{
+					{"RangeKey": {S: aws.String("user:as")}, "SocketID": {S: aws.String("as")}},
+					{"RangeKey": {S: aws.String("watcher:ws")}, "SocketID": {S: aws.String("ws")}},
+				},
+			}, nil)
+
+			var sent []string
+			dispatcher := api.MessageDispatcher(func(ctx context.Context, connectionID string, message api.Message) error {
+				asserter.Equal(api.Announcement, message.Type)
+				sent = append(sent, connectionID)
+				return nil
+			})
+			var notified *CompleteSessionView
+			notifier := ChangeNotifier(func(ctx context.Context, updated CompleteSessionView) error {
+				notified = &updated
+				return nil
+			})
+
+			res, err := NewAnnouncer(dynamo, tableName, dispatcher, notifier)(ctx, CompleteSessionView{SessionID: sessionID}, tc.request)
+			if tc.expectedErr != nil {
+				asserter.Equal(tc.expectedErr, err)
+				asserter.Empty(sent)
+				dynamo.AssertNotCalled(t, "UpdateItemWithContext", mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			asserter.NoError(err)
+			asserter.Equal(sessionID, res.SessionID)
+			asserter.Equal(tc.expectedText, res.Text)
+			asserter.Equal([]string{"as", "ws"}, sent)
+			if tc.expectBanner {
+				dynamo.AssertNumberOfCalls(t, "UpdateItemWithContext", 1)
+				if asserter.NotNil(notified) {
+					asserter.Equal(&res, notified.Banner)
+				}
+			} else {
+				dynamo.AssertNotCalled(t, "UpdateItemWithContext", mock.Anything, mock.Anything, mock.Anything)
+				asserter.Nil(notified)
+			}
+		})
+	}
+}
+
+func Test_NewBannerClearer(t *testing.T) {
+	asserter := assert.New(t)
+
+	ctx := testutil.NewTestContext()
+	dynamo := &testutil.MockDynamoClient{}
+	dynamo.On("UpdateItemWithContext", ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String("sessions"),
+		Key: map[string]*dynamodb.AttributeValue{
+			"SessionID": {S: aws.String("abcdefg")},
+			"RangeKey":  {S: aws.String("session")},
+		},
+		UpdateExpression:    aws.String("REMOVE BannerText, BannerSentAt"),
+		ConditionExpression: aws.String("attribute_exists(SessionID)"),
+	}, emptyOpts).Return(&dynamodb.UpdateItemOutput{}, nil)
+
+	var notified *CompleteSessionView
+	notifier := ChangeNotifier(func(ctx context.Context, updated CompleteSessionView) error {
+		notified = &updated
+		return nil
+	})
+
+	err := NewBannerClearer(dynamo, "sessions", notifier)(ctx, CompleteSessionView{SessionID: "abcdefg", Banner: &Announcement{Text: "old"}})
+	asserter.NoError(err)
+	if asserter.NotNil(notified) {
+		asserter.Nil(notified.Banner)
+	}
+	dynamo.AssertExpectations(t)
+}
+
+func Test_convertBanner(t *testing.T) {
+	asserter := assert.New(t)
+
+	banner := &Announcement{SessionID: "abcdefg", Text: "break time", SentAt: time.Unix(1000, 0)}
+	item := convertSession(CompleteSessionView{SessionID: "abcdefg", Banner: banner}, &dynamodb.AttributeValue{N: aws.String("1")})
+	asserter.Equal(banner, readBanner(item))
+
+	item = convertSession(CompleteSessionView{SessionID: "abcdefg"}, &dynamodb.AttributeValue{N: aws.String("1")})
+	asserter.Nil(readBanner(item))
+}
@@ -0,0 +1,76 @@
+package session
+
+import (
+	"math/rand"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+// ToFacilitatorView returns the view of the session that should be sent to the facilitator, which is the complete view
+// unless the facilitator has opted in to anonymous reveals as well.
+func ToFacilitatorView(s CompleteSessionView) CompleteSessionView {
+	s.Stats = RevealStats(s)
+	// async votes are hidden from everyone until the deadline, the facilitator included
+	if s.Deadline != nil && !s.VotesShown {
+		s.AsyncVotes = hideAsyncVotes(s.AsyncVotes)
+	}
+	if !s.AnonymousReveal || !s.AnonymizeFacilitatorView {
+		return s
+	}
+	if s.VotesShown {
+		s.RevealedVotes = shuffledVotes(s)
+	}
+	participants := make([]User, len(s.Participants))
+	for i, u := range s.Participants {
+		u.CurrentVote = nil
+		u.Confidence = ""
+		u.Rationale = ""
+		participants[i] = u
+	}
+	s.Participants = participants
+	// earlier rounds would otherwise say who voted what
+	history := make([]RoundResult, len(s.RoundHistory))
+	for i, r := range s.RoundHistory {
+		history[i] = anonymousRound(r)
+	}
+	s.RoundHistory = history
+	// hidden async votes only say who has voted, same as the participant list
+	if s.VotesShown {
+		s.AsyncVotes = anonymousAsyncVotes(s.AsyncVotes)
+	}
+	return s
+}
+
+func shuffledVotes(s CompleteSessionView) []string {
+	ret := make([]string, 0, len(s.Participants)+1)
+	if s.FacilitatorPoints && s.Facilitator.CurrentVote != nil {
+		ret = append(ret, *s.Facilitator.CurrentVote)
+	}
+	for _, u := range s.Participants {
+		if u.CurrentVote != nil {
+			ret = append(ret, *u.CurrentVote)
+		}
+	}
+	// keeping things in participant order would make it trivial to work out who voted for what
+	rand.Shuffle(len(ret), func(i, j int) {
+		ret[i], ret[j] = ret[j], ret[i]
+	})
+	return ret
+}
+
+// anonymousAsyncVotes strips out who cast each async vote, keeping what was voted on each story
+func anonymousAsyncVotes(votes []AsyncVote) []AsyncVote {
+	ret := make([]AsyncVote, len(votes))
+	for i, v := range votes {
+		ret[i] = AsyncVote{StoryKey: v.StoryKey, Vote: v.Vote, Confidence: v.Confidence, Rationale: v.Rationale}
+	}
+	// as with round history, ordering could give away who voted what
+	sort.SliceStable(ret, func(i, j int) bool {
+		if ret[i].StoryKey != ret[j].StoryKey {
+			return ret[i].StoryKey < ret[j].StoryKey
+		}
+		return aws.StringValue(ret[i].Vote) < aws.StringValue(ret[j].Vote)
+	})
+	return ret
+}
@@ -0,0 +1,147 @@
+package session
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+)
+
+func anonymousTestSession(votesShown bool, anonymizeFacilitator bool) CompleteSessionView {
+	return CompleteSessionView{
+		SessionID:                "abcdefg",
+		VotesShown:               votesShown,
+		FacilitatorSessionKey:    "bobsuruncle",
+		FacilitatorPoints:        true,
+		AnonymousReveal:          true,
+		AnonymizeFacilitatorView: anonymizeFacilitator,
+		Facilitator: User{
+			UserID:      "f",
+			Name:        "Fred",
+			CurrentVote: aws.String("3"),
+			HasVoted:    true,
+			SocketID:    "facilitator",
+		},
+		Participants: []User{
+			{UserID: "a", Name: "A", CurrentVote: aws.String("1"), Confidence: ConfidenceLow, HasVoted: true, SocketID: "aaaa"},
+			{UserID: "b", Name: "B", CurrentVote: aws.String("2"), Confidence: ConfidenceHigh, Rationale: "b's reasons", HasVoted: true, SocketID: "bbbb"},
+			{UserID: "c", Name: "C", SocketID: "cccc"},
+		},
+	}
+}
+
+func Test_ToParticipantView_AnonymousRevealed(t *testing.T) {
+	asserter := assert.New(t)
+
+	res := ToParticipantView(anonymousTestSession(true, false), "aaaa")
+
+	asserter.True(res.AnonymousReveal)
+	asserter.ElementsMatch([]string{"1", "2", "3"}, res.RevealedVotes)
+	asserter.Nil(res.Facilitator.CurrentVote)
+	asserter.True(res.Facilitator.HasVoted)
+	asserter.Equal(aws.String("1"), res.Participants[0].CurrentVote, "participants should still see their own vote")
+	asserter.Nil(res.Participants[1].CurrentVote)
+	asserter.Empty(res.Participants[1].Confidence)
+	asserter.Empty(res.Participants[1].Rationale)
+	asserter.True(res.Participants[1].HasVoted)
+	asserter.Nil(res.Participants[2].CurrentVote)
+	asserter.False(res.Participants[2].HasVoted)
+}
+
+func Test_ToParticipantView_AnonymousHidden(t *testing.T) {
+	asserter := assert.New(t)
+
+	res := ToParticipantView(anonymousTestSession(false, false), "aaaa")
+
+	asserter.Nil(res.RevealedVotes)
+	asserter.Equal(aws.String("1"), res.Participants[0].CurrentVote)
+	asserter.Nil(res.Participants[1].CurrentVote)
+}
+
+func Test_ToFacilitatorView(t *testing.T) {
+	t.Run("attributed unless opted in", func(t *testing.T) {
+		asserter := assert.New(t)
+
+		input := anonymousTestSession(true, false)
+		expected := input
+		expected.Stats = RevealStats(input)
+		asserter.Equal(expected, ToFacilitatorView(input))
+	})
+
+	t.Run("anonymized once revealed", func(t *testing.T) {
+		asserter := assert.New(t)
+
+		input := anonymousTestSession(true, true)
+		res := ToFacilitatorView(input)
+
+		asserter.ElementsMatch([]string{"1", "2", "3"}, res.RevealedVotes)
+		asserter.Equal(aws.String("3"), res.Facilitator.CurrentVote)
+		for _, p := range res.Participants {
+			asserter.Nil(p.CurrentVote)
+			asserter.Empty(p.Confidence)
+			asserter.Empty(p.Rationale)
+			asserter.NotEmpty(p.SocketID)
+		}
+		asserter.Equal(aws.String("1"), input.Participants[0].CurrentVote, "input should not be modified")
+	})
+
+	t.Run("history anonymized", func(t *testing.T) {
+		asserter := assert.New(t)
+
+		input := anonymousTestSession(false, true)
+		input.RoundHistory = []RoundResult{
+			{Round: 1, Votes: []RoundVote{
+				{UserID: "b", Name: "B", Handle: "bee", Vote: "5", Rationale: "b's reasons"},
+				{UserID: "a", Name: "A", Vote: "3"},
+			}},
+		}
+		res := ToFacilitatorView(input)
+
+		asserter.Equal([]RoundResult{
+			{Round: 1, Votes: []RoundVote{
+				{Vote: "3"},
+				{Vote: "5", Rationale: "b's reasons"},
+			}},
+		}, res.RoundHistory)
+		asserter.Equal("B", input.RoundHistory[0].Votes[0].Name, "input should not be modified")
+	})
+
+	t.Run("async votes anonymized once revealed", func(t *testing.T) {
+		asserter := assert.New(t)
+
+		deadline := time.Unix(1000, 0)
+		input := anonymousTestSession(true, true)
+		input.Deadline = &deadline
+		input.AsyncVotes = []AsyncVote{
+			{StoryKey: "2", UserID: "a", Name: "A", Vote: aws.String("8")},
+			{StoryKey: "1", UserID: "b", Name: "B", Handle: "bee", Email: "b@example.com", Vote: aws.String("5"), Rationale: "b's reasons"},
+			{StoryKey: "1", UserID: "a", Name: "A", Vote: aws.String("3"), Confidence: ConfidenceLow, VotedAt: deadline},
+		}
+		res := ToFacilitatorView(input)
+
+		asserter.Equal([]AsyncVote{
+			{StoryKey: "1", Vote: aws.String("3"), Confidence: ConfidenceLow},
+			{StoryKey: "1", Vote: aws.String("5"), Rationale: "b's reasons"},
+			{StoryKey: "2", Vote: aws.String("8")},
+		}, res.AsyncVotes)
+		asserter.Equal("a", input.AsyncVotes[0].UserID, "input should not be modified")
+
+		input.VotesShown = false
+		asserter.Equal([]AsyncVote{
+			{StoryKey: "2", UserID: "a", Name: "A"},
+			{StoryKey: "1", UserID: "b", Name: "B", Handle: "bee", Email: "b@example.com"},
+			{StoryKey: "1", UserID: "a", Name: "A", VotedAt: deadline},
+		}, ToFacilitatorView(input).AsyncVotes, "who has voted is fine to show before the reveal")
+	})
+
+	t.Run("anonymized before reveal", func(t *testing.T) {
+		asserter := assert.New(t)
+
+		res := ToFacilitatorView(anonymousTestSession(false, true))
+
+		asserter.Nil(res.RevealedVotes)
+		asserter.True(res.Participants[0].HasVoted)
+		asserter.Nil(res.Participants[0].CurrentVote)
+	})
+}
@@ -0,0 +1,434 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/jonsabados/goauth"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+
+	"github.com/jonsabados/pointypoints/profile"
+)
+
+const asyncVoteRecordRangeKeyPrefix = "asyncvote:"
+
+// anonymous voters pick their own ids, prefixing them keeps those ids from ever matching a signed in user
+const anonymousVoterPrefix = "anonymous:"
+
+// deadlines get their own shard in the schedule index so they don't get mixed up with round timers
+const scheduleShardAsyncDeadline = "asyncDeadline"
+
+const (
+	MaxAsyncStories = 100
+	// AsyncResultsRetention is how long an async session sticks around after its deadline so people can see the results
+	AsyncResultsRetention = time.Hour * 24 * 7
+)
+
+var ErrorVotingClosed = errors.New("voting has closed")
+
+type AsyncRequest struct {
+	Deadline time.Time `json:"deadline"`
+	Stories  []Story   `json:"stories"`
+}
+
+type AsyncVoteRequest struct {
+	VoteRequest
+	UserID string `json:"userId"`
+	Name   string `json:"name,omitempty"`
+	Handle string `json:"handle,omitempty"`
+}
+
+type AsyncVote struct {
+	StoryKey string `json:"storyKey"`
+	UserID   string `json:"userId"`
+	Name     string `json:"name,omitempty"`
+	Handle   string `json:"handle,omitempty"`
+	// Email is who to send results to, signed in voters only and never handed out
+	Email      string     `json:"-"`
+	Vote       *string    `json:"vote,omitempty"`
+	Confidence Confidence `json:"confidence,omitempty"`
+	Rationale  string     `json:"rationale,omitempty"`
+	VotedAt    time.Time  `json:"votedAt"`
+}
+
+// AsyncVoterID is who an async vote belongs to. Signed in voters are always themselves, anyone else gets the id they
+// asked for, kept apart from real user ids so nobody can vote as someone who is signed in.
+func AsyncVoterID(principal goauth.Principal, requested string) string {
+	if principal.UserID != "" {
+		return principal.UserID
+	}
+	if requested == "" {
+		return ""
+	}
+	return anonymousVoterPrefix + requested
+}
+
+type AsyncStory struct {
+	Story
+	VoteCount int         `json:"voteCount"`
+	MyVote    *AsyncVote  `json:"myVote,omitempty"`
+	Votes     []AsyncVote `json:"votes,omitempty"`
+	Stats     *VoteStats  `json:"stats,omitempty"`
+}
+
+// AsyncSessionView is what participants of an async session see, they aren't connected so it is fetched rather than pushed
+type AsyncSessionView struct {
+	SessionID   string       `json:"sessionId"`
+	Type        SessionType  `json:"type"`
+	Deadline    time.Time    `json:"deadline"`
+	VotesShown  bool         `json:"votesShown"`
+	Facilitator User         `json:"facilitator"`
+	Deck        []string     `json:"deck"`
+	Stories     []AsyncStory `json:"stories"`
+}
+
+// ValidateAsync checks a request to start an async session, handing back anything wrong with it. Stories without keys
+// are given one based on their position.
+func ValidateAsync(r *AsyncRequest, now time.Time) []string {
+	ret := make([]string, 0)
+	if !r.Deadline.After(now) {
+		ret = append(ret, "deadline must be in the future")
+	}
+	if len(r.Stories) == 0 {
+		ret = append(ret, "at least one story is required")
+	}
+	if len(r.Stories) > MaxAsyncStories {
+		ret = append(ret, fmt.Sprintf("at most %d stories may be pointed asynchronously", MaxAsyncStories))
+	}
+	keys := make(map[string]bool, len(r.Stories))
+	for i := range r.Stories {
+		r.Stories[i].Title = strings.TrimSpace(r.Stories[i].Title)
+		if r.Stories[i].Title == "" {
+			ret = append(ret, fmt.Sprintf("story %d needs a title", i+1))
+		}
+		if r.Stories[i].Key == "" {
+			r.Stories[i].Key = strconv.Itoa(i + 1)
+		}
+		if keys[r.Stories[i].Key] {
+			ret = append(ret, fmt.Sprintf("story key %s is used more than once", r.Stories[i].Key))
+		}
+		keys[r.Stories[i].Key] = true
+	}
+	return ret
+}
+
+// AsyncVotingClosed indicates if an async session has hit its deadline, or been revealed early by the facilitator
+func AsyncVotingClosed(s CompleteSessionView, now time.Time) bool {
+	return s.Deadline != nil && (s.VotesShown || !now.Before(*s.Deadline))
+}
+
+// AsyncVoteRecorder records a vote on one of the stories in an async session
+type AsyncVoteRecorder func(ctx context.Context, initiator goauth.Principal, sess CompleteSessionView, vote AsyncVote) error
+
+func NewAsyncVoteRecorder(dynamo DynamoClient, tableName string, sessionExpiration time.Duration, sf *profile.StatsUpdateFactory) AsyncVoteRecorder {
+	return func(ctx context.Context, initiator goauth.Principal, sess CompleteSessionView, vote AsyncVote) error {
+		now := time.Now()
+		if AsyncVotingClosed(sess, now) {
+			return ErrorVotingClosed
+		}
+		vote.VotedAt = now
+		vote.Email = initiator.Email
+		expiration := asyncExpiration(sess, &dynamodb.AttributeValue{N: aws.String(strconv.FormatInt(now.Add(sessionExpiration).Unix(), 10))})
+
+		_, err := dynamo.TransactWriteItemsWithContext(ctx, &dynamodb.TransactWriteItemsInput{
+			TransactItems: []*dynamodb.TransactWriteItem{
+				{
+					Put: &dynamodb.Put{
+						TableName: aws.String(tableName),
+						Item:      convertAsyncVote(sess.SessionID, vote, expiration),
+					},
+				},
+				{
+					// the deadline could have been moved up, or the votes revealed, since the session was loaded
+					ConditionCheck: &dynamodb.ConditionCheck{
+						TableName: aws.String(tableName),
+						Key: map[string]*dynamodb.AttributeValue{
+							"SessionID": {S: aws.String(sess.SessionID)},
+							"RangeKey":  {S: aws.String(sessionRecordRangeKeyValue)},
+						},
+						ConditionExpression: aws.String("AsyncDeadline > :now AND VotesShown = :hidden"),
+						ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+							":now":    {N: aws.String(strconv.FormatInt(now.Unix(), 10))},
+							":hidden": {BOOL: aws.Bool(false)},
+						},
+					},
+				},
+				{
+					Update: sf.VoteIncrement(initiator.UserID),
+				},
+			},
+		})
+		if err != nil {
+			if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeTransactionCanceledException {
+				return ErrorVotingClosed
+			}
+			return errors.Wrap(err, "error recording async vote")
+		}
+
+		recordHistory(ctx, dynamo, tableName, sess.SessionID, vote.UserID, HistoryRoleParticipant, now, sessionExpiration)
+		return nil
+	}
+}
+
+type AsyncDeadlineSweeper func(ctx context.Context, now time.Time) error
+
+// NewAsyncDeadlineSweeper reveals the votes of async sessions whose deadline has passed, notifyRevealed is where
+// anything that needs to know about the results hangs off of
+func NewAsyncDeadlineSweeper(dynamo DynamoClient, tableName string, scheduleIndexName string, loadSession Loader, notifyRevealed ChangeNotifier) AsyncDeadlineSweeper {
+	return func(ctx context.Context, now time.Time) error {
+		records, err := queryAll(ctx, dynamo, &dynamodb.QueryInput{
+			TableName: aws.String(tableName),
+			IndexName: aws.String(scheduleIndexName),
+			KeyConditions: map[string]*dynamodb.Condition{
+				"ScheduleShard": {
+					ComparisonOperator: aws.String("EQ"),
+					AttributeValueList: []*dynamodb.AttributeValue{
+						{S: aws.String(scheduleShardAsyncDeadline)},
+					},
+				},
+				"ScheduledAt": {
+					ComparisonOperator: aws.String("LE"),
+					AttributeValueList: []*dynamodb.AttributeValue{
+						{N: aws.String(strconv.FormatInt(now.Unix(), 10))},
+					},
+				},
+			},
+		})
+		if err != nil {
+			return err
+		}
+
+		var sweepErr error
+		for _, r := range records {
+			sessionID := *r["SessionID"].S
+			err := revealAsyncDeadline(ctx, dynamo, tableName, sessionID, r["ScheduledAt"], loadSession, notifyRevealed)
+			if err != nil {
+				// keep going so one bad session doesn't hold up everyone else
+				zerolog.Ctx(ctx).Error().Err(err).Str("sessionID", sessionID).Msg("error revealing async session")
+				sweepErr = err
+			}
+		}
+		return sweepErr
+	}
+}
+
+func revealAsyncDeadline(ctx context.Context, dynamo DynamoClient, tableName string, sessionID string, scheduledAt *dynamodb.AttributeValue, loadSession Loader, notifyRevealed ChangeNotifier) error {
+	res, err := dynamo.UpdateItemWithContext(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(tableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"SessionID": {S: aws.String(sessionID)},
+			"RangeKey":  {S: aws.String(sessionRecordRangeKeyValue)},
+		},
+		UpdateExpression:    aws.String("SET VotesShown = :shown REMOVE ScheduleShard, ScheduledAt"),
+		ConditionExpression: aws.String("ScheduledAt = :scheduledAt"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":shown":       {BOOL: aws.Bool(true)},
+			":scheduledAt": scheduledAt,
+		},
+		ReturnValues: aws.String(dynamodb.ReturnValueUpdatedOld),
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+			zerolog.Ctx(ctx).Info().Str("sessionID", sessionID).Msg("deadline changed before it could be swept")
+			return nil
+		}
+		return errors.WithStack(err)
+	}
+	// facilitators revealing early have already told everyone
+	if readOptionalBool(res.Attributes, "VotesShown") {
+		return nil
+	}
+
+	sess, err := loadSession(ctx, sessionID)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if sess == nil {
+		return nil
+	}
+	return errors.WithStack(notifyRevealed(ctx, *sess))
+}
+
+// ToAsyncView returns what the given user gets to see of an async session. Votes stay hidden until the deadline,
+// other than the user's own, and once revealed are stripped of who cast them in sessions with anonymous reveals.
+func ToAsyncView(s CompleteSessionView, userID string) AsyncSessionView {
+	ret := AsyncSessionView{
+		SessionID:  s.SessionID,
+		Type:       s.Type,
+		VotesShown: s.VotesShown,
+		Facilitator: User{
+			UserID: s.Facilitator.UserID,
+			Name:   s.Facilitator.Name,
+			Handle: s.Facilitator.Handle,
+		},
+		Deck:    SessionDeck(s),
+		Stories: make([]AsyncStory, len(s.Stories)),
+	}
+	if s.Deadline != nil {
+		ret.Deadline = *s.Deadline
+	}
+	for i, story := range s.Stories {
+		ret.Stories[i] = AsyncStory{Story: story}
+	}
+	for _, v := range s.AsyncVotes {
+		story := findAsyncStory(ret.Stories, v.StoryKey)
+		// a blank vote is one that has been taken back
+		if story == nil || v.Vote == nil {
+			continue
+		}
+		story.VoteCount++
+		if v.UserID == userID {
+			mine := v
+			story.MyVote = &mine
+		}
+		if s.VotesShown {
+			story.Votes = append(story.Votes, v)
+		}
+	}
+	if s.VotesShown {
+		for i := range ret.Stories {
+			ret.Stories[i].Stats = asyncStoryStats(s, ret.Stories[i].Votes)
+			if s.AnonymousReveal {
+				ret.Stories[i].Votes = anonymousAsyncVotes(ret.Stories[i].Votes)
+			}
+		}
+	}
+	return ret
+}
+
+func findAsyncStory(stories []AsyncStory, key string) *AsyncStory {
+	for i := range stories {
+		if stories[i].Key == key {
+			return &stories[i]
+		}
+	}
+	return nil
+}
+
+// asyncStoryStats works stats out the same way as a live round, as if everyone who voted on the story was in it
+func asyncStoryStats(s CompleteSessionView, votes []AsyncVote) *VoteStats {
+	round := CompleteSessionView{
+		Type:         s.Type,
+		VotesShown:   true,
+		Participants: make([]User, len(votes)),
+	}
+	for i, v := range votes {
+		round.Participants[i] = User{
+			UserID:      v.UserID,
+			CurrentVote: v.Vote,
+			Confidence:  v.Confidence,
+		}
+	}
+	return RevealStats(round)
+}
+
+// hideAsyncVotes leaves who has voted on what in place, but not what they voted. Votes that have been taken back are
+// dropped since they'd be indistinguishable from hidden ones.
+func hideAsyncVotes(votes []AsyncVote) []AsyncVote {
+	ret := make([]AsyncVote, 0, len(votes))
+	for _, v := range votes {
+		if v.Vote == nil {
+			continue
+		}
+		v.Vote = nil
+		v.Confidence = ""
+		v.Rationale = ""
+		ret = append(ret, v)
+	}
+	return ret
+}
+
+// async sessions need to stick around until well after their deadline, which may be further out than a live session
+// would last
+func asyncExpiration(s CompleteSessionView, expiration *dynamodb.AttributeValue) *dynamodb.AttributeValue {
+	if s.Deadline == nil {
+		return expiration
+	}
+	current, _ := strconv.ParseInt(*expiration.N, 10, 64)
+	deadlineExpiration := s.Deadline.Add(AsyncResultsRetention).Unix()
+	if deadlineExpiration <= current {
+		return expiration
+	}
+	return &dynamodb.AttributeValue{N: aws.String(strconv.FormatInt(deadlineExpiration, 10))}
+}
+
+func convertAsyncDeadline(s CompleteSessionView, item map[string]*dynamodb.AttributeValue) {
+	if s.Deadline == nil {
+		return
+	}
+	deadline := &dynamodb.AttributeValue{N: aws.String(strconv.FormatInt(s.Deadline.Unix(), 10))}
+	item["AsyncDeadline"] = deadline
+	item["Expiration"] = asyncExpiration(s, item["Expiration"])
+	// once the deadline has passed the sweeper has already had its turn, scheduling again would reveal a second time
+	if !s.VotesShown && time.Now().Before(*s.Deadline) {
+		item["ScheduleShard"] = &dynamodb.AttributeValue{S: aws.String(scheduleShardAsyncDeadline)}
+		item["ScheduledAt"] = deadline
+	}
+}
+
+func readAsyncDeadline(item map[string]*dynamodb.AttributeValue) *time.Time {
+	d, ok := item["AsyncDeadline"]
+	if !ok {
+		return nil
+	}
+	epoch, _ := strconv.ParseInt(*d.N, 10, 64)
+	ret := time.Unix(epoch, 0).UTC()
+	return &ret
+}
+
+func convertAsyncVote(sessionID string, v AsyncVote, expiration *dynamodb.AttributeValue) map[string]*dynamodb.AttributeValue {
+	ret := map[string]*dynamodb.AttributeValue{
+		"SessionID":  {S: aws.String(sessionID)},
+		"RangeKey":   {S: aws.String(fmt.Sprintf("%s%s:%s", asyncVoteRecordRangeKeyPrefix, v.UserID, v.StoryKey))},
+		"StoryKey":   {S: aws.String(v.StoryKey)},
+		"UserID":     {S: aws.String(v.UserID)},
+		"Name":       {S: aws.String(v.Name)},
+		"Handle":     {S: aws.String(v.Handle)},
+		"VotedAt":    {N: aws.String(strconv.FormatInt(v.VotedAt.Unix(), 10))},
+		"Expiration": expiration,
+	}
+	if v.Vote != nil {
+		ret["Vote"] = &dynamodb.AttributeValue{S: v.Vote}
+	}
+	if v.Confidence != "" {
+		ret["Confidence"] = &dynamodb.AttributeValue{S: aws.String(string(v.Confidence))}
+	}
+	if v.Rationale != "" {
+		ret["Rationale"] = &dynamodb.AttributeValue{S: aws.String(v.Rationale)}
+	}
+	if v.Email != "" {
+		ret["Email"] = &dynamodb.AttributeValue{S: aws.String(v.Email)}
+	}
+	return ret
+}
+
+func readAsyncVote(item map[string]*dynamodb.AttributeValue) AsyncVote {
+	votedAt, _ := strconv.ParseInt(*item["VotedAt"].N, 10, 64)
+	ret := AsyncVote{
+		StoryKey: *item["StoryKey"].S,
+		UserID:   *item["UserID"].S,
+		Name:     *item["Name"].S,
+		Handle:   *item["Handle"].S,
+		VotedAt:  time.Unix(votedAt, 0).UTC(),
+	}
+	if v, ok := item["Vote"]; ok {
+		ret.Vote = v.S
+	}
+	if c, ok := item["Confidence"]; ok {
+		ret.Confidence = Confidence(*c.S)
+	}
+	if r, ok := item["Rationale"]; ok {
+		ret.Rationale = *r.S
+	}
+	if e, ok := item["Email"]; ok {
+		ret.Email = *e.S
+	}
+	return ret
+}
@@ -0,0 +1,342 @@
+package session
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/jonsabados/goauth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/jonsabados/pointypoints/profile"
+	"github.com/jonsabados/pointypoints/session/testutil"
+)
+
+func Test_ValidateAsync(t *testing.T) {
+	now := time.Unix(1000, 0)
+
+	testCases := []struct {
+		desc            string
+		input           AsyncRequest
+		expectedErrors  []string
+		expectedStories []Story
+	}{
+		{
+			desc: "happy path",
+			input: AsyncRequest{
+				Deadline: now.Add(time.Hour),
+				Stories:  []Story{{Key: "PP-1", Title: " Login "}, {Title: "Logout"}},
+			},
+			expectedErrors:  []string{},
+			expectedStories: []Story{{Key: "PP-1", Title: "Login"}, {Key: "2", Title: "Logout"}},
+		},
+		{
+			desc: "deadline passed",
+			input: AsyncRequest{
+				Deadline: now,
+				Stories:  []Story{{Key: "PP-1", Title: "Login"}},
+			},
+			expectedErrors:  []string{"deadline must be in the future"},
+			expectedStories: []Story{{Key: "PP-1", Title: "Login"}},
+		},
+		{
+			desc: "nothing to point",
+			input: AsyncRequest{
+				Deadline: now.Add(time.Hour),
+			},
+			expectedErrors: []string{"at least one story is required"},
+		},
+		{
+			desc: "bad stories",
+			input: AsyncRequest{
+				Deadline: now.Add(time.Hour),
+				Stories:  []Story{{Key: "2", Title: "Login"}, {Title: "  "}},
+			},
+			expectedErrors:  []string{"story 2 needs a title", "story key 2 is used more than once"},
+			expectedStories: []Story{{Key: "2", Title: "Login"}, {Key: "2"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			asserter := assert.New(t)
+			asserter.Equal(tc.expectedErrors, ValidateAsync(&tc.input, now))
+			asserter.Equal(tc.expectedStories, tc.input.Stories)
+		})
+	}
+}
+
+func Test_AsyncVotingClosed(t *testing.T) {
+	asserter := assert.New(t)
+	deadline := time.Unix(1000, 0)
+
+	asserter.False(AsyncVotingClosed(CompleteSessionView{}, deadline), "live sessions aren't async")
+	asserter.False(AsyncVotingClosed(CompleteSessionView{Deadline: &deadline}, deadline.Add(-time.Second)))
+	asserter.True(AsyncVotingClosed(CompleteSessionView{Deadline: &deadline}, deadline))
+	asserter.True(AsyncVotingClosed(CompleteSessionView{Deadline: &deadline, VotesShown: true}, deadline.Add(-time.Hour)), "revealed early")
+}
+
+func Test_AsyncVoterID(t *testing.T) {
+	asserter := assert.New(t)
+
+	asserter.Equal("a", AsyncVoterID(goauth.Principal{UserID: "a"}, "b"))
+	asserter.Equal("anonymous:b", AsyncVoterID(goauth.Principal{}, "b"))
+	asserter.Equal("", AsyncVoterID(goauth.Principal{}, ""))
+}
+
+func Test_ToAsyncView(t *testing.T) {
+	deadline := time.Unix(1000, 0).UTC()
+	votedAt := time.Unix(900, 0).UTC()
+	float := func(f float64) *float64 {
+		return &f
+	}
+
+	sess := CompleteSessionView{
+		SessionID:             "s1",
+		Type:                  SessionTypeEstimate,
+		FacilitatorSessionKey: "secret",
+		Facilitator:           User{UserID: "f", Name: "Fred", SocketID: "ffff"},
+		Deadline:              &deadline,
+		Stories:               []Story{{Key: "1", Title: "Login"}, {Key: "2", Title: "Logout"}},
+		AsyncVotes: []AsyncVote{
+			{StoryKey: "1", UserID: "a", Name: "Alice", Vote: aws.String("3"), Confidence: ConfidenceHigh, VotedAt: votedAt},
+			{StoryKey: "1", UserID: "b", Name: "Bob", Vote: aws.String("5"), Rationale: "auth is hard", VotedAt: votedAt},
+			{StoryKey: "2", UserID: "a", Name: "Alice", VotedAt: votedAt},
+			{StoryKey: "gone", UserID: "a", Name: "Alice", Vote: aws.String("1"), VotedAt: votedAt},
+		},
+	}
+
+	t.Run("before the deadline", func(t *testing.T) {
+		asserter := assert.New(t)
+		view := ToAsyncView(sess, "a")
+		asserter.Equal(User{UserID: "f", Name: "Fred"}, view.Facilitator)
+		asserter.Equal(deadline, view.Deadline)
+		asserter.False(view.VotesShown)
+		asserter.Equal([]AsyncStory{
+			{
+				Story:     Story{Key: "1", Title: "Login"},
+				VoteCount: 2,
+				MyVote:    &sess.AsyncVotes[0],
+			},
+			{
+				Story: Story{Key: "2", Title: "Logout"},
+			},
+		}, view.Stories)
+	})
+
+	t.Run("after the deadline", func(t *testing.T) {
+		asserter := assert.New(t)
+		revealed := Clone(sess)
+		revealed.VotesShown = true
+		view := ToAsyncView(revealed, "")
+		asserter.Equal([]AsyncStory{
+			{
+				Story:     Story{Key: "1", Title: "Login"},
+				VoteCount: 2,
+				Votes:     []AsyncVote{sess.AsyncVotes[0], sess.AsyncVotes[1]},
+				Stats: &VoteStats{
+					Votes:           2,
+					Average:         float(4),
+					WeightedAverage: float((3*3 + 5*2) / 5.0),
+					Confidence:      map[Confidence]int{ConfidenceHigh: 1},
+				},
+			},
+			{
+				Story: Story{Key: "2", Title: "Logout"},
+				Stats: &VoteStats{},
+			},
+		}, view.Stories)
+	})
+
+	t.Run("anonymous reveal", func(t *testing.T) {
+		asserter := assert.New(t)
+		revealed := Clone(sess)
+		revealed.VotesShown = true
+		revealed.AnonymousReveal = true
+		view := ToAsyncView(revealed, "b")
+		asserter.Equal([]AsyncVote{
+			{StoryKey: "1", Vote: aws.String("3"), Confidence: ConfidenceHigh},
+			{StoryKey: "1", Vote: aws.String("5"), Rationale: "auth is hard"},
+		}, view.Stories[0].Votes)
+		asserter.Equal(&sess.AsyncVotes[1], view.Stories[0].MyVote, "voters still see their own vote")
+		asserter.Equal(2, view.Stories[0].Stats.Votes)
+	})
+}
+
+func Test_ToFacilitatorView_HidesAsyncVotes(t *testing.T) {
+	asserter := assert.New(t)
+	deadline := time.Unix(1000, 0).UTC()
+
+	sess := CompleteSessionView{
+		SessionID: "s1",
+		Deadline:  &deadline,
+		AsyncVotes: []AsyncVote{
+			{StoryKey: "1", UserID: "a", Vote: aws.String("3"), Confidence: ConfidenceLow, Rationale: "easy"},
+			{StoryKey: "2", UserID: "a"},
+		},
+	}
+	asserter.Equal([]AsyncVote{{StoryKey: "1", UserID: "a"}}, ToFacilitatorView(sess).AsyncVotes)
+	asserter.NotNil(sess.AsyncVotes[0].Vote, "original should be left alone")
+
+	sess.VotesShown = true
+	asserter.Equal(sess.AsyncVotes, ToFacilitatorView(sess).AsyncVotes)
+}
+
+func Test_NewAsyncVoteRecorder(t *testing.T) {
+	deadline := time.Now().Add(time.Hour)
+	sess := CompleteSessionView{SessionID: "s1", Deadline: &deadline}
+	principal := goauth.Principal{UserID: "a", Email: "alice@example.com"}
+	vote := AsyncVote{StoryKey: "1", UserID: "a", Name: "Alice", Vote: aws.String("3")}
+
+	t.Run("recorded", func(t *testing.T) {
+		asserter := assert.New(t)
+		inputCtx := testutil.NewTestContext()
+		dynamo := &testutil.MockDynamoClient{}
+
+		var written *dynamodb.TransactWriteItemsInput
+		dynamo.On("TransactWriteItemsWithContext", inputCtx, mock.Anything, emptyOpts).Run(func(args mock.Arguments) {
+			written = args.Get(1).(*dynamodb.TransactWriteItemsInput)
+		}).Return(&dynamodb.TransactWriteItemsOutput{}, nil)
+		dynamo.On("UpdateItemWithContext", inputCtx, mock.Anything, emptyOpts).Return(&dynamodb.UpdateItemOutput{}, nil)
+
+		err := NewAsyncVoteRecorder(dynamo, "sessions", time.Hour, profile.NewStatsUpdateFactory("profiles"))(inputCtx, principal, sess, vote)
+		asserter.NoError(err)
+		dynamo.AssertExpectations(t)
+
+		if asserter.Len(written.TransactItems, 3) {
+			item := written.TransactItems[0].Put.Item
+			asserter.Equal("asyncvote:a:1", *item["RangeKey"].S)
+			recorded := readAsyncVote(item)
+			asserter.Equal("3", *recorded.Vote)
+			asserter.False(recorded.VotedAt.IsZero())
+			asserter.Equal("alice@example.com", recorded.Email, "results get emailed to signed in voters")
+			asserter.Equal(strconv.FormatInt(deadline.Add(AsyncResultsRetention).Unix(), 10), *item["Expiration"].N)
+
+			asserter.Equal("session", *written.TransactItems[1].ConditionCheck.Key["RangeKey"].S)
+			asserter.Equal("profiles", *written.TransactItems[2].Update.TableName)
+		}
+	})
+
+	t.Run("closed since loading", func(t *testing.T) {
+		asserter := assert.New(t)
+		inputCtx := testutil.NewTestContext()
+		dynamo := &testutil.MockDynamoClient{}
+
+		dynamo.On("TransactWriteItemsWithContext", inputCtx, mock.Anything, emptyOpts).Return(nil, awserr.New(dynamodb.ErrCodeTransactionCanceledException, "nope", nil))
+
+		err := NewAsyncVoteRecorder(dynamo, "sessions", time.Hour, profile.NewStatsUpdateFactory("profiles"))(inputCtx, principal, sess, vote)
+		asserter.Equal(ErrorVotingClosed, err)
+	})
+
+	t.Run("already closed", func(t *testing.T) {
+		asserter := assert.New(t)
+		dynamo := &testutil.MockDynamoClient{}
+		closed := Clone(sess)
+		closed.VotesShown = true
+
+		err := NewAsyncVoteRecorder(dynamo, "sessions", time.Hour, profile.NewStatsUpdateFactory("profiles"))(testutil.NewTestContext(), principal, closed, vote)
+		asserter.Equal(ErrorVotingClosed, err)
+		dynamo.AssertExpectations(t)
+	})
+}
+
+func Test_NewAsyncDeadlineSweeper(t *testing.T) {
+	asserter := assert.New(t)
+
+	inputCtx := testutil.NewTestContext()
+	dynamo := &testutil.MockDynamoClient{}
+	tableName := "sessions"
+	indexName := "schedule"
+	now := time.Unix(1000, 0)
+
+	dynamo.On("QueryWithContext", inputCtx, &dynamodb.QueryInput{
+		TableName: aws.String(tableName),
+		IndexName: aws.String(indexName),
+		KeyConditions: map[string]*dynamodb.Condition{
+			"ScheduleShard": {
+				ComparisonOperator: aws.String("EQ"),
+				AttributeValueList: []*dynamodb.AttributeValue{
+					{S: aws.String("asyncDeadline")},
+				},
+			},
+			"ScheduledAt": {
+				ComparisonOperator: aws.String("LE"),
+				AttributeValueList: []*dynamodb.AttributeValue{
+					{N: aws.String("1000")},
+				},
+			},
+		},
+	}, emptyOpts).Return(&dynamodb.QueryOutput{
+		Items: []map[string]*dynamodb.AttributeValue{
+			{"SessionID": {S: aws.String("due")}, "RangeKey": {S: aws.String("session")}, "ScheduledAt": {N: aws.String("990")}},
+			{"SessionID": {S: aws.String("revealedEarly")}, "RangeKey": {S: aws.String("session")}, "ScheduledAt": {N: aws.String("995")}},
+		},
+	}, nil)
+
+	expectReveal := func(sessionID string, scheduledAt string, wasShown bool) {
+		dynamo.On("UpdateItemWithContext", inputCtx, &dynamodb.UpdateItemInput{
+			TableName: aws.String(tableName),
+			Key: map[string]*dynamodb.AttributeValue{
+				"SessionID": {S: aws.String(sessionID)},
+				"RangeKey":  {S: aws.String("session")},
+			},
+			UpdateExpression:    aws.String("SET VotesShown = :shown REMOVE ScheduleShard, ScheduledAt"),
+			ConditionExpression: aws.String("ScheduledAt = :scheduledAt"),
+			ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+				":shown":       {BOOL: aws.Bool(true)},
+				":scheduledAt": {N: aws.String(scheduledAt)},
+			},
+			ReturnValues: aws.String(dynamodb.ReturnValueUpdatedOld),
+		}, emptyOpts).Return(&dynamodb.UpdateItemOutput{
+			Attributes: map[string]*dynamodb.AttributeValue{"VotesShown": {BOOL: aws.Bool(wasShown)}},
+		}, nil)
+	}
+	expectReveal("due", "990", false)
+	expectReveal("revealedEarly", "995", true)
+
+	loader := Loader(func(ctx context.Context, sessionID string) (*CompleteSessionView, error) {
+		asserter.Equal("due", sessionID)
+		return &CompleteSessionView{SessionID: sessionID, VotesShown: true}, nil
+	})
+
+	notified := make([]string, 0)
+	notifier := ChangeNotifier(func(ctx context.Context, updated CompleteSessionView) error {
+		notified = append(notified, updated.SessionID)
+		return nil
+	})
+
+	err := NewAsyncDeadlineSweeper(dynamo, tableName, indexName, loader, notifier)(inputCtx, now)
+	asserter.NoError(err)
+	asserter.Equal([]string{"due"}, notified)
+	dynamo.AssertExpectations(t)
+}
+
+func Test_convertAsyncDeadline(t *testing.T) {
+	asserter := assert.New(t)
+	deadline := time.Now().Add(time.Hour).Truncate(time.Second).UTC()
+
+	item := map[string]*dynamodb.AttributeValue{"Expiration": {N: aws.String("2000")}}
+	convertAsyncDeadline(CompleteSessionView{}, item)
+	asserter.Len(item, 1)
+	asserter.Nil(readAsyncDeadline(item))
+
+	convertAsyncDeadline(CompleteSessionView{Deadline: &deadline}, item)
+	asserter.Equal(&deadline, readAsyncDeadline(item))
+	asserter.Equal("asyncDeadline", *item["ScheduleShard"].S)
+	asserter.Equal(strconv.FormatInt(deadline.Unix(), 10), *item["ScheduledAt"].N)
+	asserter.Equal(strconv.FormatInt(deadline.Add(AsyncResultsRetention).Unix(), 10), *item["Expiration"].N)
+
+	item = map[string]*dynamodb.AttributeValue{"Expiration": {N: aws.String("2000")}}
+	convertAsyncDeadline(CompleteSessionView{Deadline: &deadline, VotesShown: true}, item)
+	asserter.NotContains(item, "ScheduleShard", "revealed sessions have nothing left to sweep")
+
+	passed := time.Unix(1000, 0).UTC()
+	item = map[string]*dynamodb.AttributeValue{"Expiration": {N: aws.String("2000")}}
+	convertAsyncDeadline(CompleteSessionView{Deadline: &passed}, item)
+	asserter.NotContains(item, "ScheduleShard", "deadlines that have passed have already been swept")
+}
@@ -0,0 +1,84 @@
+package session
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/pkg/errors"
+)
+
+const (
+	maxBatchGetItems   = 100
+	maxBatchWriteItems = 25
+	maxBatchAttempts   = 5
+)
+
+var batchRetryDelay = 50 * time.Millisecond
+
+// batchGet reads the records with the given keys, in chunks DynamoDB will accept and retrying anything left unprocessed
+func batchGet(ctx context.Context, dynamo DynamoClient, tableName string, keys []map[string]*dynamodb.AttributeValue) ([]map[string]*dynamodb.AttributeValue, error) {
+	var ret []map[string]*dynamodb.AttributeValue
+	for start := 0; start < len(keys); start += maxBatchGetItems {
+		end := start + maxBatchGetItems
+		if end > len(keys) {
+			end = len(keys)
+		}
+		request := map[string]*dynamodb.KeysAndAttributes{
+			tableName: {Keys: keys[start:end]},
+		}
+		for attempt := 1; len(request) > 0; attempt++ {
+			if attempt > maxBatchAttempts {
+				return nil, errors.Errorf("records still unprocessed after %d attempts", maxBatchAttempts)
+			}
+			if attempt > 1 {
+				time.Sleep(batchRetryDelay * time.Duration(attempt-1))
+			}
+			res, err := dynamo.BatchGetItemWithContext(ctx, &dynamodb.BatchGetItemInput{
+				RequestItems: request,
+			})
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			ret = append(ret, res.Responses[tableName]...)
+			request = res.UnprocessedKeys
+		}
+	}
+	return ret, nil
+}
+
+// batchDelete removes the records with the given keys. Deletes are idempotent, so if this fails part way through
+// calling it again with the same keys is safe.
+func batchDelete(ctx context.Context, dynamo DynamoClient, tableName string, keys []map[string]*dynamodb.AttributeValue) error {
+	for start := 0; start < len(keys); start += maxBatchWriteItems {
+		end := start + maxBatchWriteItems
+		if end > len(keys) {
+			end = len(keys)
+		}
+		requests := make([]*dynamodb.WriteRequest, 0, end-start)
+		for _, k := range keys[start:end] {
+			requests = append(requests, &dynamodb.WriteRequest{
+				DeleteRequest: &dynamodb.DeleteRequest{Key: k},
+			})
+		}
+		request := map[string][]*dynamodb.WriteRequest{
+			tableName: requests,
+		}
+		for attempt := 1; len(request) > 0; attempt++ {
+			if attempt > maxBatchAttempts {
+				return errors.Errorf("records still unprocessed after %d attempts", maxBatchAttempts)
+			}
+			if attempt > 1 {
+				time.Sleep(batchRetryDelay * time.Duration(attempt-1))
+			}
+			res, err := dynamo.BatchWriteItemWithContext(ctx, &dynamodb.BatchWriteItemInput{
+				RequestItems: request,
+			})
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			request = res.UnprocessedItems
+		}
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package session
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/jonsabados/pointypoints/session/testutil"
+)
+
+func Test_batchDelete(t *testing.T) {
+	asserter := assert.New(t)
+
+	ctx := testutil.NewTestContext()
+	dynamo := &testutil.MockDynamoClient{}
+	tableName := "sessions"
+
+	keys := make([]map[string]*dynamodb.AttributeValue, 30)
+	for i := range keys {
+		keys[i] = map[string]*dynamodb.AttributeValue{
+			"SessionID": {S: aws.String("abcdefg")},
+			"RangeKey":  {S: aws.String(fmt.Sprintf("user:%d", i))},
+		}
+	}
+	unprocessed := map[string][]*dynamodb.WriteRequest{
+		tableName: {{DeleteRequest: &dynamodb.DeleteRequest{Key: keys[3]}}},
+	}
+
+	var batchSizes []int
+	dynamo.On("BatchWriteItemWithContext", ctx, mock.Anything, emptyOpts).Run(func(args mock.Arguments) {
+		batchSizes = append(batchSizes, len(args.Get(1).(*dynamodb.BatchWriteItemInput).RequestItems[tableName]))
+	}).Return(&dynamodb.BatchWriteItemOutput{UnprocessedItems: unprocessed}, nil).Once()
+	dynamo.On("BatchWriteItemWithContext", ctx, mock.Anything, emptyOpts).Run(func(args mock.Arguments) {
+		batchSizes = append(batchSizes, len(args.Get(1).(*dynamodb.BatchWriteItemInput).RequestItems[tableName]))
+	}).Return(&dynamodb.BatchWriteItemOutput{}, nil)
+
+	err := batchDelete(ctx, dynamo, tableName, keys)
+	asserter.NoError(err)
+	asserter.Equal([]int{25, 1, 5}, batchSizes)
+}
+
+func Test_batchDelete_GivesUp(t *testing.T) {
+	asserter := assert.New(t)
+
+	ctx := testutil.NewTestContext()
+	dynamo := &testutil.MockDynamoClient{}
+	tableName := "sessions"
+	keys := []map[string]*dynamodb.AttributeValue{{"SessionID": {S: aws.String("abcdefg")}, "RangeKey": {S: aws.String("user:1")}}}
+
+	defaultDelay := batchRetryDelay
+	batchRetryDelay = 0
+	defer func() {
+		batchRetryDelay = defaultDelay
+	}()
+
+	dynamo.On("BatchWriteItemWithContext", ctx, mock.Anything, emptyOpts).Return(&dynamodb.BatchWriteItemOutput{
+		UnprocessedItems: map[string][]*dynamodb.WriteRequest{
+			tableName: {{DeleteRequest: &dynamodb.DeleteRequest{Key: keys[0]}}},
+		},
+	}, nil)
+
+	err := batchDelete(ctx, dynamo, tableName, keys)
+	asserter.Error(err)
+	dynamo.AssertNumberOfCalls(t, "BatchWriteItemWithContext", maxBatchAttempts)
+}
@@ -0,0 +1,330 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+
+	"github.com/jonsabados/pointypoints/api"
+)
+
+const (
+	chatRecordRangeKeyPrefix      = "chat:"
+	rateLimitRecordRangeKeyPrefix = "ratelimit:"
+)
+
+const (
+	// ChatHistorySize is how many chat messages are kept around for people joining part way through
+	ChatHistorySize   = 50
+	MaxChatMessageLen = 500
+	// chat and reactions share a budget, per connection per session
+	RateLimitMessages = 10
+	RateLimitWindow   = time.Second * 10
+)
+
+var ErrorRateLimited = errors.New("rate limited")
+var ErrorInvalidChatMessage = errors.New("invalid chat message")
+var ErrorInvalidReaction = errors.New("invalid reaction")
+
+// Reactions are the emoji that may be sent as reactions
+var Reactions = []string{"👍", "👎", "🎉", "😂", "🤔", "☕", "❤️"}
+
+type ChatRequest struct {
+	SessionID string `json:"sessionId"`
+	Text      string `json:"text"`
+}
+
+type ReactionRequest struct {
+	SessionID string `json:"sessionId"`
+	Reaction  string `json:"reaction"`
+}
+
+type ChatEntry struct {
+	ID     string    `json:"id"`
+	UserID string    `json:"userId"`
+	Name   string    `json:"name"`
+	Text   string    `json:"text"`
+	SentAt time.Time `json:"sentAt"`
+}
+
+type Reaction struct {
+	UserID   string `json:"userId"`
+	Name     string `json:"name"`
+	Reaction string `json:"reaction"`
+}
+
+type ChatCleared struct {
+	SessionID string `json:"sessionId"`
+}
+
+// ChatSender records a chat message from whoever is on the connection and sends it out to everyone in the session
+type ChatSender func(ctx context.Context, sessionID string, connectionID string, text string) (ChatEntry, error)
+
+func NewChatSender(dynamo DynamoClient, tableName string, loadSession Loader, dispatchMessage api.MessageDispatcher, sessionExpiration time.Duration) ChatSender {
+	return func(ctx context.Context, sessionID string, connectionID string, text string) (ChatEntry, error) {
+		text = strings.TrimSpace(text)
+		if text == "" || utf8.RuneCountInString(text) > MaxChatMessageLen {
+			return ChatEntry{}, ErrorInvalidChatMessage
+		}
+
+		sender, err := findSender(ctx, loadSession, sessionID, connectionID)
+		if err != nil {
+			return ChatEntry{}, err
+		}
+
+		now := time.Now()
+		err = checkRateLimit(ctx, dynamo, tableName, sessionID, connectionID, now)
+		if err != nil {
+			return ChatEntry{}, err
+		}
+
+		entry := ChatEntry{
+			ID:     uuid.New().String(),
+			UserID: sender.UserID,
+			Name:   displayName(sender),
+			Text:   text,
+			SentAt: now,
+		}
+		_, err = dynamo.PutItemWithContext(ctx, &dynamodb.PutItemInput{
+			TableName: aws.String(tableName),
+			Item:      convertChatEntry(sessionID, entry, &dynamodb.AttributeValue{N: aws.String(strconv.FormatInt(now.Add(sessionExpiration).Unix(), 10))}),
+		})
+		if err != nil {
+			return ChatEntry{}, errors.WithStack(err)
+		}
+
+		// trimming is housekeeping, the message is already out there
+		err = trimChat(ctx, dynamo, tableName, sessionID)
+		if err != nil {
+			zerolog.Ctx(ctx).Warn().Err(err).Str("sessionID", sessionID).Msg("error trimming chat history")
+		}
+
+		return entry, broadcast(ctx, dynamo, tableName, dispatchMessage, sessionID, api.Message{
+			Type: api.Chat,
+			Body: entry,
+		})
+	}
+}
+
+// Reactor sends a reaction out to everyone in the session, reactions are fleeting so they are not stored
+type Reactor func(ctx context.Context, sessionID string, connectionID string, reaction string) error
+
+func NewReactor(dynamo DynamoClient, tableName string, loadSession Loader, dispatchMessage api.MessageDispatcher) Reactor {
+	return func(ctx context.Context, sessionID string, connectionID string, reaction string) error {
+		if !validReaction(reaction) {
+			return ErrorInvalidReaction
+		}
+
+		sender, err := findSender(ctx, loadSession, sessionID, connectionID)
+		if err != nil {
+			return err
+		}
+
+		err = checkRateLimit(ctx, dynamo, tableName, sessionID, connectionID, time.Now())
+		if err != nil {
+			return err
+		}
+
+		return broadcast(ctx, dynamo, tableName, dispatchMessage, sessionID, api.Message{
+			Type: api.Reaction,
+			Body: Reaction{
+				UserID:   sender.UserID,
+				Name:     displayName(sender),
+				Reaction: reaction,
+			},
+		})
+	}
+}
+
+// ChatClearer removes every chat message in the session, for facilitators that need to moderate
+type ChatClearer func(ctx context.Context, sessionID string) error
+
+func NewChatClearer(dynamo DynamoClient, tableName string, dispatchMessage api.MessageDispatcher) ChatClearer {
+	return func(ctx context.Context, sessionID string) error {
+		records, err := queryAll(ctx, dynamo, chatQuery(tableName, sessionID))
+		if err != nil {
+			return err
+		}
+		keys := make([]map[string]*dynamodb.AttributeValue, len(records))
+		for i, r := range records {
+			keys[i] = map[string]*dynamodb.AttributeValue{
+				"SessionID": r["SessionID"],
+				"RangeKey":  r["RangeKey"],
+			}
+		}
+		err = batchDelete(ctx, dynamo, tableName, keys)
+		if err != nil {
+			return err
+		}
+		return broadcast(ctx, dynamo, tableName, dispatchMessage, sessionID, api.Message{
+			Type: api.ChatCleared,
+			Body: ChatCleared{SessionID: sessionID},
+		})
+	}
+}
+
+func findSender(ctx context.Context, loadSession Loader, sessionID string, connectionID string) (User, error) {
+	sess, err := loadSession(ctx, sessionID)
+	if err != nil {
+		return User{}, err
+	}
+	if sess == nil {
+		return User{}, ErrorSessionNotFound
+	}
+	if sess.Facilitator.SocketID == connectionID {
+		return sess.Facilitator, nil
+	}
+	for _, p := range sess.Participants {
+		if p.SocketID == connectionID {
+			return p, nil
+		}
+	}
+	// watchers can follow along but don't get a say
+	return User{}, ErrorUserNotFound
+}
+
+func displayName(u User) string {
+	if u.Handle != "" {
+		return u.Handle
+	}
+	return u.Name
+}
+
+func validReaction(reaction string) bool {
+	for _, r := range Reactions {
+		if r == reaction {
+			return true
+		}
+	}
+	return false
+}
+
+// checkRateLimit counts messages in fixed windows, each window gets its own record so nothing ever needs resetting
+func checkRateLimit(ctx context.Context, dynamo DynamoClient, tableName string, sessionID string, connectionID string, now time.Time) error {
+	window := now.Unix() / int64(RateLimitWindow/time.Second)
+	_, err := dynamo.UpdateItemWithContext(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(tableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"SessionID": {S: aws.String(sessionID)},
+			"RangeKey":  {S: aws.String(fmt.Sprintf("%s%s:%d", rateLimitRecordRangeKeyPrefix, connectionID, window))},
+		},
+		UpdateExpression:    aws.String("ADD Sent :one SET Expiration = :expiration"),
+		ConditionExpression: aws.String("attribute_not_exists(Sent) OR Sent < :max"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":one":        {N: aws.String("1")},
+			":max":        {N: aws.String(strconv.Itoa(RateLimitMessages))},
+			":expiration": {N: aws.String(strconv.FormatInt(now.Add(RateLimitWindow*2).Unix(), 10))},
+		},
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+			return ErrorRateLimited
+		}
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// trimChat drops everything but the most recent ChatHistorySize messages
+func trimChat(ctx context.Context, dynamo DynamoClient, tableName string, sessionID string) error {
+	input := chatQuery(tableName, sessionID)
+	input.ScanIndexForward = aws.Bool(false)
+	records, err := queryAll(ctx, dynamo, input)
+	if err != nil {
+		return err
+	}
+	if len(records) <= ChatHistorySize {
+		return nil
+	}
+	keys := make([]map[string]*dynamodb.AttributeValue, 0, len(records)-ChatHistorySize)
+	for _, r := range records[ChatHistorySize:] {
+		keys = append(keys, map[string]*dynamodb.AttributeValue{
+			"SessionID": r["SessionID"],
+			"RangeKey":  r["RangeKey"],
+		})
+	}
+	return batchDelete(ctx, dynamo, tableName, keys)
+}
+
+func chatQuery(tableName string, sessionID string) *dynamodb.QueryInput {
+	return &dynamodb.QueryInput{
+		TableName: aws.String(tableName),
+		KeyConditions: map[string]*dynamodb.Condition{
+			"SessionID": {
+				ComparisonOperator: aws.String("EQ"),
+				AttributeValueList: []*dynamodb.AttributeValue{
+					{S: aws.String(sessionID)},
+				},
+			},
+			"RangeKey": {
+				ComparisonOperator: aws.String("BEGINS_WITH"),
+				AttributeValueList: []*dynamodb.AttributeValue{
+					{S: aws.String(chatRecordRangeKeyPrefix)},
+				},
+			},
+		},
+		ProjectionExpression: aws.String("SessionID, RangeKey"),
+	}
+}
+
+// broadcast sends a message to every connection in the session, watchers included
+func broadcast(ctx context.Context, dynamo DynamoClient, tableName string, dispatchMessage api.MessageDispatcher, sessionID string, message api.Message) error {
+	records, err := queryAll(ctx, dynamo, &dynamodb.QueryInput{
+		TableName: aws.String(tableName),
+		KeyConditions: map[string]*dynamodb.Condition{
+			"SessionID": {
+				ComparisonOperator: aws.String("EQ"),
+				AttributeValueList: []*dynamodb.AttributeValue{
+					{S: aws.String(sessionID)},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	for _, r := range records {
+		if socketID, ok := r["SocketID"]; ok && hasWebsocket(*socketID.S) {
+			err := dispatchMessage(ctx, *socketID.S, message)
+			if err != nil {
+				zerolog.Ctx(ctx).Warn().Err(err).Msg("error notifying observer")
+			}
+		}
+	}
+	return nil
+}
+
+func convertChatEntry(sessionID string, entry ChatEntry, expiration *dynamodb.AttributeValue) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		"SessionID": {S: aws.String(sessionID)},
+		// zero padded so range keys sort in the order messages were sent
+		"RangeKey":   {S: aws.String(fmt.Sprintf("%s%020d:%s", chatRecordRangeKeyPrefix, entry.SentAt.UnixNano(), entry.ID))},
+		"ChatID":     {S: aws.String(entry.ID)},
+		"UserID":     {S: aws.String(entry.UserID)},
+		"Name":       {S: aws.String(entry.Name)},
+		"Text":       {S: aws.String(entry.Text)},
+		"SentAt":     {N: aws.String(strconv.FormatInt(entry.SentAt.UnixNano(), 10))},
+		"Expiration": expiration,
+	}
+}
+
+func readChatEntry(item map[string]*dynamodb.AttributeValue) ChatEntry {
+	sentAt, _ := strconv.ParseInt(*item["SentAt"].N, 10, 64)
+	return ChatEntry{
+		ID:     *item["ChatID"].S,
+		UserID: *item["UserID"].S,
+		Name:   *item["Name"].S,
+		Text:   *item["Text"].S,
+		SentAt: time.Unix(0, sentAt),
+	}
+}
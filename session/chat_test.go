@@ -0,0 +1,261 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/jonsabados/pointypoints/api"
+	"github.com/jonsabados/pointypoints/session/testutil"
+)
+
+func chatTestSession(sessionID string) *CompleteSessionView {
+	return &CompleteSessionView{
+		SessionID:   sessionID,
+		Facilitator: User{UserID: "f", Name: "Fred", SocketID: "fs"},
+		Participants: []User{
+			{UserID: "a", Name: "Alice", Handle: "al", SocketID: "as"},
+		},
+	}
+}
+
+func isRangeKeyPrefixQuery(prefix string) func(in *dynamodb.QueryInput) bool {
+	return func(in *dynamodb.QueryInput) bool {
+		rk, ok := in.KeyConditions["RangeKey"]
+		if prefix == "" {
+			return !ok
+		}
+		return ok && *rk.AttributeValueList[0].S == prefix
+	}
+}
+
+func Test_NewChatSender(t *testing.T) {
+	sessionID := "abcdefg"
+	tableName := "sessions"
+
+	loader := Loader(func(ctx context.Context, id string) (*CompleteSessionView, error) {
+		if id != sessionID {
+			return nil, nil
+		}
+		return chatTestSession(sessionID), nil
+	})
+
+	testCases := []struct {
+		desc            string
+		sessionID       string
+		connectionID    string
+		text            string
+		rateLimitErr    error
+		existingChats   int
+		expectedErr     error
+		expectedName    string
+		expectedText    string
+		expectedTrimmed int
+	}{
+		{
+			desc:         "participant chatting",
+			sessionID:    sessionID,
+			connectionID: "as",
+			text:         "  hello there ",
+			expectedName: "al",
+			expectedText: "hello there",
+		},
+		{
+			desc:            "facilitator chatting with a full history",
+			sessionID:       sessionID,
+			connectionID:    "fs",
+			text:            "hi",
+			existingChats:   ChatHistorySize + 2,
+			expectedName:    "Fred",
+			expectedText:    "hi",
+			expectedTrimmed: 2,
+		},
+		{
+			desc:         "nothing to say",
+			sessionID:    sessionID,
+			connectionID: "as",
+			text:         "   ",
+			expectedErr:  ErrorInvalidChatMessage,
+		},
+		{
+			desc:         "watchers don't chat",
+			sessionID:    sessionID,
+			connectionID: "watcher",
+			text:         "hi",
+			expectedErr:  ErrorUserNotFound,
+		},
+		{
+			desc:         "no session",
+			sessionID:    "nope",
+			connectionID: "as",
+			text:         "hi",
+			expectedErr:  ErrorSessionNotFound,
+		},
+		{
+			desc:         "too chatty",
+			sessionID:    sessionID,
+			connectionID: "as",
+			text:         "hi",
+			rateLimitErr: awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "nope", nil),
+			expectedErr:  ErrorRateLimited,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			asserter := assert.New(t)
+
+			ctx := testutil.NewTestContext()
+			dynamo := &testutil.MockDynamoClient{}
+			dynamo.On("UpdateItemWithContext", ctx, mock.Anything, emptyOpts).Run(func(args mock.Arguments) {
+				in := args.Get(1).(*dynamodb.UpdateItemInput)
+				asserter.Regexp(fmt.Sprintf("^ratelimit:%s:[0-9]+$", tc.connectionID), *in.Key["RangeKey"].S)
+				asserter.Equal("10", *in.ExpressionAttributeValues[":max"].N)
+			}).Return(&dynamodb.UpdateItemOutput{}, tc.rateLimitErr)
+
+			var stored map[string]*dynamodb.AttributeValue
+			dynamo.On("PutItemWithContext", ctx, mock.Anything, emptyOpts).Run(func(args mock.Arguments) {
+				stored = args.Get(1).(*dynamodb.PutItemInput).Item
+			}).Return(&dynamodb.PutItemOutput{}, nil)
+
+			existing := make([]map[string]*dynamodb.AttributeValue, tc.existingChats)
+			for i := range existing {
+				existing[i] = map[string]*dynamodb.AttributeValue{
+					"SessionID": {S: aws.String(sessionID)},
+					"RangeKey":  {S: aws.String(fmt.Sprintf("chat:%d", tc.existingChats-i))},
+				}
+			}
+			dynamo.On("QueryWithContext", ctx, mock.MatchedBy(isRangeKeyPrefixQuery("chat:")), emptyOpts).Run(func(args mock.Arguments) {
+				asserter.False(*args.Get(1).(*dynamodb.QueryInput).ScanIndexForward)
+			}).Return(&dynamodb.QueryOutput{Items: existing}, nil)
+
+			var trimmed []string
+			dynamo.On("BatchWriteItemWithContext", ctx, mock.Anything, emptyOpts).Run(func(args mock.Arguments) {
+				for _, r := range args.Get(1).(*dynamodb.BatchWriteItemInput).RequestItems[tableName] {
+					trimmed = append(trimmed, *r.DeleteRequest.Key["RangeKey"].S)
+				}
+			}).Return(&dynamodb.BatchWriteItemOutput{}, nil)
+
+			dynamo.On("QueryWithContext", ctx, mock.MatchedBy(isRangeKeyPrefixQuery("")), emptyOpts).Return(&dynamodb.QueryOutput{
+				Items: []map[string]*dynamodb.AttributeValue{
+					{"RangeKey": {S: aws.String("facilitator")}, "SocketID": {S: aws.String("fs")}},
+					{"RangeKey": {S: aws.String("user:as")}, "SocketID": {S: aws.String("as")}},
+					{"RangeKey": {S: aws.String("watcher:ws")}, "SocketID": {S: aws.String("ws")}},
+					{"RangeKey": {S: aws.String("session")}},
+				},
+			}, nil)
+
+			var sent []string
+			dispatcher := api.MessageDispatcher(func(ctx context.Context, connectionID string, message api.Message) error {
+				asserter.Equal(api.Chat, message.Type)
+				sent = append(sent, connectionID)
+				return nil
+			})
+
+			res, err := NewChatSender(dynamo, tableName, loader, dispatcher, time.Hour)(ctx, tc.sessionID, tc.connectionID, tc.text)
+			if tc.expectedErr != nil {
+				asserter.Equal(tc.expectedErr, err)
+				dynamo.AssertNotCalled(t, "PutItemWithContext", mock.Anything, mock.Anything, mock.Anything)
+				asserter.Empty(sent)
+				return
+			}
+			asserter.NoError(err)
+			asserter.Equal(tc.expectedName, res.Name)
+			asserter.Equal(tc.expectedText, res.Text)
+			fromStore := readChatEntry(stored)
+			asserter.True(res.SentAt.Equal(fromStore.SentAt))
+			fromStore.SentAt = res.SentAt
+			asserter.Equal(res, fromStore)
+			asserter.Equal([]string{"fs", "as", "ws"}, sent)
+			asserter.Len(trimmed, tc.expectedTrimmed)
+		})
+	}
+}
+
+func Test_NewReactor(t *testing.T) {
+	asserter := assert.New(t)
+
+	ctx := testutil.NewTestContext()
+	dynamo := &testutil.MockDynamoClient{}
+	sessionID := "abcdefg"
+	loader := Loader(func(ctx context.Context, id string) (*CompleteSessionView, error) {
+		return chatTestSession(sessionID), nil
+	})
+	dynamo.On("UpdateItemWithContext", ctx, mock.Anything, emptyOpts).Return(&dynamodb.UpdateItemOutput{}, nil)
+	dynamo.On("QueryWithContext", ctx, mock.Anything, emptyOpts).Return(&dynamodb.QueryOutput{
+		Items: []map[string]*dynamodb.AttributeValue{
+			{"RangeKey": {S: aws.String("facilitator")}, "SocketID": {S: aws.String("fs")}},
+			{"RangeKey": {S: aws.String("user:slack")}, "SocketID": {S: aws.String("slack:channel")}},
+		},
+	}, nil)
+
+	var sent []api.Message
+	dispatcher := api.MessageDispatcher(func(ctx context.Context, connectionID string, message api.Message) error {
+		sent = append(sent, message)
+		return nil
+	})
+
+	testInstance := NewReactor(dynamo, "sessions", loader, dispatcher)
+
+	asserter.Equal(ErrorInvalidReaction, testInstance(ctx, sessionID, "as", "<script>"))
+	asserter.Empty(sent)
+
+	asserter.NoError(testInstance(ctx, sessionID, "as", "🎉"))
+	asserter.Equal([]api.Message{{Type: api.Reaction, Body: Reaction{UserID: "a", Name: "al", Reaction: "🎉"}}}, sent)
+	dynamo.AssertNotCalled(t, "PutItemWithContext", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func Test_NewChatClearer(t *testing.T) {
+	asserter := assert.New(t)
+
+	ctx := testutil.NewTestContext()
+	dynamo := &testutil.MockDynamoClient{}
+	tableName := "sessions"
+	sessionID := "abcdefg"
+
+	chats := []map[string]*dynamodb.AttributeValue{
+		{"SessionID": {S: aws.String(sessionID)}, "RangeKey": {S: aws.String("chat:1")}},
+		{"SessionID": {S: aws.String(sessionID)}, "RangeKey": {S: aws.String("chat:2")}},
+	}
+	dynamo.On("QueryWithContext", ctx, mock.MatchedBy(isRangeKeyPrefixQuery("chat:")), emptyOpts).Return(&dynamodb.QueryOutput{Items: chats}, nil)
+	dynamo.On("BatchWriteItemWithContext", ctx, &dynamodb.BatchWriteItemInput{
+		RequestItems: map[string][]*dynamodb.WriteRequest{
+			tableName: {
+				{DeleteRequest: &dynamodb.DeleteRequest{Key: chats[0]}},
+				{DeleteRequest: &dynamodb.DeleteRequest{Key: chats[1]}},
+			},
+		},
+	}, emptyOpts).Return(&dynamodb.BatchWriteItemOutput{}, nil)
+	dynamo.On("QueryWithContext", ctx, mock.MatchedBy(isRangeKeyPrefixQuery("")), emptyOpts).Return(&dynamodb.QueryOutput{
+		Items: []map[string]*dynamodb.AttributeValue{
+			{"RangeKey": {S: aws.String("user:as")}, "SocketID": {S: aws.String("as")}},
+		},
+	}, nil)
+
+	var sent []api.Message
+	dispatcher := api.MessageDispatcher(func(ctx context.Context, connectionID string, message api.Message) error {
+		sent = append(sent, message)
+		return nil
+	})
+
+	err := NewChatClearer(dynamo, tableName, dispatcher)(ctx, sessionID)
+	asserter.NoError(err)
+	asserter.Equal([]api.Message{{Type: api.ChatCleared, Body: ChatCleared{SessionID: sessionID}}}, sent)
+	dynamo.AssertExpectations(t)
+}
+
+func Test_convertChatEntry_SortsBySendTime(t *testing.T) {
+	asserter := assert.New(t)
+
+	expiration := &dynamodb.AttributeValue{N: aws.String("1")}
+	earlier := convertChatEntry("s", ChatEntry{ID: "z", SentAt: time.Unix(9, 0)}, expiration)
+	later := convertChatEntry("s", ChatEntry{ID: "a", SentAt: time.Unix(10, 0)}, expiration)
+	asserter.Less(*earlier["RangeKey"].S, *later["RangeKey"].S)
+}
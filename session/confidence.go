@@ -0,0 +1,93 @@
+package session
+
+import (
+	"math"
+	"strconv"
+)
+
+type Confidence string
+
+const (
+	ConfidenceLow    = Confidence("low")
+	ConfidenceMedium = Confidence("medium")
+	ConfidenceHigh   = Confidence("high")
+)
+
+var confidenceWeights = map[Confidence]float64{
+	ConfidenceLow:    1,
+	ConfidenceMedium: 2,
+	ConfidenceHigh:   3,
+}
+
+// Valid reports if the confidence is one of the known levels, confidence is optional so blank is valid as well
+func (c Confidence) Valid() bool {
+	if c == "" {
+		return true
+	}
+	_, ok := confidenceWeights[c]
+	return ok
+}
+
+// votes without a confidence level are treated as middle of the road
+func (c Confidence) weight() float64 {
+	if w, ok := confidenceWeights[c]; ok {
+		return w
+	}
+	return confidenceWeights[ConfidenceMedium]
+}
+
+type VoteStats struct {
+	Votes int `json:"votes"`
+	// only numeric votes go into the averages, ? and friends are left out
+	Average         *float64           `json:"average,omitempty"`
+	WeightedAverage *float64           `json:"weightedAverage,omitempty"`
+	Confidence      map[Confidence]int `json:"confidence,omitempty"`
+	// Counts and Consensus are only filled in for polls
+	Counts    map[string]int `json:"counts,omitempty"`
+	Consensus *bool          `json:"consensus,omitempty"`
+}
+
+// RevealStats summarizes the votes in the current round, nil until the votes have been shown
+func RevealStats(s CompleteSessionView) *VoteStats {
+	if !s.VotesShown {
+		return nil
+	}
+	ret := &VoteStats{
+		Confidence: make(map[Confidence]int),
+	}
+	var total, weightedTotal, numeric, weights float64
+	addVote := func(u User) {
+		if u.CurrentVote == nil {
+			return
+		}
+		ret.Votes++
+		if u.Confidence != "" {
+			ret.Confidence[u.Confidence]++
+		}
+		points, err := strconv.ParseFloat(*u.CurrentVote, 64)
+		if err != nil || math.IsInf(points, 0) || math.IsNaN(points) {
+			return
+		}
+		numeric++
+		total += points
+		weightedTotal += points * u.Confidence.weight()
+		weights += u.Confidence.weight()
+	}
+	if s.FacilitatorPoints {
+		addVote(s.Facilitator)
+	}
+	for _, p := range s.Participants {
+		addVote(p)
+	}
+	if numeric > 0 {
+		average := total / numeric
+		weightedAverage := weightedTotal / weights
+		ret.Average = &average
+		ret.WeightedAverage = &weightedAverage
+	}
+	if len(ret.Confidence) == 0 {
+		ret.Confidence = nil
+	}
+	pollStats(s, ret)
+	return ret
+}
@@ -0,0 +1,102 @@
+package session
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Confidence_Valid(t *testing.T) {
+	asserter := assert.New(t)
+
+	asserter.True(Confidence("").Valid())
+	asserter.True(ConfidenceLow.Valid())
+	asserter.True(ConfidenceMedium.Valid())
+	asserter.True(ConfidenceHigh.Valid())
+	asserter.False(Confidence("pretty sure").Valid())
+}
+
+func Test_RevealStats(t *testing.T) {
+	float := func(f float64) *float64 {
+		return &f
+	}
+
+	testCases := []struct {
+		desc     string
+		input    CompleteSessionView
+		expected *VoteStats
+	}{
+		{
+			desc: "hidden",
+			input: CompleteSessionView{
+				Participants: []User{{CurrentVote: aws.String("3"), Confidence: ConfidenceHigh}},
+			},
+			expected: nil,
+		},
+		{
+			desc: "weighted by confidence",
+			input: CompleteSessionView{
+				VotesShown: true,
+				Participants: []User{
+					{CurrentVote: aws.String("1"), Confidence: ConfidenceLow},
+					{CurrentVote: aws.String("5"), Confidence: ConfidenceHigh},
+					{CurrentVote: aws.String("3")},
+					{},
+				},
+			},
+			expected: &VoteStats{
+				Votes:           3,
+				Average:         float(3),
+				WeightedAverage: float((1*1 + 5*3 + 3*2) / 6.0),
+				Confidence:      map[Confidence]int{ConfidenceLow: 1, ConfidenceHigh: 1},
+			},
+		},
+		{
+			desc: "facilitator points and non numeric votes",
+			input: CompleteSessionView{
+				VotesShown:        true,
+				FacilitatorPoints: true,
+				Facilitator:       User{CurrentVote: aws.String("8"), Confidence: ConfidenceMedium},
+				Participants: []User{
+					{CurrentVote: aws.String("?"), Confidence: ConfidenceLow},
+				},
+			},
+			expected: &VoteStats{
+				Votes:           2,
+				Average:         float(8),
+				WeightedAverage: float(8),
+				Confidence:      map[Confidence]int{ConfidenceLow: 1, ConfidenceMedium: 1},
+			},
+		},
+		{
+			desc: "nothing numeric",
+			input: CompleteSessionView{
+				VotesShown:   true,
+				Facilitator:  User{CurrentVote: aws.String("8")},
+				Participants: []User{{CurrentVote: aws.String("∞")}},
+			},
+			expected: &VoteStats{
+				Votes: 1,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			assert.Equal(t, tc.expected, RevealStats(tc.input))
+		})
+	}
+}
+
+func Test_convertUser_Confidence(t *testing.T) {
+	asserter := assert.New(t)
+
+	item := convertUser("s1", Participant, User{UserID: "a", SocketID: "1", CurrentVote: aws.String("3"), Confidence: ConfidenceLow}, &dynamodb.AttributeValue{N: aws.String("1")})
+	asserter.Equal("low", *item["Confidence"].S)
+	asserter.Equal(ConfidenceLow, readUser(item).Confidence)
+
+	item = convertUser("s1", Participant, User{UserID: "a", SocketID: "1"}, &dynamodb.AttributeValue{N: aws.String("1")})
+	asserter.NotContains(item, "Confidence")
+}
@@ -0,0 +1,52 @@
+package session
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// DefaultDeck is what sessions point with unless they, or their team, say otherwise
+var DefaultDeck = []string{"0", "1", "2", "3", "5", "8", "13", "21", "?"}
+
+// SessionDeck returns the cards a session is pointing with, polls always use the cards that go with their type
+func SessionDeck(s CompleteSessionView) []string {
+	switch s.Type {
+	case SessionTypeFistOfFive:
+		return FistOfFiveDeck
+	case SessionTypeThumbs:
+		return ThumbsDeck
+	}
+	if len(s.Deck) == 0 {
+		return DefaultDeck
+	}
+	return s.Deck
+}
+
+func convertTeamSettings(s CompleteSessionView, item map[string]*dynamodb.AttributeValue) {
+	if s.TeamID != "" {
+		item["TeamID"] = &dynamodb.AttributeValue{S: aws.String(s.TeamID)}
+	}
+	if len(s.Deck) > 0 {
+		// a list rather than a string set, the order of the cards matters
+		cards := make([]*dynamodb.AttributeValue, len(s.Deck))
+		for i, c := range s.Deck {
+			cards[i] = &dynamodb.AttributeValue{S: aws.String(c)}
+		}
+		item["Deck"] = &dynamodb.AttributeValue{L: cards}
+	}
+}
+
+func readTeamSettings(item map[string]*dynamodb.AttributeValue) (string, []string) {
+	teamID := ""
+	if t, ok := item["TeamID"]; ok {
+		teamID = *t.S
+	}
+	var deck []string
+	if d, ok := item["Deck"]; ok {
+		deck = make([]string, len(d.L))
+		for i, c := range d.L {
+			deck[i] = *c.S
+		}
+	}
+	return teamID, deck
+}
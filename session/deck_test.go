@@ -0,0 +1,33 @@
+package session
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_SessionDeck(t *testing.T) {
+	asserter := assert.New(t)
+
+	asserter.Equal(DefaultDeck, SessionDeck(CompleteSessionView{}))
+	asserter.Equal([]string{"S", "M", "L"}, SessionDeck(CompleteSessionView{Deck: []string{"S", "M", "L"}}))
+	asserter.Equal(FistOfFiveDeck, SessionDeck(CompleteSessionView{Type: SessionTypeFistOfFive, Deck: []string{"S", "M", "L"}}))
+	asserter.Equal(ThumbsDeck, SessionDeck(CompleteSessionView{Type: SessionTypeThumbs}))
+}
+
+func Test_TeamSettingsRoundTrip(t *testing.T) {
+	asserter := assert.New(t)
+
+	item := make(map[string]*dynamodb.AttributeValue)
+	convertTeamSettings(CompleteSessionView{}, item)
+	asserter.Empty(item)
+	teamID, deck := readTeamSettings(item)
+	asserter.Empty(teamID)
+	asserter.Nil(deck)
+
+	convertTeamSettings(CompleteSessionView{TeamID: "team1", Deck: []string{"S", "M", "L"}}, item)
+	teamID, deck = readTeamSettings(item)
+	asserter.Equal("team1", teamID)
+	asserter.Equal([]string{"S", "M", "L"}, deck)
+}
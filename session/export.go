@@ -0,0 +1,182 @@
+package session
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type ExportFormat string
+
+const (
+	ExportCSV      = ExportFormat("csv")
+	ExportJSON     = ExportFormat("json")
+	ExportMarkdown = ExportFormat("md")
+)
+
+var exportContentTypes = map[ExportFormat]string{
+	ExportJSON:     "application/json",
+	ExportCSV:      "text/csv",
+	ExportMarkdown: "text/markdown",
+}
+
+// ExportContentTypes lists the content types exports can be rendered as, json first as it is the default
+func ExportContentTypes() []string {
+	return []string{
+		exportContentTypes[ExportJSON],
+		exportContentTypes[ExportCSV],
+		exportContentTypes[ExportMarkdown],
+	}
+}
+
+func (f ExportFormat) Valid() bool {
+	_, ok := exportContentTypes[f]
+	return ok
+}
+
+func (f ExportFormat) ContentType() string {
+	return exportContentTypes[f]
+}
+
+func ExportFormatForContentType(contentType string) ExportFormat {
+	for f, ct := range exportContentTypes {
+		if ct == contentType {
+			return f
+		}
+	}
+	return ""
+}
+
+type SessionExport struct {
+	SessionID  string        `json:"sessionId"`
+	ExportedAt time.Time     `json:"exportedAt"`
+	Rounds     []RoundResult `json:"rounds"`
+}
+
+// NewSessionExport gathers up the session's round history along with the current round. Sessions where the facilitator
+// has opted into anonymous results get their votes exported without attribution.
+func NewSessionExport(s CompleteSessionView, now time.Time) SessionExport {
+	now = now.UTC().Truncate(time.Second)
+	anonymize := s.AnonymousReveal && s.AnonymizeFacilitatorView
+
+	rounds := make([]RoundResult, 0, len(s.RoundHistory)+1)
+	rounds = append(rounds, s.RoundHistory...)
+	current := currentRoundVotes(s)
+	// an anonymized facilitator doesn't get to see votes before the reveal, so neither does their export
+	if len(current) > 0 && (s.VotesShown || !anonymize) {
+		rounds = append(rounds, RoundResult{
+			Round:      s.Round,
+			RecordedAt: now,
+			Votes:      current,
+		})
+	}
+
+	if anonymize {
+		for i, r := range rounds {
+			rounds[i] = anonymousRound(r)
+		}
+	}
+
+	return SessionExport{
+		SessionID:  s.SessionID,
+		ExportedAt: now,
+		Rounds:     rounds,
+	}
+}
+
+func anonymousRound(r RoundResult) RoundResult {
+	votes := make([]RoundVote, len(r.Votes))
+	for i, v := range r.Votes {
+		votes[i] = RoundVote{Vote: v.Vote, Rationale: v.Rationale}
+	}
+	// ordering could give away who voted what, so sort it away
+	sort.Slice(votes, func(i, j int) bool {
+		return votes[i].Vote < votes[j].Vote
+	})
+	return RoundResult{
+		Round:      r.Round,
+		RecordedAt: r.RecordedAt,
+		Votes:      votes,
+	}
+}
+
+func RenderExport(e SessionExport, format ExportFormat) (string, error) {
+	switch format {
+	case ExportJSON:
+		ret, err := json.Marshal(e)
+		return string(ret), errors.WithStack(err)
+	case ExportCSV:
+		return renderCSV(e)
+	case ExportMarkdown:
+		return renderMarkdown(e), nil
+	default:
+		return "", errors.Errorf("unknown export format %s", format)
+	}
+}
+
+func renderCSV(e SessionExport) (string, error) {
+	buf := new(bytes.Buffer)
+	w := csv.NewWriter(buf)
+	err := w.Write([]string{"round", "recordedAt", "userId", "name", "handle", "vote", "rationale"})
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	for _, r := range e.Rounds {
+		for _, v := range r.Votes {
+			err = w.Write([]string{strconv.Itoa(r.Round), r.RecordedAt.Format(time.RFC3339), csvCell(v.UserID), csvCell(v.Name), csvCell(v.Handle), csvCell(v.Vote), csvCell(v.Rationale)})
+			if err != nil {
+				return "", errors.WithStack(err)
+			}
+		}
+	}
+	w.Flush()
+	return buf.String(), errors.WithStack(w.Error())
+}
+
+func renderMarkdown(e SessionExport) string {
+	sb := new(strings.Builder)
+	fmt.Fprintf(sb, "# Session %s\n", e.SessionID)
+	if len(e.Rounds) == 0 {
+		sb.WriteString("\nNo votes recorded.\n")
+	}
+	for _, r := range e.Rounds {
+		fmt.Fprintf(sb, "\n## Round %d\n\n", r.Round)
+		sb.WriteString("| Participant | Vote | Rationale |\n")
+		sb.WriteString("| --- | --- | --- |\n")
+		for _, v := range r.Votes {
+			fmt.Fprintf(sb, "| %s | %s | %s |\n", markdownCell(voterName(v)), markdownCell(v.Vote), markdownCell(v.Rationale))
+		}
+	}
+	return sb.String()
+}
+
+func voterName(v RoundVote) string {
+	switch {
+	case v.Name != "":
+		return v.Name
+	case v.Handle != "":
+		return v.Handle
+	default:
+		return "anonymous"
+	}
+}
+
+// csvCell keeps spreadsheets from treating anything a participant typed as a formula
+func csvCell(s string) string {
+	if s != "" && strings.ContainsAny(s[:1], "=+-@\t\r") {
+		return "'" + s
+	}
+	return s
+}
+
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\|")
+	return strings.ReplaceAll(s, "\n", " ")
+}
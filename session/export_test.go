@@ -0,0 +1,127 @@
+package session
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+)
+
+func exportTestSession() CompleteSessionView {
+	return CompleteSessionView{
+		SessionID:         "abcdefg",
+		VotesShown:        true,
+		FacilitatorPoints: true,
+		Round:             2,
+		Facilitator:       User{UserID: "f", Name: "Fred", CurrentVote: aws.String("5")},
+		Participants: []User{
+			{UserID: "a", Handle: "pipe|dream", CurrentVote: aws.String("8"), Rationale: "needs a migration"},
+			{UserID: "b", Name: "Bob"},
+		},
+		RoundHistory: []RoundResult{
+			{
+				Round:      1,
+				RecordedAt: time.Unix(500, 0).UTC(),
+				Votes: []RoundVote{
+					{UserID: "f", Name: "Fred", Vote: "3"},
+					{UserID: "a", Handle: "pipe|dream", Vote: "13", Rationale: "unknowns, lots | of them"},
+				},
+			},
+		},
+	}
+}
+
+func Test_RenderExport(t *testing.T) {
+	now := time.Unix(1000, 0)
+	export := NewSessionExport(exportTestSession(), now)
+
+	testCases := []struct {
+		format   ExportFormat
+		expected string
+	}{
+		{
+			format:   ExportJSON,
+			expected: `{"sessionId":"abcdefg","exportedAt":"1970-01-01T00:16:40Z","rounds":[{"round":1,"recordedAt":"1970-01-01T00:08:20Z","votes":[{"userId":"f","name":"Fred","vote":"3"},{"userId":"a","handle":"pipe|dream","vote":"13","rationale":"unknowns, lots | of them"}]},{"round":2,"recordedAt":"1970-01-01T00:16:40Z","votes":[{"userId":"f","name":"Fred","vote":"5"},{"userId":"a","handle":"pipe|dream","vote":"8","rationale":"needs a migration"}]}]}`,
+		},
+		{
+			format: ExportCSV,
+			expected: "round,recordedAt,userId,name,handle,vote,rationale\n" +
+				"1,1970-01-01T00:08:20Z,f,Fred,,3,\n" +
+				"1,1970-01-01T00:08:20Z,a,,pipe|dream,13,\"unknowns, lots | of them\"\n" +
+				"2,1970-01-01T00:16:40Z,f,Fred,,5,\n" +
+				"2,1970-01-01T00:16:40Z,a,,pipe|dream,8,needs a migration\n",
+		},
+		{
+			format: ExportMarkdown,
+			expected: "# Session abcdefg\n" +
+				"\n## Round 1\n\n" +
+				"| Participant | Vote | Rationale |\n" +
+				"| --- | --- | --- |\n" +
+				"| Fred | 3 |  |\n" +
+				"| pipe\\|dream | 13 | unknowns, lots \\| of them |\n" +
+				"\n## Round 2\n\n" +
+				"| Participant | Vote | Rationale |\n" +
+				"| --- | --- | --- |\n" +
+				"| Fred | 5 |  |\n" +
+				"| pipe\\|dream | 8 | needs a migration |\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(string(tc.format), func(t *testing.T) {
+			asserter := assert.New(t)
+
+			res, err := RenderExport(export, tc.format)
+			asserter.NoError(err)
+			asserter.Equal(tc.expected, res)
+		})
+	}
+
+	_, err := RenderExport(export, ExportFormat("pdf"))
+	assert.EqualError(t, err, "unknown export format pdf")
+}
+
+func Test_NewSessionExport_Anonymized(t *testing.T) {
+	asserter := assert.New(t)
+
+	sess := exportTestSession()
+	sess.AnonymousReveal = true
+	sess.AnonymizeFacilitatorView = true
+
+	res := NewSessionExport(sess, time.Unix(1000, 0))
+	if asserter.Len(res.Rounds, 2) {
+		asserter.Equal([]RoundVote{{Vote: "13", Rationale: "unknowns, lots | of them"}, {Vote: "3"}}, res.Rounds[0].Votes)
+		asserter.Equal([]RoundVote{{Vote: "5"}, {Vote: "8", Rationale: "needs a migration"}}, res.Rounds[1].Votes)
+	}
+
+	sess.VotesShown = false
+	res = NewSessionExport(sess, time.Unix(1000, 0))
+	asserter.Len(res.Rounds, 1, "unrevealed votes should not be exported")
+}
+
+func Test_RenderExport_CSVFormulas(t *testing.T) {
+	asserter := assert.New(t)
+
+	export := SessionExport{
+		SessionID: "abcdefg",
+		Rounds: []RoundResult{
+			{
+				Round:      1,
+				RecordedAt: time.Unix(500, 0).UTC(),
+				Votes: []RoundVote{
+					{UserID: "a", Name: "=HYPERLINK(\"http://evil\")", Handle: "@bob", Vote: "-1", Rationale: "+1 to Fred"},
+					{UserID: "b", Name: "Sue", Vote: "3", Rationale: "a = b"},
+					{UserID: "c", Name: "\t=1+1", Vote: "5", Rationale: "\r=1+1"},
+				},
+			},
+		},
+	}
+
+	res, err := RenderExport(export, ExportCSV)
+	asserter.NoError(err)
+	asserter.Equal("round,recordedAt,userId,name,handle,vote,rationale\n"+
+		"1,1970-01-01T00:08:20Z,a,\"'=HYPERLINK(\"\"http://evil\"\")\",'@bob,'-1,'+1 to Fred\n"+
+		"1,1970-01-01T00:08:20Z,b,Sue,,3,a = b\n"+
+		"1,1970-01-01T00:08:20Z,c,'\t=1+1,,5,\"'\r=1+1\"\n", res)
+}
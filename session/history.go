@@ -0,0 +1,229 @@
+package session
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+const (
+	historyRecordRangeKeyPrefix = "history:"
+	summaryRecordRangeKeyValue  = "summary"
+)
+
+// HistoryRetention is how long history & summary records stick around after a session goes idle so that recently
+// expired sessions still show up for the people who were in them
+const HistoryRetention = time.Hour * 24 * 30
+
+const maxHistoryPageSize = 100
+
+var ErrorInvalidCursor = errors.New("invalid cursor")
+
+type HistoryRole string
+
+const (
+	HistoryRoleFacilitator HistoryRole = "facilitator"
+	HistoryRoleParticipant HistoryRole = "participant"
+)
+
+type HistoryEntry struct {
+	SessionID        string      `json:"sessionId"`
+	Role             HistoryRole `json:"role"`
+	ParticipantCount int         `json:"participantCount"`
+	LastActivity     time.Time   `json:"lastActivity"`
+	Active           bool        `json:"active"`
+}
+
+type HistoryPage struct {
+	Sessions []HistoryEntry `json:"sessions"`
+	Cursor   string         `json:"cursor,omitempty"`
+}
+
+type HistoryLister func(ctx context.Context, userID string, cursor string, limit int) (HistoryPage, error)
+
+func NewHistoryLister(dynamo DynamoClient, tableName string, indexName string, sessionExpiration time.Duration) HistoryLister {
+	return func(ctx context.Context, userID string, cursor string, limit int) (HistoryPage, error) {
+		startKey, err := decodeCursor(cursor)
+		if err != nil {
+			return HistoryPage{}, err
+		}
+		if limit <= 0 || limit > maxHistoryPageSize {
+			limit = maxHistoryPageSize
+		}
+
+		res, err := dynamo.QueryWithContext(ctx, &dynamodb.QueryInput{
+			TableName: aws.String(tableName),
+			IndexName: aws.String(indexName),
+			KeyConditions: map[string]*dynamodb.Condition{
+				"HistoryUserID": {
+					ComparisonOperator: aws.String("EQ"),
+					AttributeValueList: []*dynamodb.AttributeValue{
+						{S: aws.String(userID)},
+					},
+				},
+			},
+			ScanIndexForward:  aws.Bool(false),
+			Limit:             aws.Int64(int64(limit)),
+			ExclusiveStartKey: startKey,
+		})
+		if err != nil {
+			return HistoryPage{}, errors.WithStack(err)
+		}
+
+		ret := HistoryPage{
+			Sessions: make([]HistoryEntry, 0, len(res.Items)),
+		}
+		if len(res.LastEvaluatedKey) > 0 {
+			ret.Cursor, err = encodeCursor(res.LastEvaluatedKey)
+			if err != nil {
+				return HistoryPage{}, err
+			}
+		}
+		if len(res.Items) == 0 {
+			return ret, nil
+		}
+
+		summaries, err := loadSummaries(ctx, dynamo, tableName, res.Items)
+		if err != nil {
+			return HistoryPage{}, err
+		}
+
+		now := time.Now()
+		for _, item := range res.Items {
+			sessionID := *item["SessionID"].S
+			entry := HistoryEntry{
+				SessionID: sessionID,
+				Role:      HistoryRole(*item["Role"].S),
+			}
+			if summary, ok := summaries[sessionID]; ok {
+				if p, ok := summary["Participants"]; ok {
+					entry.ParticipantCount = len(p.SS)
+				}
+				if a, ok := summary["LastActivity"]; ok {
+					epoch, _ := strconv.ParseInt(*a.N, 10, 64)
+					entry.LastActivity = time.Unix(epoch, 0)
+				}
+			}
+			entry.Active = entry.LastActivity.Add(sessionExpiration).After(now)
+			ret.Sessions = append(ret.Sessions, entry)
+		}
+		return ret, nil
+	}
+}
+
+func loadSummaries(ctx context.Context, dynamo DynamoClient, tableName string, historyItems []map[string]*dynamodb.AttributeValue) (map[string]map[string]*dynamodb.AttributeValue, error) {
+	keys := make([]map[string]*dynamodb.AttributeValue, len(historyItems))
+	for i, item := range historyItems {
+		keys[i] = map[string]*dynamodb.AttributeValue{
+			"SessionID": {S: item["SessionID"].S},
+			"RangeKey":  {S: aws.String(summaryRecordRangeKeyValue)},
+		}
+	}
+
+	items, err := batchGet(ctx, dynamo, tableName, keys)
+	if err != nil {
+		return nil, err
+	}
+	ret := make(map[string]map[string]*dynamodb.AttributeValue, len(items))
+	for _, item := range items {
+		ret[*item["SessionID"].S] = item
+	}
+	return ret, nil
+}
+
+func encodeCursor(key map[string]*dynamodb.AttributeValue) (string, error) {
+	raw, err := json.Marshal(key)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+func decodeCursor(cursor string) (map[string]*dynamodb.AttributeValue, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrorInvalidCursor
+	}
+	var ret map[string]*dynamodb.AttributeValue
+	err = json.Unmarshal(raw, &ret)
+	if err != nil || len(ret) == 0 {
+		return nil, ErrorInvalidCursor
+	}
+	return ret, nil
+}
+
+// history is kept outside of the transactions that write the session since every vote in a session touches the same
+// summary record, and concurrent transactions against it would fail each other. Losing a bit of it is not worth
+// failing the actual action over.
+func recordHistory(ctx context.Context, dynamo DynamoClient, tableName string, sessionID string, userID string, role HistoryRole, now time.Time, sessionExpiration time.Duration) {
+	if userID == "" {
+		recordActivity(ctx, dynamo, tableName, sessionID, "", now, sessionExpiration)
+		return
+	}
+	expiration := historyExpiration(now, sessionExpiration)
+	_, err := dynamo.UpdateItemWithContext(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(tableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"SessionID": {S: aws.String(sessionID)},
+			"RangeKey":  {S: aws.String(fmt.Sprintf("%s%s", historyRecordRangeKeyPrefix, userID))},
+		},
+		// facilitators that also join in keep their facilitator role
+		UpdateExpression: aws.String("SET HistoryUserID = :user, #role = if_not_exists(#role, :role), JoinedAt = if_not_exists(JoinedAt, :now), Expiration = :expiration"),
+		ExpressionAttributeNames: map[string]*string{
+			"#role": aws.String("Role"),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":user":       {S: aws.String(userID)},
+			":role":       {S: aws.String(string(role))},
+			":now":        {N: aws.String(strconv.FormatInt(now.Unix(), 10))},
+			":expiration": expiration,
+		},
+	})
+	if err != nil {
+		zerolog.Ctx(ctx).Warn().Err(err).Str("sessionID", sessionID).Msg("error recording session history")
+	}
+
+	participant := ""
+	if role == HistoryRoleParticipant {
+		participant = userID
+	}
+	recordActivity(ctx, dynamo, tableName, sessionID, participant, now, sessionExpiration)
+}
+
+func recordActivity(ctx context.Context, dynamo DynamoClient, tableName string, sessionID string, participantUserID string, now time.Time, sessionExpiration time.Duration) {
+	input := &dynamodb.UpdateItemInput{
+		TableName: aws.String(tableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"SessionID": {S: aws.String(sessionID)},
+			"RangeKey":  {S: aws.String(summaryRecordRangeKeyValue)},
+		},
+		UpdateExpression: aws.String("SET LastActivity = :now, Expiration = :expiration"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":now":        {N: aws.String(strconv.FormatInt(now.Unix(), 10))},
+			":expiration": historyExpiration(now, sessionExpiration),
+		},
+	}
+	if participantUserID != "" {
+		input.UpdateExpression = aws.String(*input.UpdateExpression + " ADD Participants :participant")
+		input.ExpressionAttributeValues[":participant"] = &dynamodb.AttributeValue{SS: []*string{aws.String(participantUserID)}}
+	}
+	_, err := dynamo.UpdateItemWithContext(ctx, input)
+	if err != nil {
+		zerolog.Ctx(ctx).Warn().Err(err).Str("sessionID", sessionID).Msg("error recording session activity")
+	}
+}
+
+func historyExpiration(now time.Time, sessionExpiration time.Duration) *dynamodb.AttributeValue {
+	return &dynamodb.AttributeValue{N: aws.String(strconv.FormatInt(now.Add(sessionExpiration).Add(HistoryRetention).Unix(), 10))}
+}
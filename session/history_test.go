@@ -0,0 +1,163 @@
+package session
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/jonsabados/pointypoints/session/testutil"
+)
+
+func Test_NewHistoryLister(t *testing.T) {
+	asserter := assert.New(t)
+
+	inputCtx := testutil.NewTestContext()
+	dynamo := &testutil.MockDynamoClient{}
+	tableName := "sessions"
+	indexName := "history"
+	recent := time.Now().Add(-time.Hour).Truncate(time.Second)
+	stale := time.Now().Add(-time.Hour * 100).Truncate(time.Second)
+
+	lastKey := map[string]*dynamodb.AttributeValue{
+		"SessionID":     {S: aws.String("s2")},
+		"RangeKey":      {S: aws.String("history:u1")},
+		"HistoryUserID": {S: aws.String("u1")},
+		"JoinedAt":      {N: aws.String("10")},
+	}
+	cursor, err := encodeCursor(lastKey)
+	asserter.NoError(err)
+
+	dynamo.On("QueryWithContext", inputCtx, &dynamodb.QueryInput{
+		TableName: aws.String(tableName),
+		IndexName: aws.String(indexName),
+		KeyConditions: map[string]*dynamodb.Condition{
+			"HistoryUserID": {
+				ComparisonOperator: aws.String("EQ"),
+				AttributeValueList: []*dynamodb.AttributeValue{
+					{S: aws.String("u1")},
+				},
+			},
+		},
+		ScanIndexForward: aws.Bool(false),
+		Limit:            aws.Int64(2),
+	}, emptyOpts).Return(&dynamodb.QueryOutput{
+		Items: []map[string]*dynamodb.AttributeValue{
+			{"SessionID": {S: aws.String("s1")}, "Role": {S: aws.String("facilitator")}},
+			{"SessionID": {S: aws.String("s2")}, "Role": {S: aws.String("participant")}},
+		},
+		LastEvaluatedKey: lastKey,
+	}, nil)
+
+	summaryKeys := []map[string]*dynamodb.AttributeValue{
+		{"SessionID": {S: aws.String("s1")}, "RangeKey": {S: aws.String("summary")}},
+		{"SessionID": {S: aws.String("s2")}, "RangeKey": {S: aws.String("summary")}},
+	}
+	dynamo.On("BatchGetItemWithContext", inputCtx, &dynamodb.BatchGetItemInput{
+		RequestItems: map[string]*dynamodb.KeysAndAttributes{
+			tableName: {Keys: summaryKeys},
+		},
+	}, emptyOpts).Return(&dynamodb.BatchGetItemOutput{
+		Responses: map[string][]map[string]*dynamodb.AttributeValue{
+			tableName: {
+				{
+					"SessionID":    {S: aws.String("s1")},
+					"LastActivity": {N: aws.String(strconv.FormatInt(recent.Unix(), 10))},
+					"Participants": {SS: []*string{aws.String("u2"), aws.String("u3")}},
+				},
+			},
+		},
+		UnprocessedKeys: map[string]*dynamodb.KeysAndAttributes{
+			tableName: {Keys: summaryKeys[1:]},
+		},
+	}, nil).Once()
+	dynamo.On("BatchGetItemWithContext", inputCtx, &dynamodb.BatchGetItemInput{
+		RequestItems: map[string]*dynamodb.KeysAndAttributes{
+			tableName: {Keys: summaryKeys[1:]},
+		},
+	}, emptyOpts).Return(&dynamodb.BatchGetItemOutput{
+		Responses: map[string][]map[string]*dynamodb.AttributeValue{
+			tableName: {
+				{
+					"SessionID":    {S: aws.String("s2")},
+					"LastActivity": {N: aws.String(strconv.FormatInt(stale.Unix(), 10))},
+					"Participants": {SS: []*string{aws.String("u1")}},
+				},
+			},
+		},
+	}, nil).Once()
+
+	testInstance := NewHistoryLister(dynamo, tableName, indexName, time.Hour*72)
+	res, err := testInstance(inputCtx, "u1", "", 2)
+	asserter.NoError(err)
+	asserter.Equal(HistoryPage{
+		Sessions: []HistoryEntry{
+			{SessionID: "s1", Role: HistoryRoleFacilitator, ParticipantCount: 2, LastActivity: time.Unix(recent.Unix(), 0), Active: true},
+			{SessionID: "s2", Role: HistoryRoleParticipant, ParticipantCount: 1, LastActivity: time.Unix(stale.Unix(), 0), Active: false},
+		},
+		Cursor: cursor,
+	}, res)
+	dynamo.AssertExpectations(t)
+}
+
+func Test_NewHistoryLister_Cursor(t *testing.T) {
+	asserter := assert.New(t)
+
+	inputCtx := testutil.NewTestContext()
+	dynamo := &testutil.MockDynamoClient{}
+	startKey := map[string]*dynamodb.AttributeValue{
+		"SessionID":     {S: aws.String("s2")},
+		"RangeKey":      {S: aws.String("history:u1")},
+		"HistoryUserID": {S: aws.String("u1")},
+		"JoinedAt":      {N: aws.String("10")},
+	}
+	cursor, err := encodeCursor(startKey)
+	asserter.NoError(err)
+
+	dynamo.On("QueryWithContext", inputCtx, mock.MatchedBy(func(in *dynamodb.QueryInput) bool {
+		return asserter.Equal(startKey, in.ExclusiveStartKey) && asserter.Equal(int64(100), *in.Limit)
+	}), emptyOpts).Return(&dynamodb.QueryOutput{}, nil)
+
+	testInstance := NewHistoryLister(dynamo, "sessions", "history", time.Hour)
+	res, err := testInstance(inputCtx, "u1", cursor, 1000)
+	asserter.NoError(err)
+	asserter.Equal(HistoryPage{Sessions: []HistoryEntry{}}, res)
+
+	_, err = testInstance(inputCtx, "u1", "not a cursor!", 10)
+	asserter.Equal(ErrorInvalidCursor, err)
+	dynamo.AssertExpectations(t)
+}
+
+func Test_recordHistory(t *testing.T) {
+	asserter := assert.New(t)
+
+	inputCtx := testutil.NewTestContext()
+	dynamo := &testutil.MockDynamoClient{}
+	now := time.Unix(1000, 0)
+	expiration := strconv.FormatInt(now.Add(time.Hour).Add(HistoryRetention).Unix(), 10)
+
+	dynamo.On("UpdateItemWithContext", inputCtx, mock.MatchedBy(func(in *dynamodb.UpdateItemInput) bool {
+		return *in.Key["RangeKey"].S == "history:u1"
+	}), emptyOpts).Run(func(args mock.Arguments) {
+		in := args.Get(1).(*dynamodb.UpdateItemInput)
+		asserter.Equal("s1", *in.Key["SessionID"].S)
+		asserter.Equal("u1", *in.ExpressionAttributeValues[":user"].S)
+		asserter.Equal("participant", *in.ExpressionAttributeValues[":role"].S)
+		asserter.Equal(expiration, *in.ExpressionAttributeValues[":expiration"].N)
+	}).Return(&dynamodb.UpdateItemOutput{}, nil)
+	dynamo.On("UpdateItemWithContext", inputCtx, mock.MatchedBy(func(in *dynamodb.UpdateItemInput) bool {
+		return *in.Key["RangeKey"].S == "summary"
+	}), emptyOpts).Run(func(args mock.Arguments) {
+		in := args.Get(1).(*dynamodb.UpdateItemInput)
+		asserter.Equal("SET LastActivity = :now, Expiration = :expiration ADD Participants :participant", *in.UpdateExpression)
+		asserter.Equal("1000", *in.ExpressionAttributeValues[":now"].N)
+		asserter.Equal([]*string{aws.String("u1")}, in.ExpressionAttributeValues[":participant"].SS)
+	}).Return(&dynamodb.UpdateItemOutput{}, nil)
+
+	recordHistory(inputCtx, dynamo, "sessions", "s1", "u1", HistoryRoleParticipant, now, time.Hour)
+	dynamo.AssertExpectations(t)
+}
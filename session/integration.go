@@ -0,0 +1,47 @@
+package session
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// IntegrationMessage points at a message posted by an integration that mirrors the session, so it can be kept up to date
+type IntegrationMessage struct {
+	Provider  string
+	Channel   string
+	MessageID string
+}
+
+// IntegrationSocketID builds the socket id for users coming in through an integration rather than a browser. Real
+// websocket connection ids never contain a colon, which is how these are told apart.
+func IntegrationSocketID(integration string, id string) string {
+	return fmt.Sprintf("%s:%s", integration, id)
+}
+
+func hasWebsocket(socketID string) bool {
+	return !strings.Contains(socketID, ":")
+}
+
+func convertIntegrationMessage(s CompleteSessionView, item map[string]*dynamodb.AttributeValue) {
+	if s.IntegrationMessage == nil {
+		return
+	}
+	item["IntegrationProvider"] = &dynamodb.AttributeValue{S: aws.String(s.IntegrationMessage.Provider)}
+	item["IntegrationChannel"] = &dynamodb.AttributeValue{S: aws.String(s.IntegrationMessage.Channel)}
+	item["IntegrationMessageID"] = &dynamodb.AttributeValue{S: aws.String(s.IntegrationMessage.MessageID)}
+}
+
+func readIntegrationMessage(item map[string]*dynamodb.AttributeValue) *IntegrationMessage {
+	provider, ok := item["IntegrationProvider"]
+	if !ok {
+		return nil
+	}
+	return &IntegrationMessage{
+		Provider:  *provider.S,
+		Channel:   *item["IntegrationChannel"].S,
+		MessageID: *item["IntegrationMessageID"].S,
+	}
+}
@@ -0,0 +1,52 @@
+package session
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/jonsabados/pointypoints/api"
+	"github.com/jonsabados/pointypoints/session/testutil"
+)
+
+func Test_NewChangeNotifier_SkipsIntegrationSockets(t *testing.T) {
+	asserter := assert.New(t)
+
+	inputCtx := testutil.NewTestContext()
+	dynamo := &testutil.MockDynamoClient{}
+
+	dynamo.On("QueryWithContext", inputCtx, mock.Anything, emptyOpts).Return(&dynamodb.QueryOutput{
+		Items: []map[string]*dynamodb.AttributeValue{
+			{"RangeKey": {S: aws.String("session")}},
+			{"RangeKey": {S: aws.String("facilitator")}, "SocketID": {S: aws.String(IntegrationSocketID("slack", "T1:U1"))}},
+			{"RangeKey": {S: aws.String("user:abc123=")}, "SocketID": {S: aws.String("abc123=")}},
+		},
+	}, nil)
+
+	dispatched := make([]string, 0)
+	dispatcher := api.MessageDispatcher(func(ctx context.Context, connectionID string, message api.Message) error {
+		dispatched = append(dispatched, connectionID)
+		return nil
+	})
+
+	err := NewChangeNotifier(dynamo, "sessions", dispatcher)(inputCtx, CompleteSessionView{SessionID: "abcdefg"})
+	asserter.NoError(err)
+	asserter.Equal([]string{"abc123="}, dispatched)
+}
+
+func Test_IntegrationMessageRoundTrip(t *testing.T) {
+	asserter := assert.New(t)
+
+	item := make(map[string]*dynamodb.AttributeValue)
+	convertIntegrationMessage(CompleteSessionView{}, item)
+	asserter.Empty(item)
+	asserter.Nil(readIntegrationMessage(item))
+
+	msg := &IntegrationMessage{Provider: "slack", Channel: "C1", MessageID: "1600000000.000001"}
+	convertIntegrationMessage(CompleteSessionView{IntegrationMessage: msg}, item)
+	asserter.Equal(msg, readIntegrationMessage(item))
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/jonsabados/goauth"
 	"github.com/pkg/errors"
@@ -20,7 +22,7 @@ type ChangeNotifier func(ctx context.Context, updated CompleteSessionView) error
 
 func NewChangeNotifier(dynamo DynamoClient, tableName string, dispatchMessage api.MessageDispatcher) ChangeNotifier {
 	return func(ctx context.Context, updated CompleteSessionView) error {
-		records, err := dynamo.QueryWithContext(ctx, &dynamodb.QueryInput{
+		records, err := queryAll(ctx, dynamo, &dynamodb.QueryInput{
 			TableName: aws.String(tableName),
 			KeyConditions: map[string]*dynamodb.Condition{
 				"SessionID": {
@@ -32,10 +34,10 @@ func NewChangeNotifier(dynamo DynamoClient, tableName string, dispatchMessage ap
 			},
 		})
 		if err != nil {
-			return errors.WithStack(err)
+			return err
 		}
-		for _, r := range records.Items {
-			if socketID, ok := r["SocketID"]; ok {
+		for _, r := range records {
+			if socketID, ok := r["SocketID"]; ok && hasWebsocket(*socketID.S) {
 				err := dispatchMessage(ctx, *socketID.S, api.Message{
 					Type: api.SessionUpdated,
 					Body: connectionView(updated, *socketID.S),
@@ -51,7 +53,7 @@ func NewChangeNotifier(dynamo DynamoClient, tableName string, dispatchMessage ap
 
 func connectionView(sess CompleteSessionView, connectionID string) interface{} {
 	if sess.Facilitator.SocketID == connectionID {
-		return sess
+		return ToFacilitatorView(sess)
 	}
 	return ToParticipantView(sess, connectionID)
 }
@@ -87,11 +89,27 @@ func NewWatcherSaver(dynamo DynamoClient, tableName string, sessionExpiration ti
 	}
 }
 
-type Disconnector func(ctx context.Context, connectionID string) error
+// DisconnectResult is what happened to one of the sessions a connection was part of when it went away
+type DisconnectResult struct {
+	SessionID string
+	// Notified is false if the session has since gone away, or if notifying those remaining failed
+	Notified bool
+	Err      error
+}
+
+// Disconnector removes every record belonging to a connection and lets the remaining people in each affected session
+// know. Participant records are kept around for the grace period, without a socket, so someone whose connection dropped
+// can resume with their vote intact. Records are all removed or released before anyone is notified, and doing so is
+// idempotent, so if an error is returned the disconnect can simply be retried. Problems with individual sessions are
+// reported in the results instead.
+type Disconnector func(ctx context.Context, connectionID string) ([]DisconnectResult, error)
+
+// DepartureNotifier is told about participants leaving a session, after everyone remaining has been notified
+type DepartureNotifier func(ctx context.Context, sess CompleteSessionView, departed User) error
 
-func NewDisconnector(dynamo DynamoClient, tableName string, indexName string, loadSession Loader, notifyParticipants ChangeNotifier) Disconnector {
-	return func(ctx context.Context, connectionID string) error {
-		records, err := dynamo.QueryWithContext(ctx, &dynamodb.QueryInput{
+func NewDisconnector(dynamo DynamoClient, tableName string, indexName string, gracePeriod time.Duration, loadSession Loader, notifyParticipants ChangeNotifier, notifyDeparture DepartureNotifier) Disconnector {
+	return func(ctx context.Context, connectionID string) ([]DisconnectResult, error) {
+		records, err := queryAll(ctx, dynamo, &dynamodb.QueryInput{
 			TableName: aws.String(tableName),
 			IndexName: aws.String(indexName),
 			KeyConditions: map[string]*dynamodb.Condition{
@@ -104,32 +122,95 @@ func NewDisconnector(dynamo DynamoClient, tableName string, indexName string, lo
 			},
 		})
 		if err != nil {
-			return errors.WithStack(err)
+			return nil, err
 		}
 
-		for _, r := range records.Items {
-			_, err := dynamo.DeleteItemWithContext(ctx, &dynamodb.DeleteItemInput{
-				TableName: aws.String(tableName),
-				Key: map[string]*dynamodb.AttributeValue{
-					"SessionID": r["SessionID"],
-					"RangeKey":  r["RangeKey"],
-				},
-			})
-			if err != nil {
-				return errors.WithStack(err)
+		var sessionIDs []string
+		seen := make(map[string]bool)
+		keys := make([]map[string]*dynamodb.AttributeValue, 0, len(records))
+		var participantKeys []map[string]*dynamodb.AttributeValue
+		for _, r := range records {
+			key := map[string]*dynamodb.AttributeValue{
+				"SessionID": r["SessionID"],
+				"RangeKey":  r["RangeKey"],
+			}
+			if strings.HasPrefix(*r["RangeKey"].S, participantRecordRangeKeyPrefix) {
+				participantKeys = append(participantKeys, key)
+			} else {
+				keys = append(keys, key)
 			}
+			if sessionID := *r["SessionID"].S; !seen[sessionID] {
+				seen[sessionID] = true
+				sessionIDs = append(sessionIDs, sessionID)
+			}
+		}
+
+		// the index only has keys, grab who is leaving before their records are gone
+		departing, err := batchGet(ctx, dynamo, tableName, participantKeys)
+		if err != nil {
+			return nil, err
+		}
+
+		err = releaseParticipants(ctx, dynamo, tableName, participantKeys, time.Now().Add(gracePeriod))
+		if err != nil {
+			return nil, err
+		}
+		err = batchDelete(ctx, dynamo, tableName, keys)
+		if err != nil {
+			return nil, err
+		}
 
-			sess, err := loadSession(ctx, *r["SessionID"].S)
+		results := make([]DisconnectResult, 0, len(sessionIDs))
+		for _, sessionID := range sessionIDs {
+			result := DisconnectResult{SessionID: sessionID}
+			sess, err := loadSession(ctx, sessionID)
 			if err != nil {
-				return errors.WithStack(err)
+				result.Err = errors.WithStack(err)
+			} else if sess != nil {
+				result.Err = errors.WithStack(notifyParticipants(ctx, *sess))
+				result.Notified = result.Err == nil
 			}
+			results = append(results, result)
 
-			err = notifyParticipants(ctx, *sess)
-			if err != nil {
-				return errors.WithStack(err)
+			// nobody cares about people leaving sessions that have already expired
+			if sess == nil {
+				continue
+			}
+			for _, d := range departing {
+				if *d["SessionID"].S != sessionID {
+					continue
+				}
+				err = notifyDeparture(ctx, *sess, readUser(d))
+				if err != nil {
+					// everyone in the session already knows, so this isn't worth failing the disconnect over
+					zerolog.Ctx(ctx).Error().Err(err).Msg("error notifying of departure")
+				}
 			}
 		}
-		return nil
+		return results, nil
 	}
 }
 
+// releaseParticipants drops the socket from participant records, taking them out of the session and the socket index,
+// and leaves them to expire at the given time unless someone resumes them first
+func releaseParticipants(ctx context.Context, dynamo DynamoClient, tableName string, keys []map[string]*dynamodb.AttributeValue, expiresAt time.Time) error {
+	for _, key := range keys {
+		_, err := dynamo.UpdateItemWithContext(ctx, &dynamodb.UpdateItemInput{
+			TableName:        aws.String(tableName),
+			Key:              key,
+			UpdateExpression: aws.String("SET Expiration = :expiration REMOVE SocketID"),
+			// already resumed or released, either way there is nothing left to do
+			ConditionExpression: aws.String("attribute_exists(SocketID)"),
+			ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+				":expiration": {N: aws.String(strconv.FormatInt(expiresAt.Unix(), 10))},
+			},
+		})
+		if err != nil {
+			if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+				continue
+			}
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
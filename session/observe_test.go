@@ -9,7 +9,9 @@ import (
 	"github.com/jonsabados/pointypoints/api"
 	"github.com/jonsabados/pointypoints/session/testutil"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"testing"
+	"time"
 )
 
 var emptyOpts []request.Option
@@ -47,6 +49,7 @@ func Test_NewChangeNotifier_ErrorLoadingWatchers(t *testing.T) {
 				Name:        "A",
 				Handle:      "AAA",
 				CurrentVote: aws.String("1"),
+				HasVoted:    true,
 				SocketID:    userAConnectionID,
 			},
 			{
@@ -105,6 +108,8 @@ func Test_NewChangeNotifier_VotesShown(t *testing.T) {
 		UserID:      "someUUIDA",
 		Name:        "A",
 		CurrentVote: aws.String("1"),
+		Confidence:  ConfidenceHigh,
+		HasVoted:    true,
 		SocketID:    "aaaaaaaa",
 	}
 
@@ -113,11 +118,22 @@ func Test_NewChangeNotifier_VotesShown(t *testing.T) {
 		Name:        "B",
 		Handle:      "BBB",
 		CurrentVote: aws.String("2"),
+		Rationale:   "seen it before",
+		HasVoted:    true,
 		SocketID:    "bbbbbbbb",
 	}
 
 	goneConnectionID := "gone"
 
+	average := 1.5
+	weightedAverage := 1.4
+	stats := &VoteStats{
+		Votes:           2,
+		Average:         &average,
+		WeightedAverage: &weightedAverage,
+		Confidence:      map[Confidence]int{ConfidenceHigh: 1},
+	}
+
 	input := CompleteSessionView{
 		SessionID:             sessionID,
 		VotesShown:            true,
@@ -186,16 +202,21 @@ func Test_NewChangeNotifier_VotesShown(t *testing.T) {
 					Handle: facilitator.Handle,
 				},
 				FacilitatorPoints: true,
+				Stats:             stats,
 				Participants: []User{
 					{
 						UserID:      userA.UserID,
 						Name:        userA.Name,
 						CurrentVote: userA.CurrentVote,
+						Confidence:  userA.Confidence,
+						HasVoted:    true,
 					},
 					{
 						UserID:      userB.UserID,
 						Handle:      userB.Handle,
 						CurrentVote: userB.CurrentVote,
+						Rationale:   userB.Rationale,
+						HasVoted:    true,
 					},
 				},
 			},
@@ -210,16 +231,21 @@ func Test_NewChangeNotifier_VotesShown(t *testing.T) {
 					Handle: facilitator.Handle,
 				},
 				FacilitatorPoints: true,
+				Stats:             stats,
 				Participants: []User{
 					{
 						UserID:      userA.UserID,
 						Name:        userA.Name,
 						CurrentVote: userA.CurrentVote,
+						Confidence:  userA.Confidence,
+						HasVoted:    true,
 					},
 					{
 						UserID:      userB.UserID,
 						Handle:      userB.Handle,
 						CurrentVote: userB.CurrentVote,
+						Rationale:   userB.Rationale,
+						HasVoted:    true,
 					},
 				},
 			},
@@ -237,12 +263,15 @@ func Test_NewChangeNotifier_VotesShown(t *testing.T) {
 					SocketID: facilitator.SocketID,
 				},
 				FacilitatorPoints: true,
+				Stats:             stats,
 				Participants: []User{
 					{
 						UserID:      userA.UserID,
 						Name:        userA.Name,
 						Handle:      userA.Handle,
 						CurrentVote: userA.CurrentVote,
+						Confidence:  userA.Confidence,
+						HasVoted:    true,
 						SocketID:    userA.SocketID,
 					},
 					{
@@ -250,6 +279,8 @@ func Test_NewChangeNotifier_VotesShown(t *testing.T) {
 						Name:        userB.Name,
 						Handle:      userB.Handle,
 						CurrentVote: userB.CurrentVote,
+						Rationale:   userB.Rationale,
+						HasVoted:    true,
 						SocketID:    userB.SocketID,
 					},
 				},
@@ -283,6 +314,7 @@ func Test_NewChangeNotifier_VotesHidden(t *testing.T) {
 		Name:        "A",
 		Handle:      "AAA",
 		CurrentVote: aws.String("1"),
+		HasVoted:    true,
 		SocketID:    "aaaaaaaa",
 	}
 
@@ -291,6 +323,7 @@ func Test_NewChangeNotifier_VotesHidden(t *testing.T) {
 		Name:        "B",
 		Handle:      "BBB",
 		CurrentVote: aws.String("2"),
+		HasVoted:    true,
 		SocketID:    "bbbbbbbb",
 	}
 
@@ -369,10 +402,12 @@ func Test_NewChangeNotifier_VotesHidden(t *testing.T) {
 						UserID:      userA.UserID,
 						Handle:      userA.Handle,
 						CurrentVote: userA.CurrentVote,
+						HasVoted:    true,
 					},
 					{
-						UserID: userB.UserID,
-						Handle: userB.Handle,
+						UserID:   userB.UserID,
+						Handle:   userB.Handle,
+						HasVoted: true,
 					},
 				},
 			},
@@ -389,13 +424,15 @@ func Test_NewChangeNotifier_VotesHidden(t *testing.T) {
 				FacilitatorPoints: true,
 				Participants: []User{
 					{
-						UserID: userA.UserID,
-						Handle: userA.Handle,
+						UserID:   userA.UserID,
+						Handle:   userA.Handle,
+						HasVoted: true,
 					},
 					{
 						UserID:      userB.UserID,
 						Handle:      userB.Handle,
 						CurrentVote: userB.CurrentVote,
+						HasVoted:    true,
 					},
 				},
 			},
@@ -419,6 +456,7 @@ func Test_NewChangeNotifier_VotesHidden(t *testing.T) {
 						Name:        userA.Name,
 						Handle:      userA.Handle,
 						CurrentVote: userA.CurrentVote,
+						HasVoted:    true,
 						SocketID:    userA.SocketID,
 					},
 					{
@@ -426,6 +464,7 @@ func Test_NewChangeNotifier_VotesHidden(t *testing.T) {
 						Name:        userB.Name,
 						Handle:      userB.Handle,
 						CurrentVote: userB.CurrentVote,
+						HasVoted:    true,
 						SocketID:    userB.SocketID,
 					},
 				},
@@ -433,3 +472,118 @@ func Test_NewChangeNotifier_VotesHidden(t *testing.T) {
 		},
 	}, dispatchedMessages)
 }
+
+func Test_NewDisconnector(t *testing.T) {
+	tableName := "sessions"
+	indexName := "sockets"
+	connectionID := "socket"
+	participant := User{UserID: "a", Name: "A", Handle: "AAA", SocketID: connectionID}
+
+	socketQuery := &dynamodb.QueryInput{
+		TableName: aws.String(tableName),
+		IndexName: aws.String(indexName),
+		KeyConditions: map[string]*dynamodb.Condition{
+			"SocketID": {
+				ComparisonOperator: aws.String("EQ"),
+				AttributeValueList: []*dynamodb.AttributeValue{
+					{S: aws.String(connectionID)},
+				},
+			},
+		},
+	}
+	participantKey := map[string]*dynamodb.AttributeValue{"SessionID": {S: aws.String("active")}, "RangeKey": {S: aws.String("user:socket")}}
+	vanishedKey := map[string]*dynamodb.AttributeValue{"SessionID": {S: aws.String("vanished")}, "RangeKey": {S: aws.String("user:socket")}}
+	brokenKey := map[string]*dynamodb.AttributeValue{"SessionID": {S: aws.String("broken")}, "RangeKey": {S: aws.String("watcher:socket")}}
+
+	setup := func(dynamo *testutil.MockDynamoClient, ctx context.Context, deleteErr error) {
+		dynamo.On("UpdateItemWithContext", ctx, mock.MatchedBy(func(in *dynamodb.UpdateItemInput) bool {
+			return *in.TableName == tableName && *in.UpdateExpression == "SET Expiration = :expiration REMOVE SocketID"
+		}), emptyOpts).Return(&dynamodb.UpdateItemOutput{}, nil).Twice()
+		dynamo.On("QueryWithContext", ctx, socketQuery, emptyOpts).Return(&dynamodb.QueryOutput{
+			Items: []map[string]*dynamodb.AttributeValue{participantKey, vanishedKey, brokenKey},
+		}, nil)
+		dynamo.On("BatchGetItemWithContext", ctx, &dynamodb.BatchGetItemInput{
+			RequestItems: map[string]*dynamodb.KeysAndAttributes{
+				tableName: {Keys: []map[string]*dynamodb.AttributeValue{participantKey, vanishedKey}},
+			},
+		}, emptyOpts).Return(&dynamodb.BatchGetItemOutput{
+			Responses: map[string][]map[string]*dynamodb.AttributeValue{
+				tableName: {
+					convertUser("active", Participant, participant, nil),
+					convertUser("vanished", Participant, participant, nil),
+				},
+			},
+		}, nil)
+		dynamo.On("BatchWriteItemWithContext", ctx, &dynamodb.BatchWriteItemInput{
+			RequestItems: map[string][]*dynamodb.WriteRequest{
+				tableName: {
+					{DeleteRequest: &dynamodb.DeleteRequest{Key: brokenKey}},
+				},
+			},
+		}, emptyOpts).Return(&dynamodb.BatchWriteItemOutput{}, deleteErr)
+	}
+
+	loader := Loader(func(ctx context.Context, sessionID string) (*CompleteSessionView, error) {
+		if sessionID == "vanished" {
+			return nil, nil
+		}
+		return &CompleteSessionView{SessionID: sessionID, TeamID: "team"}, nil
+	})
+
+	t.Run("per session results", func(t *testing.T) {
+		asserter := assert.New(t)
+
+		ctx := testutil.NewTestContext()
+		dynamo := &testutil.MockDynamoClient{}
+		setup(dynamo, ctx, nil)
+
+		var notified []string
+		notifier := ChangeNotifier(func(ctx context.Context, updated CompleteSessionView) error {
+			notified = append(notified, updated.SessionID)
+			if updated.SessionID == "broken" {
+				return errors.New("kablam")
+			}
+			return nil
+		})
+		var departures []string
+		departure := DepartureNotifier(func(ctx context.Context, sess CompleteSessionView, departed User) error {
+			asserter.Equal(participant, departed)
+			departures = append(departures, sess.SessionID+"/"+sess.TeamID)
+			return nil
+		})
+
+		results, err := NewDisconnector(dynamo, tableName, indexName, time.Minute, loader, notifier, departure)(ctx, connectionID)
+		asserter.NoError(err)
+		asserter.Equal([]string{"active", "broken"}, notified)
+		asserter.Equal([]string{"active/team"}, departures)
+		if asserter.Len(results, 3) {
+			asserter.Equal(DisconnectResult{SessionID: "active", Notified: true}, results[0])
+			asserter.Equal(DisconnectResult{SessionID: "vanished"}, results[1])
+			asserter.Equal("broken", results[2].SessionID)
+			asserter.False(results[2].Notified)
+			asserter.EqualError(results[2].Err, "kablam")
+		}
+		dynamo.AssertExpectations(t)
+	})
+
+	t.Run("delete failure notifies nobody", func(t *testing.T) {
+		asserter := assert.New(t)
+
+		ctx := testutil.NewTestContext()
+		dynamo := &testutil.MockDynamoClient{}
+		setup(dynamo, ctx, errors.New("kablam"))
+
+		notifier := ChangeNotifier(func(ctx context.Context, updated CompleteSessionView) error {
+			asserter.Fail("nobody should be notified until the records are gone")
+			return nil
+		})
+		departure := DepartureNotifier(func(ctx context.Context, sess CompleteSessionView, departed User) error {
+			asserter.Fail("nobody should be notified until the records are gone")
+			return nil
+		})
+
+		results, err := NewDisconnector(dynamo, tableName, indexName, time.Minute, loader, notifier, departure)(ctx, connectionID)
+		asserter.EqualError(err, "kablam")
+		asserter.Nil(results)
+	})
+}
@@ -0,0 +1,131 @@
+package session
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/pkg/errors"
+)
+
+type PresenceStatus string
+
+const (
+	PresenceActive PresenceStatus = "active"
+	PresenceIdle   PresenceStatus = "idle"
+	PresenceAway   PresenceStatus = "away"
+)
+
+const (
+	// IdleAfter is a few missed pings, the client pings every 30 seconds while someone is using the page
+	IdleAfter = time.Minute * 2
+	AwayAfter = time.Minute * 10
+)
+
+// Presence works out the status of a user based on when they were last seen doing something. Records written before
+// presence was tracked have no status at all.
+func Presence(u User, now time.Time) PresenceStatus {
+	if u.LastActive.IsZero() {
+		return ""
+	}
+	inactive := now.Sub(u.LastActive)
+	switch {
+	case inactive >= AwayAfter:
+		return PresenceAway
+	case inactive >= IdleAfter:
+		return PresenceIdle
+	default:
+		return PresenceActive
+	}
+}
+
+// WaitingFor returns the voters who have yet to vote, leaving out anyone idle or away when the facilitator has asked
+// to skip them
+func WaitingFor(s CompleteSessionView) []User {
+	voters := s.Participants
+	if s.FacilitatorPoints {
+		voters = append([]User{s.Facilitator}, voters...)
+	}
+	ret := make([]User, 0)
+	for _, u := range voters {
+		if u.CurrentVote != nil {
+			continue
+		}
+		if s.SkipIdle && (u.Status == PresenceIdle || u.Status == PresenceAway) {
+			continue
+		}
+		ret = append(ret, u)
+	}
+	return ret
+}
+
+// PresenceRecorder marks everyone on the given connection as active, returning the sessions where that changed their
+// status so the people in them can be told. Their records are kept from expiring too, saves only rewrite the user
+// records that changed, so this is what keeps people in long lived sessions like rooms around.
+type PresenceRecorder func(ctx context.Context, connectionID string, now time.Time) ([]string, error)
+
+func NewPresenceRecorder(dynamo DynamoClient, tableName string, socketIndexName string, sessionExpiration time.Duration) PresenceRecorder {
+	return func(ctx context.Context, connectionID string, now time.Time) ([]string, error) {
+		records, err := queryAll(ctx, dynamo, &dynamodb.QueryInput{
+			TableName: aws.String(tableName),
+			IndexName: aws.String(socketIndexName),
+			KeyConditions: map[string]*dynamodb.Condition{
+				"SocketID": {
+					ComparisonOperator: aws.String("EQ"),
+					AttributeValueList: []*dynamodb.AttributeValue{
+						{S: aws.String(connectionID)},
+					},
+				},
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		changed := make([]string, 0)
+		for _, r := range records {
+			rangeKey := *r["RangeKey"].S
+			if rangeKey != facilitatorRecordRangeKeyValue && !strings.HasPrefix(rangeKey, participantRecordRangeKeyPrefix) {
+				continue
+			}
+			res, err := dynamo.UpdateItemWithContext(ctx, &dynamodb.UpdateItemInput{
+				TableName: aws.String(tableName),
+				Key: map[string]*dynamodb.AttributeValue{
+					"SessionID": r["SessionID"],
+					"RangeKey":  r["RangeKey"],
+				},
+				UpdateExpression: aws.String("SET LastActive = :now, Expiration = :expiration"),
+				// the record may have been removed since the index was read, don't resurrect a partial one
+				ConditionExpression: aws.String("attribute_exists(SocketID)"),
+				ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+					":now":        {N: aws.String(strconv.FormatInt(now.Unix(), 10))},
+					":expiration": {N: aws.String(strconv.FormatInt(now.Add(sessionExpiration).Unix(), 10))},
+				},
+				ReturnValues: aws.String(dynamodb.ReturnValueUpdatedOld),
+			})
+			if err != nil {
+				if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+					continue
+				}
+				return nil, errors.WithStack(err)
+			}
+			previous := User{LastActive: readLastActive(res.Attributes)}
+			if Presence(previous, now) != PresenceActive {
+				changed = append(changed, *r["SessionID"].S)
+			}
+		}
+		return changed, nil
+	}
+}
+
+func readLastActive(item map[string]*dynamodb.AttributeValue) time.Time {
+	if v, ok := item["LastActive"]; ok && v.N != nil {
+		epoch, _ := strconv.ParseInt(*v.N, 10, 64)
+		return time.Unix(epoch, 0)
+	}
+	return time.Time{}
+}
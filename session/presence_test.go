@@ -0,0 +1,187 @@
+package session
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/jonsabados/pointypoints/session/testutil"
+)
+
+func Test_Presence(t *testing.T) {
+	now := time.Unix(10000, 0)
+
+	testCases := []struct {
+		desc       string
+		lastActive time.Time
+		expected   PresenceStatus
+	}{
+		{
+			desc:     "never seen",
+			expected: "",
+		},
+		{
+			desc:       "recent",
+			lastActive: now.Add(-time.Minute),
+			expected:   PresenceActive,
+		},
+		{
+			desc:       "idle",
+			lastActive: now.Add(-IdleAfter),
+			expected:   PresenceIdle,
+		},
+		{
+			desc:       "away",
+			lastActive: now.Add(-AwayAfter - time.Minute),
+			expected:   PresenceAway,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			assert.Equal(t, tc.expected, Presence(User{LastActive: tc.lastActive}, now))
+		})
+	}
+}
+
+func Test_WaitingFor(t *testing.T) {
+	facilitator := User{UserID: "f", Status: PresenceAway}
+	voted := User{UserID: "a", CurrentVote: aws.String("1")}
+	active := User{UserID: "b", Status: PresenceActive}
+	idle := User{UserID: "c", Status: PresenceIdle}
+	unknown := User{UserID: "d"}
+
+	testCases := []struct {
+		desc     string
+		input    CompleteSessionView
+		expected []User
+	}{
+		{
+			desc: "everyone without a vote",
+			input: CompleteSessionView{
+				Facilitator:  facilitator,
+				Participants: []User{voted, active, idle, unknown},
+			},
+			expected: []User{active, idle, unknown},
+		},
+		{
+			desc: "facilitator points",
+			input: CompleteSessionView{
+				Facilitator:       facilitator,
+				FacilitatorPoints: true,
+				Participants:      []User{voted, active},
+			},
+			expected: []User{facilitator, active},
+		},
+		{
+			desc: "skipping idle",
+			input: CompleteSessionView{
+				Facilitator:       facilitator,
+				FacilitatorPoints: true,
+				SkipIdle:          true,
+				Participants:      []User{voted, active, idle, unknown},
+			},
+			expected: []User{active, unknown},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			assert.Equal(t, tc.expected, WaitingFor(tc.input))
+		})
+	}
+}
+
+func Test_convertUser_LastActive(t *testing.T) {
+	asserter := assert.New(t)
+
+	lastActive := time.Now().Add(-IdleAfter - time.Second).Truncate(time.Second)
+	item := convertUser("s1", Participant, User{UserID: "a", SocketID: "1", LastActive: lastActive}, &dynamodb.AttributeValue{N: aws.String("1")})
+	asserter.Equal(strconv.FormatInt(lastActive.Unix(), 10), *item["LastActive"].N)
+
+	res := readUser(item)
+	asserter.True(lastActive.Equal(res.LastActive))
+	asserter.Equal(PresenceIdle, res.Status)
+}
+
+func Test_NewPresenceRecorder(t *testing.T) {
+	asserter := assert.New(t)
+
+	ctx := testutil.NewTestContext()
+	dynamo := &testutil.MockDynamoClient{}
+	tableName := "sessions"
+	indexName := "sockets"
+	connectionID := "socket"
+	now := time.Unix(10000, 0)
+
+	dynamo.On("QueryWithContext", ctx, &dynamodb.QueryInput{
+		TableName: aws.String(tableName),
+		IndexName: aws.String(indexName),
+		KeyConditions: map[string]*dynamodb.Condition{
+			"SocketID": {
+				ComparisonOperator: aws.String("EQ"),
+				AttributeValueList: []*dynamodb.AttributeValue{
+					{S: aws.String(connectionID)},
+				},
+			},
+		},
+	}, emptyOpts).Return(&dynamodb.QueryOutput{
+		Items: []map[string]*dynamodb.AttributeValue{
+			{"SessionID": {S: aws.String("active")}, "RangeKey": {S: aws.String("user:socket")}},
+			{"SessionID": {S: aws.String("idle")}, "RangeKey": {S: aws.String("facilitator")}},
+			{"SessionID": {S: aws.String("new")}, "RangeKey": {S: aws.String("user:socket")}},
+			{"SessionID": {S: aws.String("watching")}, "RangeKey": {S: aws.String("watcher:socket")}},
+			{"SessionID": {S: aws.String("gone")}, "RangeKey": {S: aws.String("user:socket")}},
+		},
+	}, nil)
+
+	previous := map[string]*dynamodb.UpdateItemOutput{
+		"active": {Attributes: map[string]*dynamodb.AttributeValue{"LastActive": {N: aws.String(strconv.FormatInt(now.Add(-time.Minute).Unix(), 10))}}},
+		"idle":   {Attributes: map[string]*dynamodb.AttributeValue{"LastActive": {N: aws.String(strconv.FormatInt(now.Add(-IdleAfter).Unix(), 10))}}},
+		"new":    {},
+	}
+	for sessionID, out := range previous {
+		sessionID := sessionID
+		dynamo.On("UpdateItemWithContext", ctx, mock.MatchedBy(func(in *dynamodb.UpdateItemInput) bool {
+			return *in.Key["SessionID"].S == sessionID
+		}), emptyOpts).Run(func(args mock.Arguments) {
+			in := args.Get(1).(*dynamodb.UpdateItemInput)
+			asserter.Equal("SET LastActive = :now, Expiration = :expiration", *in.UpdateExpression)
+			asserter.Equal("10000", *in.ExpressionAttributeValues[":now"].N)
+			asserter.Equal("13600", *in.ExpressionAttributeValues[":expiration"].N)
+			asserter.Equal(dynamodb.ReturnValueUpdatedOld, *in.ReturnValues)
+		}).Return(out, nil)
+	}
+	dynamo.On("UpdateItemWithContext", ctx, mock.MatchedBy(func(in *dynamodb.UpdateItemInput) bool {
+		return *in.Key["SessionID"].S == "gone"
+	}), emptyOpts).Return(nil, awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "nope", nil))
+
+	res, err := NewPresenceRecorder(dynamo, tableName, indexName, time.Hour)(ctx, connectionID, now)
+	asserter.NoError(err)
+	asserter.Equal([]string{"idle", "new"}, res)
+	dynamo.AssertNumberOfCalls(t, "UpdateItemWithContext", 4)
+}
+
+func Test_NewPresenceRecorder_UpdateError(t *testing.T) {
+	asserter := assert.New(t)
+
+	ctx := testutil.NewTestContext()
+	dynamo := &testutil.MockDynamoClient{}
+	dynamo.On("QueryWithContext", ctx, mock.Anything, emptyOpts).Return(&dynamodb.QueryOutput{
+		Items: []map[string]*dynamodb.AttributeValue{
+			{"SessionID": {S: aws.String("s1")}, "RangeKey": {S: aws.String("facilitator")}},
+		},
+	}, nil)
+	dynamo.On("UpdateItemWithContext", ctx, mock.Anything, emptyOpts).Return(nil, errors.New("kablam"))
+
+	res, err := NewPresenceRecorder(dynamo, "sessions", "sockets", time.Hour)(ctx, "socket", time.Now())
+	asserter.EqualError(err, "kablam")
+	asserter.Nil(res)
+}
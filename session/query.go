@@ -0,0 +1,47 @@
+package session
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/pkg/errors"
+)
+
+// queryAll runs a query to completion, following LastEvaluatedKey until every page has been read. Session partitions
+// accumulate rounds, stories and participants so they can exceed the 1MB a single query will return.
+func queryAll(ctx context.Context, dynamo DynamoClient, input *dynamodb.QueryInput) ([]map[string]*dynamodb.AttributeValue, error) {
+	var ret []map[string]*dynamodb.AttributeValue
+	page := input
+	for {
+		res, err := dynamo.QueryWithContext(ctx, page)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		ret = append(ret, res.Items...)
+		if len(res.LastEvaluatedKey) == 0 {
+			return ret, nil
+		}
+		next := *input
+		next.ExclusiveStartKey = res.LastEvaluatedKey
+		page = &next
+	}
+}
+
+// scanAll is queryAll for scans
+func scanAll(ctx context.Context, dynamo DynamoClient, input *dynamodb.ScanInput) ([]map[string]*dynamodb.AttributeValue, error) {
+	var ret []map[string]*dynamodb.AttributeValue
+	page := input
+	for {
+		res, err := dynamo.ScanWithContext(ctx, page)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		ret = append(ret, res.Items...)
+		if len(res.LastEvaluatedKey) == 0 {
+			return ret, nil
+		}
+		next := *input
+		next.ExclusiveStartKey = res.LastEvaluatedKey
+		page = &next
+	}
+}
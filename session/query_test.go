@@ -0,0 +1,226 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/jonsabados/pointypoints/api"
+	"github.com/jonsabados/pointypoints/session/testutil"
+)
+
+// pagedQuery sets up the mock to return each page in turn, chaining them together via LastEvaluatedKey
+func pagedQuery(dynamo *testutil.MockDynamoClient, ctx context.Context, input *dynamodb.QueryInput, pages ...[]map[string]*dynamodb.AttributeValue) {
+	var startKey map[string]*dynamodb.AttributeValue
+	for i, items := range pages {
+		expected := *input
+		expected.ExclusiveStartKey = startKey
+		out := &dynamodb.QueryOutput{
+			Items: items,
+			Count: aws.Int64(int64(len(items))),
+		}
+		if i < len(pages)-1 {
+			startKey = map[string]*dynamodb.AttributeValue{
+				"SessionID": {S: aws.String("page")},
+				"RangeKey":  {S: aws.String(string(rune('a' + i)))},
+			}
+			out.LastEvaluatedKey = startKey
+		}
+		dynamo.On("QueryWithContext", ctx, &expected, emptyOpts).Return(out, nil).Once()
+	}
+}
+
+func Test_queryAll(t *testing.T) {
+	testCases := []struct {
+		desc  string
+		pages [][]map[string]*dynamodb.AttributeValue
+	}{
+		{
+			desc:  "single page",
+			pages: [][]map[string]*dynamodb.AttributeValue{{{"RangeKey": {S: aws.String("1")}}}},
+		},
+		{
+			desc: "multiple pages",
+			pages: [][]map[string]*dynamodb.AttributeValue{
+				{{"RangeKey": {S: aws.String("1")}}, {"RangeKey": {S: aws.String("2")}}},
+				{},
+				{{"RangeKey": {S: aws.String("3")}}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			asserter := assert.New(t)
+
+			ctx := testutil.NewTestContext()
+			dynamo := &testutil.MockDynamoClient{}
+			input := &dynamodb.QueryInput{TableName: aws.String("sessions")}
+			pagedQuery(dynamo, ctx, input, tc.pages...)
+
+			var expected []map[string]*dynamodb.AttributeValue
+			for _, p := range tc.pages {
+				expected = append(expected, p...)
+			}
+
+			res, err := queryAll(ctx, dynamo, input)
+			asserter.NoError(err)
+			asserter.Equal(expected, res)
+			asserter.Nil(input.ExclusiveStartKey)
+			dynamo.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_queryAll_ErrorOnLaterPage(t *testing.T) {
+	asserter := assert.New(t)
+
+	ctx := testutil.NewTestContext()
+	dynamo := &testutil.MockDynamoClient{}
+	dynamo.On("QueryWithContext", ctx, mock.MatchedBy(func(in *dynamodb.QueryInput) bool {
+		return in.ExclusiveStartKey == nil
+	}), emptyOpts).Return(&dynamodb.QueryOutput{
+		Items:            []map[string]*dynamodb.AttributeValue{{"RangeKey": {S: aws.String("1")}}},
+		LastEvaluatedKey: map[string]*dynamodb.AttributeValue{"RangeKey": {S: aws.String("1")}},
+	}, nil)
+	dynamo.On("QueryWithContext", ctx, mock.MatchedBy(func(in *dynamodb.QueryInput) bool {
+		return in.ExclusiveStartKey != nil
+	}), emptyOpts).Return(nil, errors.New("kablam"))
+
+	res, err := queryAll(ctx, dynamo, &dynamodb.QueryInput{TableName: aws.String("sessions")})
+	asserter.EqualError(err, "kablam")
+	asserter.Nil(res)
+}
+
+func Test_NewLoader_MultiplePages(t *testing.T) {
+	asserter := assert.New(t)
+
+	ctx := testutil.NewTestContext()
+	dynamo := &testutil.MockDynamoClient{}
+	tableName := "sessions"
+	sessionID := "abcdefg"
+
+	facilitator := User{UserID: "f", Name: "Bob", Handle: "TheTester", SocketID: "fs"}
+	userA := User{UserID: "a", Name: "A", Handle: "AAA", SocketID: "as"}
+	userB := User{UserID: "b", Name: "B", Handle: "BBB", SocketID: "bs", CurrentVote: aws.String("5"), HasVoted: true}
+	expiration := &dynamodb.AttributeValue{N: aws.String("1000")}
+
+	pagedQuery(dynamo, ctx, &dynamodb.QueryInput{
+		TableName: aws.String(tableName),
+		KeyConditions: map[string]*dynamodb.Condition{
+			"SessionID": {
+				ComparisonOperator: aws.String("EQ"),
+				AttributeValueList: []*dynamodb.AttributeValue{
+					{S: aws.String(sessionID)},
+				},
+			},
+		},
+		ConsistentRead: aws.Bool(true),
+	},
+		[]map[string]*dynamodb.AttributeValue{
+			convertUser(sessionID, Participant, userA, expiration),
+			convertUser(sessionID, Facilitator, facilitator, expiration),
+		},
+		[]map[string]*dynamodb.AttributeValue{
+			convertSession(CompleteSessionView{SessionID: sessionID, FacilitatorSessionKey: "key", Facilitator: facilitator}, expiration),
+			convertUser(sessionID, Participant, userB, expiration),
+		},
+	)
+
+	res, err := NewLoader(dynamo, tableName)(ctx, sessionID)
+	asserter.NoError(err)
+	if asserter.NotNil(res) {
+		asserter.Equal(sessionID, res.SessionID)
+		asserter.Equal(facilitator, res.Facilitator)
+		asserter.Equal([]User{userA, userB}, res.Participants)
+	}
+	dynamo.AssertExpectations(t)
+}
+
+func Test_NewChangeNotifier_MultiplePages(t *testing.T) {
+	asserter := assert.New(t)
+
+	ctx := testutil.NewTestContext()
+	dynamo := &testutil.MockDynamoClient{}
+	tableName := "sessions"
+	sessionID := "abcdefg"
+
+	pagedQuery(dynamo, ctx, &dynamodb.QueryInput{
+		TableName: aws.String(tableName),
+		KeyConditions: map[string]*dynamodb.Condition{
+			"SessionID": {
+				ComparisonOperator: aws.String("EQ"),
+				AttributeValueList: []*dynamodb.AttributeValue{
+					{S: aws.String(sessionID)},
+				},
+			},
+		},
+	},
+		[]map[string]*dynamodb.AttributeValue{{"SocketID": {S: aws.String("a")}}},
+		[]map[string]*dynamodb.AttributeValue{{"SocketID": {S: aws.String("b")}}, {"SocketID": {S: aws.String("c")}}},
+	)
+
+	var notified []string
+	dispatcher := api.MessageDispatcher(func(ctx context.Context, connectionID string, message api.Message) error {
+		notified = append(notified, connectionID)
+		return nil
+	})
+
+	err := NewChangeNotifier(dynamo, tableName, dispatcher)(ctx, CompleteSessionView{SessionID: sessionID})
+	asserter.NoError(err)
+	asserter.Equal([]string{"a", "b", "c"}, notified)
+	dynamo.AssertExpectations(t)
+}
+
+func Test_NewDisconnector_MultiplePages(t *testing.T) {
+	asserter := assert.New(t)
+
+	ctx := testutil.NewTestContext()
+	dynamo := &testutil.MockDynamoClient{}
+	tableName := "sessions"
+	indexName := "sockets"
+	connectionID := "socket"
+
+	pagedQuery(dynamo, ctx, &dynamodb.QueryInput{
+		TableName: aws.String(tableName),
+		IndexName: aws.String(indexName),
+		KeyConditions: map[string]*dynamodb.Condition{
+			"SocketID": {
+				ComparisonOperator: aws.String("EQ"),
+				AttributeValueList: []*dynamodb.AttributeValue{
+					{S: aws.String(connectionID)},
+				},
+			},
+		},
+	},
+		[]map[string]*dynamodb.AttributeValue{{"SessionID": {S: aws.String("s1")}, "RangeKey": {S: aws.String("watcher:socket")}}},
+		[]map[string]*dynamodb.AttributeValue{{"SessionID": {S: aws.String("s2")}, "RangeKey": {S: aws.String("watcher:socket")}}},
+	)
+	dynamo.On("BatchWriteItemWithContext", ctx, mock.Anything, emptyOpts).Return(&dynamodb.BatchWriteItemOutput{}, nil)
+
+	loader := Loader(func(ctx context.Context, sessionID string) (*CompleteSessionView, error) {
+		return &CompleteSessionView{SessionID: sessionID}, nil
+	})
+	var notified []string
+	notifier := ChangeNotifier(func(ctx context.Context, updated CompleteSessionView) error {
+		notified = append(notified, updated.SessionID)
+		return nil
+	})
+	departure := DepartureNotifier(func(ctx context.Context, sess CompleteSessionView, departed User) error {
+		asserter.Fail("watchers leaving isn't a departure")
+		return nil
+	})
+
+	results, err := NewDisconnector(dynamo, tableName, indexName, time.Minute, loader, notifier, departure)(ctx, connectionID)
+	asserter.NoError(err)
+	asserter.Len(results, 2)
+	asserter.Equal([]string{"s1", "s2"}, notified)
+	dynamo.AssertNumberOfCalls(t, "BatchWriteItemWithContext", 1)
+	dynamo.AssertExpectations(t)
+}
@@ -0,0 +1,59 @@
+package session
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/rs/zerolog"
+
+	"github.com/jonsabados/pointypoints/api"
+)
+
+// StaleConnectionReaper finds connections that API Gateway has dropped without the disconnect route ever firing, say
+// when a laptop lid closes, and disconnects them so abandoned participants don't linger until their records expire.
+// It returns the outcome for every session a stale connection was removed from.
+type StaleConnectionReaper func(ctx context.Context) ([]DisconnectResult, error)
+
+func NewStaleConnectionReaper(dynamo DynamoClient, tableName string, socketIndexName string, isConnected api.ConnectionProber, disconnect Disconnector) StaleConnectionReaper {
+	return func(ctx context.Context) ([]DisconnectResult, error) {
+		// every record tied to a connection is in the socket index, which is a lot smaller than the table
+		records, err := scanAll(ctx, dynamo, &dynamodb.ScanInput{
+			TableName: aws.String(tableName),
+			IndexName: aws.String(socketIndexName),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		seen := make(map[string]bool)
+		ret := make([]DisconnectResult, 0)
+		for _, r := range records {
+			connectionID := *r["SocketID"].S
+			// people coming in through integrations have no websocket for API Gateway to know about
+			if seen[connectionID] || !hasWebsocket(connectionID) {
+				continue
+			}
+			seen[connectionID] = true
+
+			connected, err := isConnected(ctx, connectionID)
+			if err != nil {
+				// leave it be, the next run can try again
+				zerolog.Ctx(ctx).Warn().Err(err).Str("connectionID", connectionID).Msg("error probing connection")
+				continue
+			}
+			if connected {
+				continue
+			}
+
+			zerolog.Ctx(ctx).Info().Str("connectionID", connectionID).Msg("reaping stale connection")
+			results, err := disconnect(ctx, connectionID)
+			if err != nil {
+				zerolog.Ctx(ctx).Error().Err(err).Str("connectionID", connectionID).Msg("error disconnecting stale connection")
+				continue
+			}
+			ret = append(ret, results...)
+		}
+		return ret, nil
+	}
+}
@@ -0,0 +1,107 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jonsabados/pointypoints/session/testutil"
+)
+
+func Test_NewStaleConnectionReaper(t *testing.T) {
+	asserter := assert.New(t)
+
+	ctx := testutil.NewTestContext()
+	dynamo := &testutil.MockDynamoClient{}
+	tableName := "sessions"
+	indexName := "sockets"
+
+	record := func(sessionID string, rangeKey string, socketID string) map[string]*dynamodb.AttributeValue {
+		return map[string]*dynamodb.AttributeValue{
+			"SessionID": {S: aws.String(sessionID)},
+			"RangeKey":  {S: aws.String(rangeKey)},
+			"SocketID":  {S: aws.String(socketID)},
+		}
+	}
+	lastKey := map[string]*dynamodb.AttributeValue{"SessionID": {S: aws.String("s1")}}
+
+	dynamo.On("ScanWithContext", ctx, &dynamodb.ScanInput{
+		TableName: aws.String(tableName),
+		IndexName: aws.String(indexName),
+	}, emptyOpts).Return(&dynamodb.ScanOutput{
+		Items: []map[string]*dynamodb.AttributeValue{
+			record("s1", "facilitator", "alive"),
+			record("s1", "user:gone", "gone"),
+		},
+		LastEvaluatedKey: lastKey,
+	}, nil).Once()
+	dynamo.On("ScanWithContext", ctx, &dynamodb.ScanInput{
+		TableName:         aws.String(tableName),
+		IndexName:         aws.String(indexName),
+		ExclusiveStartKey: lastKey,
+	}, emptyOpts).Return(&dynamodb.ScanOutput{
+		Items: []map[string]*dynamodb.AttributeValue{
+			record("s2", "watcher:gone", "gone"),
+			record("s2", "user:unknown", "unknown"),
+			record("s3", "user:broken", "broken"),
+			record("s3", "user:slack:T1:U1", "slack:T1:U1"),
+		},
+	}, nil).Once()
+
+	var probed []string
+	prober := func(ctx context.Context, connectionID string) (bool, error) {
+		probed = append(probed, connectionID)
+		switch connectionID {
+		case "alive":
+			return true, nil
+		case "unknown":
+			return false, errors.New("kablam")
+		default:
+			return false, nil
+		}
+	}
+
+	var disconnected []string
+	disconnector := func(ctx context.Context, connectionID string) ([]DisconnectResult, error) {
+		disconnected = append(disconnected, connectionID)
+		if connectionID == "broken" {
+			return nil, errors.New("nope")
+		}
+		return []DisconnectResult{{SessionID: "s1", Notified: true}, {SessionID: "s2", Notified: true}}, nil
+	}
+
+	res, err := NewStaleConnectionReaper(dynamo, tableName, indexName, prober, disconnector)(ctx)
+	asserter.NoError(err)
+	asserter.Equal([]DisconnectResult{{SessionID: "s1", Notified: true}, {SessionID: "s2", Notified: true}}, res)
+	asserter.Equal([]string{"alive", "gone", "unknown", "broken"}, probed)
+	asserter.Equal([]string{"gone", "broken"}, disconnected)
+	dynamo.AssertExpectations(t)
+}
+
+func Test_NewStaleConnectionReaper_ScanError(t *testing.T) {
+	asserter := assert.New(t)
+
+	ctx := testutil.NewTestContext()
+	dynamo := &testutil.MockDynamoClient{}
+	dynamo.On("ScanWithContext", ctx, &dynamodb.ScanInput{
+		TableName: aws.String("sessions"),
+		IndexName: aws.String("sockets"),
+	}, emptyOpts).Return(nil, errors.New("kablam"))
+
+	prober := func(ctx context.Context, connectionID string) (bool, error) {
+		asserter.Fail("nothing to probe")
+		return true, nil
+	}
+	disconnector := func(ctx context.Context, connectionID string) ([]DisconnectResult, error) {
+		asserter.Fail("nothing to disconnect")
+		return nil, nil
+	}
+
+	res, err := NewStaleConnectionReaper(dynamo, "sessions", "sockets", prober, disconnector)(ctx)
+	asserter.EqualError(err, "kablam")
+	asserter.Nil(res)
+}
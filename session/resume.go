@@ -0,0 +1,167 @@
+package session
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/pkg/errors"
+)
+
+var ErrorInvalidResumeToken = errors.New("invalid resume token")
+
+// Resumer moves a participant who has come back on a new connection, say after their browser lost its websocket,
+// over to that connection. Their vote comes along with them, and any other records left behind under the same user are
+// cleaned up. ErrorInvalidResumeToken is returned if the token doesn't match the participant, or their record has expired,
+// in which case they will need to join again.
+type Resumer func(ctx context.Context, sessionID string, userID string, resumeToken string, connectionID string) (User, error)
+
+func NewResumer(dynamo DynamoClient, tableName string, sessionExpiration time.Duration) Resumer {
+	return func(ctx context.Context, sessionID string, userID string, resumeToken string, connectionID string) (User, error) {
+		if resumeToken == "" {
+			return User{}, ErrorInvalidResumeToken
+		}
+
+		// the loader collapses duplicate participants, here every record matters
+		records, err := queryAll(ctx, dynamo, &dynamodb.QueryInput{
+			TableName: aws.String(tableName),
+			KeyConditions: map[string]*dynamodb.Condition{
+				"SessionID": {
+					ComparisonOperator: aws.String("EQ"),
+					AttributeValueList: []*dynamodb.AttributeValue{
+						{S: aws.String(sessionID)},
+					},
+				},
+				"RangeKey": {
+					ComparisonOperator: aws.String("BEGINS_WITH"),
+					AttributeValueList: []*dynamodb.AttributeValue{
+						{S: aws.String(participantRecordRangeKeyPrefix)},
+					},
+				},
+			},
+			ConsistentRead: aws.Bool(true),
+		})
+		if err != nil {
+			return User{}, err
+		}
+
+		now := time.Now()
+		var existing *User
+		var leftovers []User
+		for _, r := range records {
+			p := readUser(r)
+			if p.UserID != userID {
+				continue
+			}
+			// TTL deletes can lag well behind the expiration, records past it are treated as already gone
+			if existing == nil && !expired(r, now) && subtle.ConstantTimeCompare([]byte(p.ResumeToken), []byte(resumeToken)) == 1 {
+				matched := p
+				existing = &matched
+			} else if p.SocketID != connectionID {
+				leftovers = append(leftovers, p)
+			}
+		}
+		if existing == nil {
+			return User{}, ErrorInvalidResumeToken
+		}
+		if existing.SocketID == connectionID {
+			return *existing, nil
+		}
+
+		resumed := *existing
+		resumed.SocketID = connectionID
+		expiration := &dynamodb.AttributeValue{N: aws.String(strconv.FormatInt(now.Add(sessionExpiration).Unix(), 10))}
+
+		actions := []*dynamodb.TransactWriteItem{
+			{
+				Put: &dynamodb.Put{
+					TableName: aws.String(tableName),
+					Item:      convertUser(sessionID, Participant, resumed, expiration),
+				},
+			},
+			{
+				// whoever gets here first wins if the same token is used from two places at once
+				Delete: &dynamodb.Delete{
+					TableName:           aws.String(tableName),
+					Key:                 participantKey(sessionID, existing.SocketID),
+					ConditionExpression: aws.String("ResumeToken = :token"),
+					ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+						":token": {S: aws.String(resumeToken)},
+					},
+				},
+			},
+		}
+		for _, l := range leftovers {
+			actions = append(actions, &dynamodb.TransactWriteItem{
+				Delete: &dynamodb.Delete{
+					TableName: aws.String(tableName),
+					Key:       participantKey(sessionID, l.SocketID),
+				},
+			})
+		}
+
+		err = transactInChunks(ctx, dynamo, actions)
+		if err != nil {
+			if conditionFailed(err) {
+				return User{}, ErrorInvalidResumeToken
+			}
+			return User{}, err
+		}
+		return resumed, nil
+	}
+}
+
+func expired(record map[string]*dynamodb.AttributeValue, now time.Time) bool {
+	e, ok := record["Expiration"]
+	if !ok || e.N == nil {
+		return false
+	}
+	expiresAt, err := strconv.ParseInt(*e.N, 10, 64)
+	return err == nil && expiresAt <= now.Unix()
+}
+
+func participantKey(sessionID string, socketID string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		"SessionID": {S: aws.String(sessionID)},
+		"RangeKey":  {S: aws.String(fmt.Sprintf("%s%s", participantRecordRangeKeyPrefix, socketID))},
+	}
+}
+
+func conditionFailed(err error) bool {
+	var canceled *dynamodb.TransactionCanceledException
+	if !errors.As(err, &canceled) {
+		return false
+	}
+	for _, r := range canceled.CancellationReasons {
+		if r.Code != nil && *r.Code == "ConditionalCheckFailed" {
+			return true
+		}
+	}
+	return false
+}
+
+// dedupeParticipants collapses participants that show up more than once under the same user, which happens when
+// someone rejoins on a new connection before their old one has been cleaned up. A record holding a vote wins over
+// one without, otherwise the first one seen is kept.
+func dedupeParticipants(participants []User) []User {
+	ret := make([]User, 0, len(participants))
+	positions := make(map[string]int)
+	for _, p := range participants {
+		if p.UserID == "" {
+			ret = append(ret, p)
+			continue
+		}
+		i, seen := positions[p.UserID]
+		if !seen {
+			positions[p.UserID] = len(ret)
+			ret = append(ret, p)
+		} else if ret[i].CurrentVote == nil && p.CurrentVote != nil {
+			ret[i] = p
+		}
+	}
+	return ret
+}
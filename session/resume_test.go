@@ -0,0 +1,223 @@
+package session
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/jonsabados/pointypoints/session/testutil"
+)
+
+func Test_NewResumer(t *testing.T) {
+	sessionID := "abcdefg"
+	tableName := "sessions"
+	expiration := &dynamodb.AttributeValue{N: aws.String(strconv.FormatInt(time.Now().Add(time.Minute).Unix(), 10))}
+	passed := &dynamodb.AttributeValue{N: aws.String(strconv.FormatInt(time.Now().Add(-time.Minute).Unix(), 10))}
+
+	voted := User{UserID: "a", Name: "A", CurrentVote: aws.String("5"), HasVoted: true, SocketID: "old", ResumeToken: "token"}
+	leftover := User{UserID: "a", Name: "A", SocketID: "older"}
+	someoneElse := User{UserID: "b", Name: "B", SocketID: "other", ResumeToken: "token"}
+	lapsed := User{UserID: "c", Name: "C", SocketID: "gone", ResumeToken: "token"}
+
+	records := []map[string]*dynamodb.AttributeValue{
+		convertUser(sessionID, Participant, voted, expiration),
+		convertUser(sessionID, Participant, leftover, expiration),
+		convertUser(sessionID, Participant, someoneElse, expiration),
+		convertUser(sessionID, Participant, lapsed, passed),
+	}
+
+	testCases := []struct {
+		desc          string
+		userID        string
+		token         string
+		connectionID  string
+		transactErr   error
+		expectedUser  User
+		expectedErr   error
+		expectedItems []string
+	}{
+		{
+			desc:          "moves the participant and cleans up",
+			userID:        "a",
+			token:         "token",
+			connectionID:  "new",
+			expectedUser:  User{UserID: "a", Name: "A", CurrentVote: aws.String("5"), HasVoted: true, SocketID: "new", ResumeToken: "token"},
+			expectedItems: []string{"put user:new", "delete user:old", "delete user:older"},
+		},
+		{
+			desc:         "already on the connection",
+			userID:       "a",
+			token:        "token",
+			connectionID: "old",
+			expectedUser: voted,
+		},
+		{
+			desc:         "wrong token",
+			userID:       "a",
+			token:        "nope",
+			connectionID: "new",
+			expectedErr:  ErrorInvalidResumeToken,
+		},
+		{
+			desc:         "expired",
+			userID:       "c",
+			token:        "token",
+			connectionID: "new",
+			expectedErr:  ErrorInvalidResumeToken,
+		},
+		{
+			desc:         "token for someone else",
+			userID:       "d",
+			token:        "token",
+			connectionID: "new",
+			expectedErr:  ErrorInvalidResumeToken,
+		},
+		{
+			desc:         "beaten to it",
+			userID:       "a",
+			token:        "token",
+			connectionID: "new",
+			transactErr: &dynamodb.TransactionCanceledException{
+				CancellationReasons: []*dynamodb.CancellationReason{
+					{Code: aws.String("None")},
+					{Code: aws.String("ConditionalCheckFailed")},
+				},
+			},
+			expectedErr:   ErrorInvalidResumeToken,
+			expectedItems: []string{"put user:new", "delete user:old", "delete user:older"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			asserter := assert.New(t)
+
+			ctx := testutil.NewTestContext()
+			dynamo := &testutil.MockDynamoClient{}
+			dynamo.On("QueryWithContext", ctx, mock.MatchedBy(func(in *dynamodb.QueryInput) bool {
+				return *in.KeyConditions["SessionID"].AttributeValueList[0].S == sessionID &&
+					*in.KeyConditions["RangeKey"].AttributeValueList[0].S == "user:" &&
+					*in.ConsistentRead
+			}), emptyOpts).Return(&dynamodb.QueryOutput{Items: records}, nil)
+
+			var written []string
+			dynamo.On("TransactWriteItemsWithContext", ctx, mock.Anything, emptyOpts).Run(func(args mock.Arguments) {
+				for _, i := range args.Get(1).(*dynamodb.TransactWriteItemsInput).TransactItems {
+					if i.Put != nil {
+						written = append(written, "put "+*i.Put.Item["RangeKey"].S)
+					} else {
+						written = append(written, "delete "+*i.Delete.Key["RangeKey"].S)
+					}
+				}
+			}).Return(&dynamodb.TransactWriteItemsOutput{}, tc.transactErr)
+
+			res, err := NewResumer(dynamo, tableName, time.Hour)(ctx, sessionID, tc.userID, tc.token, tc.connectionID)
+			if tc.expectedErr != nil {
+				asserter.Equal(tc.expectedErr, err)
+			} else {
+				asserter.NoError(err)
+				asserter.Equal(tc.expectedUser, res)
+			}
+			asserter.Equal(tc.expectedItems, written)
+		})
+	}
+}
+
+func Test_dedupeParticipants(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		input    []User
+		expected []User
+	}{
+		{
+			desc:     "no duplicates",
+			input:    []User{{UserID: "a", SocketID: "1"}, {UserID: "b", SocketID: "2"}},
+			expected: []User{{UserID: "a", SocketID: "1"}, {UserID: "b", SocketID: "2"}},
+		},
+		{
+			desc:     "vote wins",
+			input:    []User{{UserID: "a", SocketID: "1"}, {UserID: "b", SocketID: "2"}, {UserID: "a", SocketID: "3", CurrentVote: aws.String("5")}},
+			expected: []User{{UserID: "a", SocketID: "3", CurrentVote: aws.String("5")}, {UserID: "b", SocketID: "2"}},
+		},
+		{
+			desc:     "first wins without votes",
+			input:    []User{{UserID: "a", SocketID: "1"}, {UserID: "a", SocketID: "2"}},
+			expected: []User{{UserID: "a", SocketID: "1"}},
+		},
+		{
+			desc:     "anonymous users are left alone",
+			input:    []User{{SocketID: "1"}, {SocketID: "2"}},
+			expected: []User{{SocketID: "1"}, {SocketID: "2"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			assert.Equal(t, tc.expected, dedupeParticipants(tc.input))
+		})
+	}
+}
+
+func Test_DisconnectThenResume(t *testing.T) {
+	asserter := assert.New(t)
+
+	ctx := testutil.NewTestContext()
+	dynamo := &testutil.MockDynamoClient{}
+	sessionID := "abcdefg"
+	tableName := "sessions"
+	indexName := "sockets"
+
+	voted := User{UserID: "a", Name: "A", CurrentVote: aws.String("5"), HasVoted: true, SocketID: "old", ResumeToken: "token"}
+	record := convertUser(sessionID, Participant, voted, &dynamodb.AttributeValue{N: aws.String("1000")})
+	key := participantKey(sessionID, "old")
+
+	dynamo.On("QueryWithContext", ctx, mock.MatchedBy(func(in *dynamodb.QueryInput) bool {
+		return in.IndexName != nil
+	}), emptyOpts).Return(&dynamodb.QueryOutput{Items: []map[string]*dynamodb.AttributeValue{key}}, nil)
+	dynamo.On("BatchGetItemWithContext", ctx, mock.Anything, emptyOpts).Return(&dynamodb.BatchGetItemOutput{
+		Responses: map[string][]map[string]*dynamodb.AttributeValue{tableName: {record}},
+	}, nil)
+	dynamo.On("UpdateItemWithContext", ctx, mock.Anything, emptyOpts).Run(func(args mock.Arguments) {
+		in := args.Get(1).(*dynamodb.UpdateItemInput)
+		asserter.Equal(key, in.Key)
+		delete(record, "SocketID")
+		record["Expiration"] = in.ExpressionAttributeValues[":expiration"]
+	}).Return(&dynamodb.UpdateItemOutput{}, nil)
+
+	loader := Loader(func(ctx context.Context, sessionID string) (*CompleteSessionView, error) {
+		return &CompleteSessionView{SessionID: sessionID}, nil
+	})
+	notifier := ChangeNotifier(func(ctx context.Context, updated CompleteSessionView) error {
+		return nil
+	})
+	departure := DepartureNotifier(func(ctx context.Context, sess CompleteSessionView, departed User) error {
+		return nil
+	})
+	_, err := NewDisconnector(dynamo, tableName, indexName, time.Minute, loader, notifier, departure)(ctx, "old")
+	asserter.NoError(err)
+	asserter.NotContains(record, "SocketID")
+	dynamo.AssertNotCalled(t, "BatchWriteItemWithContext", mock.Anything, mock.Anything, mock.Anything)
+
+	dynamo.On("QueryWithContext", ctx, mock.MatchedBy(func(in *dynamodb.QueryInput) bool {
+		return in.IndexName == nil
+	}), emptyOpts).Return(&dynamodb.QueryOutput{Items: []map[string]*dynamodb.AttributeValue{record}}, nil)
+	var written *dynamodb.TransactWriteItemsInput
+	dynamo.On("TransactWriteItemsWithContext", ctx, mock.Anything, emptyOpts).Run(func(args mock.Arguments) {
+		written = args.Get(1).(*dynamodb.TransactWriteItemsInput)
+	}).Return(&dynamodb.TransactWriteItemsOutput{}, nil)
+
+	res, err := NewResumer(dynamo, tableName, time.Hour)(ctx, sessionID, "a", "token", "new")
+	asserter.NoError(err)
+	asserter.Equal(User{UserID: "a", Name: "A", CurrentVote: aws.String("5"), HasVoted: true, SocketID: "new", ResumeToken: "token"}, res)
+	if asserter.NotNil(written) && asserter.Len(written.TransactItems, 2) {
+		asserter.Equal("user:new", *written.TransactItems[0].Put.Item["RangeKey"].S)
+		asserter.Equal("new", *written.TransactItems[0].Put.Item["SocketID"].S)
+		asserter.Equal(key, written.TransactItems[1].Delete.Key)
+	}
+}
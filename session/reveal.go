@@ -0,0 +1,95 @@
+package session
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+// AutoRevealer reloads a session after a vote has been recorded, revealing the votes if the session is configured to
+// do so and everyone who is able to vote has done so. The returned session reflects the state that should be broadcast.
+type AutoRevealer func(ctx context.Context, sessionID string) (*CompleteSessionView, error)
+
+func NewAutoRevealer(dynamo DynamoClient, tableName string, loadSession Loader) AutoRevealer {
+	return func(ctx context.Context, sessionID string) (*CompleteSessionView, error) {
+		// the loader uses consistent reads, so when votes race whichever lands last is guaranteed to see all the others
+		sess, err := loadSession(ctx, sessionID)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if sess == nil || !sess.AutoReveal || sess.VotesShown || !allVotesCast(*sess) {
+			return sess, nil
+		}
+
+		// multiple voters may see everyone as having voted, only flip the flag if nobody else beat us to it
+		_, err = dynamo.UpdateItemWithContext(ctx, &dynamodb.UpdateItemInput{
+			TableName: aws.String(tableName),
+			Key: map[string]*dynamodb.AttributeValue{
+				"SessionID": {S: aws.String(sessionID)},
+				"RangeKey":  {S: aws.String(sessionRecordRangeKeyValue)},
+			},
+			// a reveal timer has nothing left to do once everyone has voted
+			UpdateExpression:    aws.String("SET VotesShown = :shown REMOVE ScheduleShard, ScheduledAt"),
+			ConditionExpression: aws.String("VotesShown = :hidden"),
+			ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+				":shown":  {BOOL: aws.Bool(true)},
+				":hidden": {BOOL: aws.Bool(false)},
+			},
+		})
+		if err != nil {
+			if aerr, ok := err.(awserr.Error); !ok || aerr.Code() != dynamodb.ErrCodeConditionalCheckFailedException {
+				return nil, errors.WithStack(err)
+			}
+			zerolog.Ctx(ctx).Debug().Str("sessionID", sessionID).Msg("votes already revealed by another request")
+		}
+		sess.VotesShown = true
+		return sess, nil
+	}
+}
+
+// VotesShownSaver shows or hides the votes in a session based on sess.VotesShown, touching only the session record
+type VotesShownSaver func(ctx context.Context, sess CompleteSessionView) error
+
+func NewVotesShownSaver(dynamo DynamoClient, tableName string, notifyObservers ChangeNotifier) VotesShownSaver {
+	return func(ctx context.Context, sess CompleteSessionView) error {
+		update := "SET VotesShown = :shown"
+		if sess.VotesShown {
+			update += " REMOVE ScheduleShard, ScheduledAt"
+		}
+		_, err := dynamo.UpdateItemWithContext(ctx, &dynamodb.UpdateItemInput{
+			TableName: aws.String(tableName),
+			Key: map[string]*dynamodb.AttributeValue{
+				"SessionID": {S: aws.String(sess.SessionID)},
+				"RangeKey":  {S: aws.String(sessionRecordRangeKeyValue)},
+			},
+			UpdateExpression:    aws.String(update),
+			ConditionExpression: aws.String("attribute_exists(SessionID)"),
+			ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+				":shown": {BOOL: aws.Bool(sess.VotesShown)},
+			},
+		})
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		return errors.WithStack(notifyObservers(ctx, sess))
+	}
+}
+
+func allVotesCast(s CompleteSessionView) bool {
+	if len(WaitingFor(s)) > 0 {
+		return false
+	}
+	if s.FacilitatorPoints && s.Facilitator.CurrentVote != nil {
+		return true
+	}
+	for _, p := range s.Participants {
+		if p.CurrentVote != nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,221 @@
+package session
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jonsabados/pointypoints/session/testutil"
+)
+
+func Test_NewAutoRevealer(t *testing.T) {
+	sessionID := "abcdefg"
+	tableName := "sessions"
+
+	expectedUpdate := &dynamodb.UpdateItemInput{
+		TableName: aws.String(tableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"SessionID": {S: aws.String(sessionID)},
+			"RangeKey":  {S: aws.String("session")},
+		},
+		UpdateExpression:    aws.String("SET VotesShown = :shown REMOVE ScheduleShard, ScheduledAt"),
+		ConditionExpression: aws.String("VotesShown = :hidden"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":shown":  {BOOL: aws.Bool(true)},
+			":hidden": {BOOL: aws.Bool(false)},
+		},
+	}
+
+	testCases := []struct {
+		name               string
+		loaded             *CompleteSessionView
+		updateError        error
+		expectUpdate       bool
+		expectedVotesShown bool
+		expectedError      string
+	}{
+		{
+			name:   "session vanished",
+			loaded: nil,
+		},
+		{
+			name: "auto reveal disabled",
+			loaded: &CompleteSessionView{
+				SessionID:    sessionID,
+				Participants: []User{{UserID: "a", CurrentVote: aws.String("1")}},
+			},
+		},
+		{
+			name: "votes outstanding",
+			loaded: &CompleteSessionView{
+				SessionID:  sessionID,
+				AutoReveal: true,
+				Participants: []User{
+					{UserID: "a", CurrentVote: aws.String("1")},
+					{UserID: "b"},
+				},
+			},
+		},
+		{
+			name: "facilitator vote outstanding",
+			loaded: &CompleteSessionView{
+				SessionID:         sessionID,
+				AutoReveal:        true,
+				FacilitatorPoints: true,
+				Facilitator:       User{UserID: "f"},
+				Participants:      []User{{UserID: "a", CurrentVote: aws.String("1")}},
+			},
+		},
+		{
+			name: "nobody able to vote",
+			loaded: &CompleteSessionView{
+				SessionID:    sessionID,
+				AutoReveal:   true,
+				Participants: []User{},
+			},
+		},
+		{
+			name: "already shown",
+			loaded: &CompleteSessionView{
+				SessionID:    sessionID,
+				AutoReveal:   true,
+				VotesShown:   true,
+				Participants: []User{{UserID: "a", CurrentVote: aws.String("1")}},
+			},
+			expectedVotesShown: true,
+		},
+		{
+			name: "everyone voted",
+			loaded: &CompleteSessionView{
+				SessionID:         sessionID,
+				AutoReveal:        true,
+				FacilitatorPoints: true,
+				Facilitator:       User{UserID: "f", CurrentVote: aws.String("3")},
+				Participants:      []User{{UserID: "a", CurrentVote: aws.String("1")}},
+			},
+			expectUpdate:       true,
+			expectedVotesShown: true,
+		},
+		{
+			name: "idle voter outstanding",
+			loaded: &CompleteSessionView{
+				SessionID:  sessionID,
+				AutoReveal: true,
+				Participants: []User{
+					{UserID: "a", CurrentVote: aws.String("1")},
+					{UserID: "b", Status: PresenceIdle},
+				},
+			},
+		},
+		{
+			name: "skipping idle voters",
+			loaded: &CompleteSessionView{
+				SessionID:  sessionID,
+				AutoReveal: true,
+				SkipIdle:   true,
+				Participants: []User{
+					{UserID: "a", CurrentVote: aws.String("1")},
+					{UserID: "b", Status: PresenceIdle},
+					{UserID: "c", Status: PresenceAway},
+				},
+			},
+			expectUpdate:       true,
+			expectedVotesShown: true,
+		},
+		{
+			name: "lost race to another voter",
+			loaded: &CompleteSessionView{
+				SessionID:    sessionID,
+				AutoReveal:   true,
+				Participants: []User{{UserID: "a", CurrentVote: aws.String("1")}},
+			},
+			expectUpdate:       true,
+			updateError:        awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "nope", nil),
+			expectedVotesShown: true,
+		},
+		{
+			name: "error updating",
+			loaded: &CompleteSessionView{
+				SessionID:    sessionID,
+				AutoReveal:   true,
+				Participants: []User{{UserID: "a", CurrentVote: aws.String("1")}},
+			},
+			expectUpdate:  true,
+			updateError:   awserr.New(dynamodb.ErrCodeInternalServerError, "kablam", nil),
+			expectedError: "InternalServerError: kablam",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			asserter := assert.New(t)
+
+			inputCtx := testutil.NewTestContext()
+
+			dynamo := &testutil.MockDynamoClient{}
+			if tc.expectUpdate {
+				dynamo.On("UpdateItemWithContext", inputCtx, expectedUpdate, emptyOpts).Return(&dynamodb.UpdateItemOutput{}, tc.updateError)
+			}
+
+			loader := Loader(func(ctx context.Context, id string) (*CompleteSessionView, error) {
+				asserter.Equal(inputCtx, ctx)
+				asserter.Equal(sessionID, id)
+				return tc.loaded, nil
+			})
+
+			res, err := NewAutoRevealer(dynamo, tableName, loader)(inputCtx, sessionID)
+			dynamo.AssertExpectations(t)
+			if tc.expectedError != "" {
+				asserter.EqualError(err, tc.expectedError)
+				return
+			}
+			asserter.NoError(err)
+			if tc.loaded == nil {
+				asserter.Nil(res)
+				return
+			}
+			asserter.Equal(tc.expectedVotesShown, res.VotesShown)
+		})
+	}
+}
+
+func Test_NewVotesShownSaver(t *testing.T) {
+	asserter := assert.New(t)
+
+	inputCtx := testutil.NewTestContext()
+	dynamo := &testutil.MockDynamoClient{}
+	tableName := "sessions"
+	sess := CompleteSessionView{
+		SessionID:    "abcdefg",
+		VotesShown:   true,
+		Participants: []User{{UserID: "a", CurrentVote: aws.String("5"), SocketID: "aaaa"}},
+	}
+
+	dynamo.On("UpdateItemWithContext", inputCtx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(tableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"SessionID": {S: aws.String("abcdefg")},
+			"RangeKey":  {S: aws.String("session")},
+		},
+		UpdateExpression:    aws.String("SET VotesShown = :shown REMOVE ScheduleShard, ScheduledAt"),
+		ConditionExpression: aws.String("attribute_exists(SessionID)"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":shown": {BOOL: aws.Bool(true)},
+		},
+	}, emptyOpts).Return(&dynamodb.UpdateItemOutput{}, nil)
+
+	var notified []CompleteSessionView
+	notifier := ChangeNotifier(func(ctx context.Context, updated CompleteSessionView) error {
+		notified = append(notified, updated)
+		return nil
+	})
+
+	err := NewVotesShownSaver(dynamo, tableName, notifier)(inputCtx, sess)
+	asserter.NoError(err)
+	asserter.Equal([]CompleteSessionView{sess}, notified)
+	dynamo.AssertExpectations(t)
+}
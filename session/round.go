@@ -0,0 +1,330 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/pkg/errors"
+)
+
+type RoundVote struct {
+	UserID string `json:"userId"`
+	Name   string `json:"name,omitempty"`
+	Handle string `json:"handle,omitempty"`
+	Vote   string `json:"vote"`
+	// Rationale is whatever the voter had to say for themselves, if anything
+	Rationale string `json:"rationale,omitempty"`
+}
+
+type RoundResult struct {
+	Round      int         `json:"round"`
+	RecordedAt time.Time   `json:"recordedAt"`
+	Votes      []RoundVote `json:"votes"`
+}
+
+type VoteCount struct {
+	Vote  string `json:"vote"`
+	Count int    `json:"count"`
+}
+
+type RoundDistribution struct {
+	Round int         `json:"round"`
+	Votes []VoteCount `json:"votes"`
+}
+
+// Revoter snapshots the votes of the current round into the session's history, then clears them so the same item can
+// be voted on again
+type Revoter func(ctx context.Context, sess CompleteSessionView) error
+
+func NewRevoter(dynamo DynamoClient, tableName string, sessionExpiration time.Duration) Revoter {
+	return func(ctx context.Context, sess CompleteSessionView) error {
+		now := time.Now()
+		expiration := &dynamodb.AttributeValue{N: aws.String(strconv.FormatInt(now.Add(sessionExpiration).Unix(), 10))}
+
+		result := RoundResult{
+			Round:      sess.Round,
+			RecordedAt: now.UTC().Truncate(time.Second),
+			Votes:      currentRoundVotes(sess),
+		}
+
+		actions := []*dynamodb.TransactWriteItem{
+			{
+				Put: &dynamodb.Put{
+					TableName: aws.String(tableName),
+					Item:      convertRound(sess.SessionID, now, result, expiration),
+				},
+			},
+			{
+				// guards against a double submit snapshotting the same round twice
+				Update: &dynamodb.Update{
+					TableName: aws.String(tableName),
+					Key: map[string]*dynamodb.AttributeValue{
+						"SessionID": {S: aws.String(sess.SessionID)},
+						"RangeKey":  {S: aws.String(sessionRecordRangeKeyValue)},
+					},
+					UpdateExpression:    aws.String("SET #round = :next, VotesShown = :hidden REMOVE TimerStart, TimerDuration, TimerExpiryAction, ScheduleShard, ScheduledAt"),
+					ConditionExpression: aws.String("attribute_not_exists(#round) OR #round = :current"),
+					ExpressionAttributeNames: map[string]*string{
+						"#round": aws.String("Round"),
+					},
+					ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+						":next":    {N: aws.String(strconv.Itoa(sess.Round + 1))},
+						":current": {N: aws.String(strconv.Itoa(sess.Round))},
+						":hidden":  {BOOL: aws.Bool(false)},
+					},
+				},
+			},
+		}
+
+		err := transactInChunks(ctx, dynamo, actions)
+		if err != nil {
+			return errors.Wrap(err, "error starting new round")
+		}
+		return errors.Wrap(clearVotes(ctx, dynamo, tableName, sess), "error clearing votes for new round")
+	}
+}
+
+// VoteClearer moves a session on to the next item, hiding and clearing everyone's votes and resetting the timer and
+// round count along with them. The returned session reflects the cleared state.
+type VoteClearer func(ctx context.Context, sess CompleteSessionView) (CompleteSessionView, error)
+
+func NewVoteClearer(dynamo DynamoClient, tableName string, notifyObservers ChangeNotifier, sessionExpiration time.Duration) VoteClearer {
+	return func(ctx context.Context, sess CompleteSessionView) (CompleteSessionView, error) {
+		now := time.Now()
+		expiration := &dynamodb.AttributeValue{N: aws.String(strconv.FormatInt(now.Add(sessionExpiration).Unix(), 10))}
+
+		// votes get hidden first so that nobody sees a partial reveal while they are being cleared
+		_, err := dynamo.UpdateItemWithContext(ctx, &dynamodb.UpdateItemInput{
+			TableName: aws.String(tableName),
+			Key: map[string]*dynamodb.AttributeValue{
+				"SessionID": {S: aws.String(sess.SessionID)},
+				"RangeKey":  {S: aws.String(sessionRecordRangeKeyValue)},
+			},
+			UpdateExpression:    aws.String("SET #round = :first, VotesShown = :hidden, Expiration = :expiration REMOVE TimerStart, TimerDuration, TimerExpiryAction, ScheduleShard, ScheduledAt"),
+			ConditionExpression: aws.String("attribute_exists(SessionID)"),
+			ExpressionAttributeNames: map[string]*string{
+				"#round": aws.String("Round"),
+			},
+			ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+				":first":      {N: aws.String("1")},
+				":hidden":     {BOOL: aws.Bool(false)},
+				":expiration": expiration,
+			},
+		})
+		if err != nil {
+			return sess, errors.Wrap(err, "error hiding votes")
+		}
+		err = clearVotes(ctx, dynamo, tableName, sess)
+		if err != nil {
+			return sess, errors.Wrap(err, "error clearing votes")
+		}
+
+		cleared := Clone(sess)
+		cleared.VotesShown = false
+		cleared.Timer = nil
+		cleared.Round = 1
+		cleared.Facilitator.CurrentVote = nil
+		cleared.Facilitator.Confidence = ""
+		cleared.Facilitator.Rationale = ""
+		cleared.Facilitator.HasVoted = false
+		for i := range cleared.Participants {
+			cleared.Participants[i].CurrentVote = nil
+			cleared.Participants[i].Confidence = ""
+			cleared.Participants[i].Rationale = ""
+			cleared.Participants[i].HasVoted = false
+		}
+		recordActivity(ctx, dynamo, tableName, sess.SessionID, "", now, sessionExpiration)
+		return cleared, errors.WithStack(notifyObservers(ctx, cleared))
+	}
+}
+
+// clearVotes removes the votes of everyone in the session, along with any left behind by participants who have since
+// disconnected. Each record is updated on its own so that one vanishing part way through, say to the reaper, doesn't
+// hold up clearing everyone else.
+func clearVotes(ctx context.Context, dynamo DynamoClient, tableName string, sess CompleteSessionView) error {
+	if sess.Facilitator.CurrentVote != nil {
+		err := clearVote(ctx, dynamo, tableName, sess.SessionID, sess.Facilitator, Facilitator)
+		if err != nil {
+			return err
+		}
+	}
+	for _, p := range sess.Participants {
+		if p.CurrentVote != nil {
+			err := clearVote(ctx, dynamo, tableName, sess.SessionID, p, Participant)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	released, err := releasedVoters(ctx, dynamo, tableName, sess.SessionID)
+	if err != nil {
+		return err
+	}
+	for _, p := range released {
+		err = clearVote(ctx, dynamo, tableName, sess.SessionID, p, Participant)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func clearVote(ctx context.Context, dynamo DynamoClient, tableName string, sessionID string, u User, userType UserType) error {
+	_, err := dynamo.UpdateItemWithContext(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(tableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"SessionID": {S: aws.String(sessionID)},
+			"RangeKey":  userRangeKey(u.SocketID, userType),
+		},
+		UpdateExpression: aws.String("REMOVE CurrentVote, Confidence, Rationale"),
+		// don't resurrect a half baked record for someone who has disconnected
+		ConditionExpression: aws.String("attribute_exists(SessionID)"),
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+			return nil
+		}
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// releasedVoters finds participants who disconnected with a vote in and haven't resumed yet. The loader leaves them out
+// of the session, but their votes still need clearing so they don't come back into a later round with them.
+func releasedVoters(ctx context.Context, dynamo DynamoClient, tableName string, sessionID string) ([]User, error) {
+	records, err := queryAll(ctx, dynamo, &dynamodb.QueryInput{
+		TableName: aws.String(tableName),
+		KeyConditions: map[string]*dynamodb.Condition{
+			"SessionID": {
+				ComparisonOperator: aws.String("EQ"),
+				AttributeValueList: []*dynamodb.AttributeValue{
+					{S: aws.String(sessionID)},
+				},
+			},
+			"RangeKey": {
+				ComparisonOperator: aws.String("BEGINS_WITH"),
+				AttributeValueList: []*dynamodb.AttributeValue{
+					{S: aws.String(participantRecordRangeKeyPrefix)},
+				},
+			},
+		},
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading released participants")
+	}
+	ret := make([]User, 0)
+	for _, r := range records {
+		if _, connected := r["SocketID"]; connected {
+			continue
+		}
+		if _, voted := r["CurrentVote"]; voted {
+			ret = append(ret, readUser(r))
+		}
+	}
+	return ret, nil
+}
+
+func currentRoundVotes(s CompleteSessionView) []RoundVote {
+	ret := make([]RoundVote, 0, len(s.Participants)+1)
+	addVote := func(u User) {
+		if u.CurrentVote != nil {
+			ret = append(ret, RoundVote{
+				UserID:    u.UserID,
+				Name:      u.Name,
+				Handle:    u.Handle,
+				Vote:      *u.CurrentVote,
+				Rationale: u.Rationale,
+			})
+		}
+	}
+	if s.FacilitatorPoints {
+		addVote(s.Facilitator)
+	}
+	for _, p := range s.Participants {
+		addVote(p)
+	}
+	return ret
+}
+
+func previousRoundDistribution(s CompleteSessionView) *RoundDistribution {
+	if len(s.RoundHistory) == 0 {
+		return nil
+	}
+	last := s.RoundHistory[len(s.RoundHistory)-1]
+	// history from an earlier item in the session isn't useful for comparison
+	if last.Round != s.Round-1 {
+		return nil
+	}
+	counts := make(map[string]int)
+	for _, v := range last.Votes {
+		counts[v.Vote]++
+	}
+	votes := make([]VoteCount, 0, len(counts))
+	for vote, count := range counts {
+		votes = append(votes, VoteCount{Vote: vote, Count: count})
+	}
+	sort.Slice(votes, func(i, j int) bool {
+		if votes[i].Count != votes[j].Count {
+			return votes[i].Count > votes[j].Count
+		}
+		return votes[i].Vote < votes[j].Vote
+	})
+	return &RoundDistribution{
+		Round: last.Round,
+		Votes: votes,
+	}
+}
+
+func convertRound(sessionID string, recordedAt time.Time, r RoundResult, expiration *dynamodb.AttributeValue) map[string]*dynamodb.AttributeValue {
+	votes := make([]*dynamodb.AttributeValue, len(r.Votes))
+	for i, v := range r.Votes {
+		vote := map[string]*dynamodb.AttributeValue{
+			"UserID": {S: aws.String(v.UserID)},
+			"Name":   {S: aws.String(v.Name)},
+			"Handle": {S: aws.String(v.Handle)},
+			"Vote":   {S: aws.String(v.Vote)},
+		}
+		if v.Rationale != "" {
+			vote["Rationale"] = &dynamodb.AttributeValue{S: aws.String(v.Rationale)}
+		}
+		votes[i] = &dynamodb.AttributeValue{M: vote}
+	}
+	return map[string]*dynamodb.AttributeValue{
+		"SessionID": {S: aws.String(sessionID)},
+		// zero padded nanos keep the history in chronological order when queried
+		"RangeKey":   {S: aws.String(fmt.Sprintf("%s%020d", roundRecordRangeKeyPrefix, recordedAt.UnixNano()))},
+		"Round":      {N: aws.String(strconv.Itoa(r.Round))},
+		"RecordedAt": {N: aws.String(strconv.FormatInt(r.RecordedAt.Unix(), 10))},
+		"Votes":      {L: votes},
+		"Expiration": expiration,
+	}
+}
+
+func readRound(item map[string]*dynamodb.AttributeValue) RoundResult {
+	round, _ := strconv.Atoi(*item["Round"].N)
+	recordedAt, _ := strconv.ParseInt(*item["RecordedAt"].N, 10, 64)
+	votes := make([]RoundVote, len(item["Votes"].L))
+	for i, v := range item["Votes"].L {
+		votes[i] = RoundVote{
+			UserID: *v.M["UserID"].S,
+			Name:   *v.M["Name"].S,
+			Handle: *v.M["Handle"].S,
+			Vote:   *v.M["Vote"].S,
+		}
+		if r, ok := v.M["Rationale"]; ok {
+			votes[i].Rationale = *r.S
+		}
+	}
+	return RoundResult{
+		Round:      round,
+		RecordedAt: time.Unix(recordedAt, 0).UTC(),
+		Votes:      votes,
+	}
+}
@@ -0,0 +1,176 @@
+package session
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/jonsabados/pointypoints/session/testutil"
+)
+
+func releasedRecords(sessionID string) []map[string]*dynamodb.AttributeValue {
+	expiration := &dynamodb.AttributeValue{N: aws.String("1000")}
+	active := convertUser(sessionID, Participant, User{UserID: "a", CurrentVote: aws.String("5"), SocketID: "aaaa"}, expiration)
+	released := convertUser(sessionID, Participant, User{UserID: "c", CurrentVote: aws.String("8"), SocketID: "cccc"}, expiration)
+	delete(released, "SocketID")
+	silent := convertUser(sessionID, Participant, User{UserID: "d", SocketID: "dddd"}, expiration)
+	delete(silent, "SocketID")
+	return []map[string]*dynamodb.AttributeValue{active, released, silent}
+}
+
+func Test_NewRevoter(t *testing.T) {
+	asserter := assert.New(t)
+
+	inputCtx := testutil.NewTestContext()
+	dynamo := &testutil.MockDynamoClient{}
+	tableName := "sessions"
+
+	sess := CompleteSessionView{
+		SessionID:         "abcdefg",
+		VotesShown:        true,
+		FacilitatorPoints: true,
+		Round:             2,
+		Facilitator:       User{UserID: "f", Name: "Fred", CurrentVote: aws.String("3"), SocketID: "facilitator"},
+		Participants: []User{
+			{UserID: "a", Handle: "A", CurrentVote: aws.String("5"), Rationale: "auth is hard", SocketID: "aaaa"},
+			{UserID: "b", Handle: "B", SocketID: "bbbb"},
+		},
+	}
+
+	var written *dynamodb.TransactWriteItemsInput
+	dynamo.On("QueryWithContext", inputCtx, mock.MatchedBy(func(in *dynamodb.QueryInput) bool {
+		return *in.KeyConditions["RangeKey"].AttributeValueList[0].S == "user:" && *in.ConsistentRead
+	}), emptyOpts).Return(&dynamodb.QueryOutput{Items: releasedRecords("abcdefg")}, nil)
+	dynamo.On("TransactWriteItemsWithContext", inputCtx, mock.Anything, emptyOpts).Run(func(args mock.Arguments) {
+		written = args.Get(1).(*dynamodb.TransactWriteItemsInput)
+	}).Return(&dynamodb.TransactWriteItemsOutput{}, nil)
+	cleared := expectVoteClears(dynamo, inputCtx)
+
+	err := NewRevoter(dynamo, tableName, time.Hour)(inputCtx, sess)
+	asserter.NoError(err)
+	dynamo.AssertExpectations(t)
+
+	if asserter.Len(written.TransactItems, 2) {
+		round := readRound(written.TransactItems[0].Put.Item)
+		asserter.Equal(2, round.Round)
+		asserter.Equal([]RoundVote{
+			{UserID: "f", Name: "Fred", Vote: "3"},
+			{UserID: "a", Handle: "A", Vote: "5", Rationale: "auth is hard"},
+		}, round.Votes)
+
+		sessionUpdate := written.TransactItems[1].Update
+		asserter.Equal("3", *sessionUpdate.ExpressionAttributeValues[":next"].N)
+		asserter.Equal("2", *sessionUpdate.ExpressionAttributeValues[":current"].N)
+	}
+	asserter.Equal([]string{"facilitator", "user:aaaa", "user:cccc"}, *cleared)
+}
+
+// expectVoteClears records the range keys of every vote cleared, with user:aaaa having vanished part way through
+func expectVoteClears(dynamo *testutil.MockDynamoClient, ctx context.Context) *[]string {
+	cleared := make([]string, 0)
+	isClear := func(in *dynamodb.UpdateItemInput) bool {
+		return *in.UpdateExpression == "REMOVE CurrentVote, Confidence, Rationale"
+	}
+	dynamo.On("UpdateItemWithContext", ctx, mock.MatchedBy(func(in *dynamodb.UpdateItemInput) bool {
+		return isClear(in) && *in.Key["RangeKey"].S == "user:aaaa"
+	}), emptyOpts).Run(func(args mock.Arguments) {
+		cleared = append(cleared, "user:aaaa")
+	}).Return(&dynamodb.UpdateItemOutput{}, awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "gone", nil))
+	dynamo.On("UpdateItemWithContext", ctx, mock.MatchedBy(isClear), emptyOpts).Run(func(args mock.Arguments) {
+		cleared = append(cleared, *args.Get(1).(*dynamodb.UpdateItemInput).Key["RangeKey"].S)
+	}).Return(&dynamodb.UpdateItemOutput{}, nil)
+	return &cleared
+}
+
+func Test_previousRoundDistribution(t *testing.T) {
+	asserter := assert.New(t)
+
+	history := []RoundResult{
+		{Round: 1, Votes: []RoundVote{{UserID: "a", Vote: "8"}}},
+		{Round: 2, Votes: []RoundVote{
+			{UserID: "a", Vote: "5"},
+			{UserID: "b", Vote: "8"},
+			{UserID: "c", Vote: "3"},
+			{UserID: "d", Vote: "8"},
+		}},
+	}
+
+	asserter.Equal(&RoundDistribution{
+		Round: 2,
+		Votes: []VoteCount{
+			{Vote: "8", Count: 2},
+			{Vote: "3", Count: 1},
+			{Vote: "5", Count: 1},
+		},
+	}, previousRoundDistribution(CompleteSessionView{Round: 3, RoundHistory: history}))
+
+	asserter.Nil(previousRoundDistribution(CompleteSessionView{Round: 1, RoundHistory: history}), "history from an earlier item should be ignored")
+	asserter.Nil(previousRoundDistribution(CompleteSessionView{Round: 1}))
+}
+
+func Test_NewVoteClearer(t *testing.T) {
+	asserter := assert.New(t)
+
+	inputCtx := testutil.NewTestContext()
+	dynamo := &testutil.MockDynamoClient{}
+	tableName := "sessions"
+
+	sess := CompleteSessionView{
+		SessionID:   "abcdefg",
+		VotesShown:  true,
+		Round:       3,
+		Timer:       &RoundTimer{DurationSeconds: 30},
+		Facilitator: User{UserID: "f", CurrentVote: aws.String("3"), HasVoted: true, SocketID: "facilitator"},
+		Participants: []User{
+			{UserID: "a", CurrentVote: aws.String("5"), HasVoted: true, SocketID: "aaaa"},
+			{UserID: "b", SocketID: "bbbb"},
+		},
+	}
+
+	dynamo.On("QueryWithContext", inputCtx, mock.MatchedBy(func(in *dynamodb.QueryInput) bool {
+		return *in.KeyConditions["RangeKey"].AttributeValueList[0].S == "user:" && *in.ConsistentRead
+	}), emptyOpts).Return(&dynamodb.QueryOutput{Items: releasedRecords("abcdefg")}, nil)
+	clearedVotes := expectVoteClears(dynamo, inputCtx)
+	var sessionUpdate *dynamodb.UpdateItemInput
+	dynamo.On("UpdateItemWithContext", inputCtx, mock.MatchedBy(func(in *dynamodb.UpdateItemInput) bool {
+		return *in.Key["RangeKey"].S == "session"
+	}), emptyOpts).Run(func(args mock.Arguments) {
+		sessionUpdate = args.Get(1).(*dynamodb.UpdateItemInput)
+	}).Return(&dynamodb.UpdateItemOutput{}, nil)
+	dynamo.On("UpdateItemWithContext", inputCtx, mock.Anything, emptyOpts).Return(&dynamodb.UpdateItemOutput{}, nil)
+
+	var notified []CompleteSessionView
+	notifier := ChangeNotifier(func(ctx context.Context, updated CompleteSessionView) error {
+		notified = append(notified, updated)
+		return nil
+	})
+
+	cleared, err := NewVoteClearer(dynamo, tableName, notifier, time.Hour)(inputCtx, sess)
+	asserter.NoError(err)
+
+	expected := CompleteSessionView{
+		SessionID:   "abcdefg",
+		Round:       1,
+		Facilitator: User{UserID: "f", SocketID: "facilitator"},
+		Participants: []User{
+			{UserID: "a", SocketID: "aaaa"},
+			{UserID: "b", SocketID: "bbbb"},
+		},
+	}
+	asserter.Equal(expected, cleared)
+	asserter.Equal([]CompleteSessionView{expected}, notified)
+	asserter.Equal(aws.String("5"), sess.Participants[0].CurrentVote)
+
+	if asserter.NotNil(sessionUpdate) {
+		asserter.Equal("1", *sessionUpdate.ExpressionAttributeValues[":first"].N)
+		asserter.False(*sessionUpdate.ExpressionAttributeValues[":hidden"].BOOL)
+	}
+	asserter.Equal([]string{"facilitator", "user:aaaa", "user:cccc"}, *clearedVotes)
+	dynamo.AssertNotCalled(t, "TransactWriteItemsWithContext", mock.Anything, mock.Anything, mock.Anything)
+}
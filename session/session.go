@@ -3,6 +3,7 @@ package session
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"strconv"
 	"strings"
 	"time"
@@ -30,6 +31,7 @@ const (
 	facilitatorRecordRangeKeyValue  = "facilitator"
 	participantRecordRangeKeyPrefix = "user:"
 	watcherRecordRangeKeyPrefix     = "watcher:"
+	roundRecordRangeKeyPrefix       = "round:"
 )
 
 var ErrorSessionNotFound = errors.New("session not found")
@@ -39,6 +41,13 @@ type JoinSessionRequest struct {
 	Name         string `json:"name,omitempty"`
 	Handle       string `json:"handle,omitempty"`
 	ConnectionID string `json:"connectionId"`
+	// ResumeToken is the token handed out by an earlier join, when present and still valid the existing participant
+	// is moved over to the new connection instead of joining all over again
+	ResumeToken string `json:"resumeToken,omitempty"`
+}
+
+type JoinSessionResponse struct {
+	ResumeToken string `json:"resumeToken"`
 }
 
 type User struct {
@@ -46,13 +55,32 @@ type User struct {
 	Name        string  `json:"name,omitempty"`
 	Handle      string  `json:"handle,omitempty"`
 	CurrentVote *string `json:"currentVote,omitempty"`
-	SocketID    string  `json:"-"`
+	// Confidence is shown and hidden right along with CurrentVote
+	Confidence Confidence `json:"confidence,omitempty"`
+	Rationale  string     `json:"rationale,omitempty"`
+	// always present, even when the vote itself is hidden, so participants can see who is still deciding
+	HasVoted    bool   `json:"hasVoted"`
+	SocketID    string `json:"-"`
+	ResumeToken string `json:"-"`
+	// Status is worked out from LastActive when the user is loaded
+	Status     PresenceStatus `json:"status,omitempty"`
+	LastActive time.Time      `json:"-"`
 }
 
 type StartRequest struct {
-	Facilitator       User   `json:"facilitator"`
-	FacilitatorPoints bool   `json:"facilitatorPoints"`
-	ConnectionID      string `json:"connectionId"`
+	Facilitator User `json:"facilitator"`
+	// FacilitatorPoints falls back to the team default, or false, when not provided
+	FacilitatorPoints        *bool    `json:"facilitatorPoints,omitempty"`
+	AutoReveal               bool     `json:"autoReveal"`
+	AnonymousReveal          bool     `json:"anonymousReveal"`
+	AnonymizeFacilitatorView bool     `json:"anonymizeFacilitatorView"`
+	ConnectionID             string   `json:"connectionId"`
+	TeamID                   string   `json:"teamId,omitempty"`
+	Deck                     []string `json:"deck,omitempty"`
+	// Type defaults to an estimation session when not provided
+	Type SessionType `json:"type,omitempty"`
+	// Async starts a session that participants vote in on their own time, rather than all together
+	Async *AsyncRequest `json:"async,omitempty"`
 }
 
 type SetFacilitatorSessionRequest struct {
@@ -67,8 +95,13 @@ type WatchSessionRequest struct {
 	ConnectionID string `json:"connectionId"`
 }
 
+// MaxRationaleLen keeps vote rationale to a sentence or two, longer discussions belong in chat
+const MaxRationaleLen = 280
+
 type VoteRequest struct {
-	Vote string `json:"vote"`
+	Vote       string     `json:"vote"`
+	Confidence Confidence `json:"confidence,omitempty"`
+	Rationale  string     `json:"rationale,omitempty"`
 }
 
 type ShowVotesRequest struct {
@@ -76,34 +109,96 @@ type ShowVotesRequest struct {
 	FacilitatorSessionKey string `json:"facilitatorSessionKey"`
 }
 
+// UpdateRequest changes session settings, anything left out of the request is left as is
 type UpdateRequest struct {
-	VotesShown        bool `json:"votesShown"`
-	FacilitatorPoints bool `json:"facilitatorPoints"`
+	VotesShown               *bool   `json:"votesShown,omitempty"`
+	FacilitatorPoints        *bool   `json:"facilitatorPoints,omitempty"`
+	AutoReveal               *bool   `json:"autoReveal,omitempty"`
+	AnonymousReveal          *bool   `json:"anonymousReveal,omitempty"`
+	AnonymizeFacilitatorView *bool   `json:"anonymizeFacilitatorView,omitempty"`
+	SkipIdle                 *bool   `json:"skipIdle,omitempty"`
+	CurrentStory             *string `json:"currentStory,omitempty"`
+}
+
+// Apply copies the settings present in the request onto the session
+func (r UpdateRequest) Apply(sess *CompleteSessionView) {
+	applyBool := func(from *bool, to *bool) {
+		if from != nil {
+			*to = *from
+		}
+	}
+	applyBool(r.VotesShown, &sess.VotesShown)
+	applyBool(r.FacilitatorPoints, &sess.FacilitatorPoints)
+	applyBool(r.AutoReveal, &sess.AutoReveal)
+	applyBool(r.AnonymousReveal, &sess.AnonymousReveal)
+	applyBool(r.AnonymizeFacilitatorView, &sess.AnonymizeFacilitatorView)
+	applyBool(r.SkipIdle, &sess.SkipIdle)
+	if r.CurrentStory != nil {
+		sess.CurrentStory = *r.CurrentStory
+	}
 }
 
 type CompleteSessionView struct {
-	SessionID             string `json:"sessionId"`
-	VotesShown            bool   `json:"votesShown"`
-	FacilitatorSessionKey string `json:"facilitatorSessionKey,omitempty"`
-	Facilitator           User   `json:"facilitator"`
-	FacilitatorPoints     bool   `json:"facilitatorPoints"`
-	Participants          []User `json:"participants"`
+	SessionID                string              `json:"sessionId"`
+	Type                     SessionType         `json:"type"`
+	VotesShown               bool                `json:"votesShown"`
+	FacilitatorSessionKey    string              `json:"facilitatorSessionKey,omitempty"`
+	Facilitator              User                `json:"facilitator"`
+	FacilitatorPoints        bool                `json:"facilitatorPoints"`
+	AutoReveal               bool                `json:"autoReveal"`
+	AnonymousReveal          bool                `json:"anonymousReveal"`
+	AnonymizeFacilitatorView bool                `json:"anonymizeFacilitatorView"`
+	SkipIdle                 bool                `json:"skipIdle"`
+	Timer                    *RoundTimer         `json:"timer,omitempty"`
+	Round                    int                 `json:"round"`
+	Participants             []User              `json:"participants"`
+	RevealedVotes            []string            `json:"revealedVotes,omitempty"`
+	Stats                    *VoteStats          `json:"stats,omitempty"`
+	RoundHistory             []RoundResult       `json:"roundHistory,omitempty"`
+	Stories                  []Story             `json:"stories,omitempty"`
+	CurrentStory             string              `json:"currentStory,omitempty"`
+	IntegrationMessage       *IntegrationMessage `json:"-"`
+	Chat                     []ChatEntry         `json:"chat,omitempty"`
+	Banner                   *Announcement       `json:"banner,omitempty"`
+	TeamID                   string              `json:"teamId,omitempty"`
+	Deck                     []string            `json:"deck,omitempty"`
+	// Deadline is only set for async sessions, votes are revealed once it passes
+	Deadline   *time.Time  `json:"deadline,omitempty"`
+	AsyncVotes []AsyncVote `json:"asyncVotes,omitempty"`
 }
 
 type ParticipantSessionView struct {
-	SessionID         string `json:"sessionId"`
-	VotesShown        bool   `json:"votesShown"`
-	Facilitator       User   `json:"facilitator"`
-	FacilitatorPoints bool   `json:"facilitatorPoints"`
-	Participants      []User `json:"participants"`
+	SessionID         string             `json:"sessionId"`
+	Type              SessionType        `json:"type"`
+	VotesShown        bool               `json:"votesShown"`
+	Facilitator       User               `json:"facilitator"`
+	FacilitatorPoints bool               `json:"facilitatorPoints"`
+	AutoReveal        bool               `json:"autoReveal"`
+	AnonymousReveal   bool               `json:"anonymousReveal"`
+	SkipIdle          bool               `json:"skipIdle"`
+	Timer             *RoundTimer        `json:"timer,omitempty"`
+	Round             int                `json:"round"`
+	Participants      []User             `json:"participants"`
+	RevealedVotes     []string           `json:"revealedVotes,omitempty"`
+	Stats             *VoteStats         `json:"stats,omitempty"`
+	PreviousRound     *RoundDistribution `json:"previousRound,omitempty"`
+	CurrentStory      *Story             `json:"currentStory,omitempty"`
+	Chat              []ChatEntry        `json:"chat,omitempty"`
+	Banner            *Announcement      `json:"banner,omitempty"`
+	TeamID            string             `json:"teamId,omitempty"`
+	Deck              []string           `json:"deck,omitempty"`
 }
 
 type DynamoClient interface {
 	GetItemWithContext(ctx aws.Context, input *dynamodb.GetItemInput, opts ...request.Option) (*dynamodb.GetItemOutput, error)
 	QueryWithContext(ctx aws.Context, input *dynamodb.QueryInput, opts ...request.Option) (*dynamodb.QueryOutput, error)
+	ScanWithContext(ctx aws.Context, input *dynamodb.ScanInput, opts ...request.Option) (*dynamodb.ScanOutput, error)
 	PutItemWithContext(ctx aws.Context, input *dynamodb.PutItemInput, opts ...request.Option) (*dynamodb.PutItemOutput, error)
 	TransactWriteItemsWithContext(ctx aws.Context, input *dynamodb.TransactWriteItemsInput, opts ...request.Option) (*dynamodb.TransactWriteItemsOutput, error)
 	DeleteItemWithContext(ctx aws.Context, input *dynamodb.DeleteItemInput, opts ...request.Option) (*dynamodb.DeleteItemOutput, error)
+	UpdateItemWithContext(ctx aws.Context, input *dynamodb.UpdateItemInput, opts ...request.Option) (*dynamodb.UpdateItemOutput, error)
+	BatchGetItemWithContext(ctx aws.Context, input *dynamodb.BatchGetItemInput, opts ...request.Option) (*dynamodb.BatchGetItemOutput, error)
+	BatchWriteItemWithContext(ctx aws.Context, input *dynamodb.BatchWriteItemInput, opts ...request.Option) (*dynamodb.BatchWriteItemOutput, error)
 }
 
 func ToParticipantView(s CompleteSessionView, connectionID string) ParticipantSessionView {
@@ -111,25 +206,67 @@ func ToParticipantView(s CompleteSessionView, connectionID string) ParticipantSe
 	for i, u := range s.Participants {
 		participants[i] = participantUserView(s, u, connectionID)
 	}
-	return ParticipantSessionView{
+	ret := ParticipantSessionView{
 		SessionID:         s.SessionID,
+		Type:              s.Type,
 		VotesShown:        s.VotesShown,
 		Facilitator:       participantUserView(s, s.Facilitator, connectionID),
 		FacilitatorPoints: s.FacilitatorPoints,
+		AutoReveal:        s.AutoReveal,
+		AnonymousReveal:   s.AnonymousReveal,
+		SkipIdle:          s.SkipIdle,
+		Timer:             s.Timer,
+		Round:             s.Round,
 		Participants:      participants,
+		Stats:             RevealStats(s),
+		PreviousRound:     previousRoundDistribution(s),
+		CurrentStory:      CurrentStory(s),
+		Chat:              s.Chat,
+		Banner:            s.Banner,
+		TeamID:            s.TeamID,
+		Deck:              s.Deck,
 	}
+	if s.VotesShown && s.AnonymousReveal {
+		ret.RevealedVotes = shuffledVotes(s)
+	}
+	return ret
 }
 
 func participantUserView(s CompleteSessionView, u User, connectionID string) User {
 	ret := User{
-		UserID: u.UserID,
-		Handle: u.Handle,
+		UserID:   u.UserID,
+		Handle:   u.Handle,
+		HasVoted: u.CurrentVote != nil,
+		Status:   u.Status,
 	}
 	if u.Handle == "" {
 		ret.Name = u.Name
 	}
-	if s.VotesShown || u.SocketID == connectionID {
+	if (s.VotesShown && !s.AnonymousReveal) || u.SocketID == connectionID {
 		ret.CurrentVote = u.CurrentVote
+		ret.Confidence = u.Confidence
+		ret.Rationale = u.Rationale
+	}
+	return ret
+}
+
+// ValidateStart checks a request to start a session, handing back anything wrong with it
+func ValidateStart(r *StartRequest, now time.Time) []string {
+	ret := make([]string, 0)
+	if r.Facilitator.Name == "" {
+		ret = append(ret, "facilitator name is required")
+	}
+	if r.Facilitator.UserID == "" {
+		ret = append(ret, "facilitator user id is required")
+	}
+	if r.ConnectionID == "" {
+		ret = append(ret, "connection id is required")
+	}
+	if !r.Type.Valid() {
+		ret = append(ret, "unknown session type")
+	}
+	if r.Async != nil {
+		ret = append(ret, ValidateAsync(r.Async, now)...)
 	}
 	return ret
 }
@@ -141,22 +278,35 @@ func NewStarter(dynamo DynamoClient, sessionTableName string, sessionExpiration
 		sessionID := uuid.New().String()
 		facilitatorSessionKey := uuid.New().String()
 
-		expiration := &dynamodb.AttributeValue{N: aws.String(strconv.FormatInt(time.Now().Add(sessionExpiration).Unix(), 10))}
+		now := time.Now()
+		expiration := &dynamodb.AttributeValue{N: aws.String(strconv.FormatInt(now.Add(sessionExpiration).Unix(), 10))}
+
+		ret := CompleteSessionView{
+			SessionID:                sessionID,
+			FacilitatorSessionKey:    facilitatorSessionKey,
+			Type:                     toStart.Type,
+			Facilitator:              toStart.Facilitator,
+			FacilitatorPoints:        toStart.FacilitatorPoints != nil && *toStart.FacilitatorPoints,
+			AutoReveal:               toStart.AutoReveal,
+			AnonymousReveal:          toStart.AnonymousReveal,
+			AnonymizeFacilitatorView: toStart.AnonymizeFacilitatorView,
+			Round:                    1,
+			Participants:             make([]User, 0),
+			TeamID:                   toStart.TeamID,
+			Deck:                     toStart.Deck,
+		}
+		if ret.Type == "" {
+			ret.Type = SessionTypeEstimate
+		}
+		if toStart.Async != nil {
+			ret.Deadline = &toStart.Async.Deadline
+			ret.Stories = toStart.Async.Stories
+			expiration = asyncExpiration(ret, expiration)
+		}
 
 		sessionPut := &dynamodb.Put{
 			TableName: aws.String(sessionTableName),
-			Item: map[string]*dynamodb.AttributeValue{
-				"SessionID":             {S: aws.String(sessionID)},
-				"RangeKey":              {S: aws.String(sessionRecordRangeKeyValue)},
-				"VotesShown":            {BOOL: aws.Bool(false)},
-				"FacilitatorSessionKey": {S: aws.String(facilitatorSessionKey)},
-				"FacilitatorPoints":     {BOOL: aws.Bool(toStart.FacilitatorPoints)},
-				// duplicating facilitator info so it can be resurrected in the event of a reload without having to have the client keep track
-				"FacilitatorName":   {S: aws.String(toStart.Facilitator.Name)},
-				"FacilitatorHandle": {S: aws.String(toStart.Facilitator.Handle)},
-				"FacilitatorUserID": {S: aws.String(toStart.Facilitator.UserID)},
-				"Expiration":        expiration,
-			},
+			Item:      convertSession(ret, expiration),
 		}
 
 		facilitatorPut := &dynamodb.Put{
@@ -177,53 +327,40 @@ func NewStarter(dynamo DynamoClient, sessionTableName string, sessionExpiration
 				},
 			},
 		})
-		return CompleteSessionView{
-			SessionID:             sessionID,
-			FacilitatorSessionKey: facilitatorSessionKey,
-			Facilitator:           toStart.Facilitator,
-			FacilitatorPoints:     toStart.FacilitatorPoints,
-			Participants:          make([]User, 0),
-		}, errors.WithStack(err)
+		if err != nil {
+			return ret, errors.WithStack(err)
+		}
+		recordHistory(ctx, dynamo, sessionTableName, sessionID, toStart.Facilitator.UserID, HistoryRoleFacilitator, now, sessionExpiration)
+		return ret, nil
 	}
 }
 
-type Saver func(ctx context.Context, toSave CompleteSessionView) error
+// Saver writes the changes made to a loaded session. Only records that differ from original are written so that
+// updating session settings doesn't clobber votes landing at the same time, or rewrite every participant.
+type Saver func(ctx context.Context, original CompleteSessionView, toSave CompleteSessionView) error
 
 func NewSaver(dynamo DynamoClient, tableName string, notifyObservers ChangeNotifier, sessionExpiration time.Duration) Saver {
-	return func(ctx context.Context, toSave CompleteSessionView) error {
+	return func(ctx context.Context, original CompleteSessionView, toSave CompleteSessionView) error {
 		expiration := &dynamodb.AttributeValue{N: aws.String(strconv.FormatInt(time.Now().Add(sessionExpiration).Unix(), 10))}
 
-		sessionPut := &dynamodb.Put{
-			TableName: aws.String(tableName),
-			Item: map[string]*dynamodb.AttributeValue{
-				"SessionID":             {S: aws.String(toSave.SessionID)},
-				"RangeKey":              {S: aws.String(sessionRecordRangeKeyValue)},
-				"VotesShown":            {BOOL: aws.Bool(toSave.VotesShown)},
-				"FacilitatorSessionKey": {S: aws.String(toSave.FacilitatorSessionKey)},
-				"FacilitatorPoints":     {BOOL: aws.Bool(toSave.FacilitatorPoints)},
-				// duplicating facilitator info so it can be resurrected in the event of a reload without having to have the client keep track
-				"FacilitatorName":   {S: aws.String(toSave.Facilitator.Name)},
-				"FacilitatorHandle": {S: aws.String(toSave.Facilitator.Handle)},
-				"FacilitatorUserID": {S: aws.String(toSave.Facilitator.UserID)},
-				"Expiration":        {N: aws.String(strconv.FormatInt(time.Now().Add(sessionExpiration).Unix(), 10))},
-			},
-		}
-
-		facilitatorPut := &dynamodb.Put{
-			TableName: aws.String(tableName),
-			Item:      convertUser(toSave.SessionID, Facilitator, toSave.Facilitator, expiration),
+		var transactItems []*dynamodb.TransactWriteItem
+		if !reflect.DeepEqual(original.Facilitator, toSave.Facilitator) {
+			transactItems = append(transactItems, &dynamodb.TransactWriteItem{
+				Put: &dynamodb.Put{
+					TableName: aws.String(tableName),
+					Item:      convertUser(toSave.SessionID, Facilitator, toSave.Facilitator, expiration),
+				},
+			})
 		}
 
-		transactItems := []*dynamodb.TransactWriteItem{
-			{
-				Put: sessionPut,
-			},
-			{
-				Put: facilitatorPut,
-			},
+		originalParticipants := make(map[string]User, len(original.Participants))
+		for _, u := range original.Participants {
+			originalParticipants[u.SocketID] = u
 		}
-
 		for _, u := range toSave.Participants {
+			if prev, ok := originalParticipants[u.SocketID]; ok && reflect.DeepEqual(prev, u) {
+				continue
+			}
 			transactItems = append(transactItems, &dynamodb.TransactWriteItem{
 				Put: &dynamodb.Put{
 					TableName: aws.String(tableName),
@@ -232,21 +369,107 @@ func NewSaver(dynamo DynamoClient, tableName string, notifyObservers ChangeNotif
 			})
 		}
 
-		_, err := dynamo.TransactWriteItemsWithContext(ctx, &dynamodb.TransactWriteItemsInput{
-			TransactItems: transactItems,
+		// the session record goes last, if a save has to be split up it is what makes the change visible as a whole
+		transactItems = append(transactItems, &dynamodb.TransactWriteItem{
+			Put: &dynamodb.Put{
+				TableName: aws.String(tableName),
+				Item:      convertSession(toSave, expiration),
+			},
 		})
+
+		err := transactInChunks(ctx, dynamo, transactItems)
 		if err != nil {
-			return errors.WithStack(err)
+			return err
 		}
+		recordActivity(ctx, dynamo, tableName, toSave.SessionID, "", time.Now(), sessionExpiration)
 		return errors.WithStack(notifyObservers(ctx, toSave))
 	}
 }
 
+// SettingsSaver writes the settings present in an update request to the session record. Only those attributes are
+// touched, so rounds, timers, banners and anything else landing on the session record at the same time are left be.
+// sess is the session with the update already applied, it is what observers are notified with.
+type SettingsSaver func(ctx context.Context, sess CompleteSessionView, r UpdateRequest) error
+
+func NewSettingsSaver(dynamo DynamoClient, tableName string, notifyObservers ChangeNotifier, sessionExpiration time.Duration) SettingsSaver {
+	return func(ctx context.Context, sess CompleteSessionView, r UpdateRequest) error {
+		now := time.Now()
+		expiration := &dynamodb.AttributeValue{N: aws.String(strconv.FormatInt(now.Add(sessionExpiration).Unix(), 10))}
+		set := []string{"Expiration = :expiration"}
+		var remove []string
+		values := map[string]*dynamodb.AttributeValue{
+			":expiration": asyncExpiration(sess, expiration),
+		}
+		setBool := func(attribute string, value *bool) {
+			if value != nil {
+				set = append(set, fmt.Sprintf("%s = :%s", attribute, attribute))
+				values[":"+attribute] = &dynamodb.AttributeValue{BOOL: value}
+			}
+		}
+		setBool("VotesShown", r.VotesShown)
+		setBool("FacilitatorPoints", r.FacilitatorPoints)
+		setBool("AutoReveal", r.AutoReveal)
+		setBool("AnonymousReveal", r.AnonymousReveal)
+		setBool("AnonymizeFacilitatorView", r.AnonymizeFacilitatorView)
+		setBool("SkipIdle", r.SkipIdle)
+		if r.CurrentStory != nil {
+			if *r.CurrentStory == "" {
+				remove = append(remove, "CurrentStory")
+			} else {
+				set = append(set, "CurrentStory = :CurrentStory")
+				values[":CurrentStory"] = &dynamodb.AttributeValue{S: r.CurrentStory}
+			}
+		}
+		// once revealed there is nothing left for a reveal timer or async deadline to do
+		if r.VotesShown != nil && *r.VotesShown {
+			remove = append(remove, "ScheduleShard", "ScheduledAt")
+		}
+
+		update := "SET " + strings.Join(set, ", ")
+		if len(remove) > 0 {
+			update += " REMOVE " + strings.Join(remove, ", ")
+		}
+		_, err := dynamo.UpdateItemWithContext(ctx, &dynamodb.UpdateItemInput{
+			TableName: aws.String(tableName),
+			Key: map[string]*dynamodb.AttributeValue{
+				"SessionID": {S: aws.String(sess.SessionID)},
+				"RangeKey":  {S: aws.String(sessionRecordRangeKeyValue)},
+			},
+			UpdateExpression:          aws.String(update),
+			ConditionExpression:       aws.String("attribute_exists(SessionID)"),
+			ExpressionAttributeValues: values,
+		})
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		recordActivity(ctx, dynamo, tableName, sess.SessionID, "", now, sessionExpiration)
+		return errors.WithStack(notifyObservers(ctx, sess))
+	}
+}
+
+// Clone copies a session so that the copy can be modified without affecting the original, which is what a Saver
+// needs to work out what changed
+func Clone(s CompleteSessionView) CompleteSessionView {
+	ret := s
+	if s.Participants != nil {
+		ret.Participants = make([]User, len(s.Participants))
+		copy(ret.Participants, s.Participants)
+	}
+	ret.Stories = append([]Story(nil), s.Stories...)
+	ret.RoundHistory = append([]RoundResult(nil), s.RoundHistory...)
+	ret.Deck = append([]string(nil), s.Deck...)
+	ret.Chat = append([]ChatEntry(nil), s.Chat...)
+	ret.AsyncVotes = append([]AsyncVote(nil), s.AsyncVotes...)
+	return ret
+}
+
 type JoinSaver func(ctx context.Context, initiator goauth.Principal, sessionID string, user User, userType UserType) error
 
 func NewJoinSaver(dynamo DynamoClient, tableName string, sessionExpiration time.Duration, sf *profile.StatsUpdateFactory) JoinSaver {
 	return func(ctx context.Context, initiator goauth.Principal, sessionID string, user User, userType UserType) error {
-		expiration := &dynamodb.AttributeValue{N: aws.String(strconv.FormatInt(time.Now().Add(sessionExpiration).Unix(), 10))}
+		now := time.Now()
+		expiration := &dynamodb.AttributeValue{N: aws.String(strconv.FormatInt(now.Add(sessionExpiration).Unix(), 10))}
+		user.LastActive = now
 
 		actions := []*dynamodb.TransactWriteItem{
 			{
@@ -266,8 +489,16 @@ func NewJoinSaver(dynamo DynamoClient, tableName string, sessionExpiration time.
 		_, err := dynamo.TransactWriteItemsWithContext(ctx, &dynamodb.TransactWriteItemsInput{
 			TransactItems: actions,
 		})
+		if err != nil {
+			return errors.Wrap(err, "error writing user record to dynamo")
+		}
 
-		return errors.Wrap(err, "error writing user record to dynamo")
+		role := HistoryRoleParticipant
+		if userType == Facilitator {
+			role = HistoryRoleFacilitator
+		}
+		recordHistory(ctx, dynamo, tableName, sessionID, user.UserID, role, time.Now(), sessionExpiration)
+		return nil
 	}
 }
 
@@ -275,7 +506,9 @@ type VoteRecorder func(ctx context.Context, initiator goauth.Principal, sessionI
 
 func NewVoteRecorder(dynamo DynamoClient, tableName string, sessionExpiration time.Duration, sf *profile.StatsUpdateFactory) VoteRecorder {
 	return func(ctx context.Context, initiator goauth.Principal, sessionID string, user User, userType UserType) error {
-		expiration := &dynamodb.AttributeValue{N: aws.String(strconv.FormatInt(time.Now().Add(sessionExpiration).Unix(), 10))}
+		now := time.Now()
+		expiration := &dynamodb.AttributeValue{N: aws.String(strconv.FormatInt(now.Add(sessionExpiration).Unix(), 10))}
+		user.LastActive = now
 
 		actions := []*dynamodb.TransactWriteItem{
 			{
@@ -292,8 +525,12 @@ func NewVoteRecorder(dynamo DynamoClient, tableName string, sessionExpiration ti
 		_, err := dynamo.TransactWriteItemsWithContext(ctx, &dynamodb.TransactWriteItemsInput{
 			TransactItems: actions,
 		})
+		if err != nil {
+			return errors.Wrap(err, "error recording vote")
+		}
 
-		return errors.Wrap(err, "error recording vote")
+		recordActivity(ctx, dynamo, tableName, sessionID, "", now, sessionExpiration)
+		return nil
 	}
 }
 
@@ -301,7 +538,7 @@ type Loader func(ctx context.Context, sessionID string) (*CompleteSessionView, e
 
 func NewLoader(dynamo DynamoClient, tableName string) Loader {
 	return func(ctx context.Context, sessionID string) (*CompleteSessionView, error) {
-		res, err := dynamo.QueryWithContext(ctx, &dynamodb.QueryInput{
+		items, err := queryAll(ctx, dynamo, &dynamodb.QueryInput{
 			TableName: aws.String(tableName),
 			KeyConditions: map[string]*dynamodb.Condition{
 				"SessionID": {
@@ -311,13 +548,15 @@ func NewLoader(dynamo DynamoClient, tableName string) Loader {
 					},
 				},
 			},
+			// sessions are frequently read right after a write (votes, joins etc) and acted upon so make sure we see it
+			ConsistentRead: aws.Bool(true),
 		})
 
 		if err != nil {
-			return nil, errors.WithStack(err)
+			return nil, err
 		}
 
-		if *res.Count == 0 {
+		if len(items) == 0 {
 			return nil, nil
 		}
 
@@ -326,25 +565,55 @@ func NewLoader(dynamo DynamoClient, tableName string) Loader {
 		ret := &CompleteSessionView{
 			Participants: make([]User, 0),
 		}
-		for _, item := range res.Items {
+		for _, item := range items {
 			rangeKey := *item["RangeKey"].S
 			if rangeKey == sessionRecordRangeKeyValue {
 				ret.SessionID = *item["SessionID"].S
+				ret.Type = readSessionType(item)
 				ret.VotesShown = *item["VotesShown"].BOOL
 				ret.FacilitatorSessionKey = *item["FacilitatorSessionKey"].S
 				ret.FacilitatorPoints = *item["FacilitatorPoints"].BOOL
 				ret.Facilitator.Name = *item["FacilitatorName"].S
 				ret.Facilitator.Handle = *item["FacilitatorHandle"].S
 				ret.Facilitator.UserID = *item["FacilitatorUserID"].S
+				ret.AutoReveal = readOptionalBool(item, "AutoReveal")
+				ret.AnonymousReveal = readOptionalBool(item, "AnonymousReveal")
+				ret.AnonymizeFacilitatorView = readOptionalBool(item, "AnonymizeFacilitatorView")
+				ret.SkipIdle = readOptionalBool(item, "SkipIdle")
+				ret.Timer = readTimer(item)
+				ret.Round = 1
+				if round, ok := item["Round"]; ok {
+					ret.Round, _ = strconv.Atoi(*round.N)
+				}
+				ret.Stories, ret.CurrentStory = readStories(item)
+				ret.IntegrationMessage = readIntegrationMessage(item)
+				ret.TeamID, ret.Deck = readTeamSettings(item)
+				ret.Banner = readBanner(item)
+				ret.Deadline = readAsyncDeadline(item)
 			} else if rangeKey == facilitatorRecordRangeKeyValue {
 				ret.Facilitator = readUser(item)
 			} else if strings.HasPrefix(rangeKey, participantRecordRangeKeyPrefix) {
-				ret.Participants = append(ret.Participants, readUser(item))
-			} else if !strings.HasPrefix(rangeKey, watcherRecordRangeKeyPrefix) {
+				// participants without a socket have disconnected and are only being held on to in case they resume
+				if item["SocketID"] != nil {
+					ret.Participants = append(ret.Participants, readUser(item))
+				}
+			} else if strings.HasPrefix(rangeKey, roundRecordRangeKeyPrefix) {
+				ret.RoundHistory = append(ret.RoundHistory, readRound(item))
+			} else if strings.HasPrefix(rangeKey, chatRecordRangeKeyPrefix) {
+				ret.Chat = append(ret.Chat, readChatEntry(item))
+			} else if strings.HasPrefix(rangeKey, asyncVoteRecordRangeKeyPrefix) {
+				ret.AsyncVotes = append(ret.AsyncVotes, readAsyncVote(item))
+			} else if !strings.HasPrefix(rangeKey, watcherRecordRangeKeyPrefix) && !strings.HasPrefix(rangeKey, historyRecordRangeKeyPrefix) &&
+				!strings.HasPrefix(rangeKey, rateLimitRecordRangeKeyPrefix) && rangeKey != summaryRecordRangeKeyValue {
 				zerolog.Ctx(ctx).Warn().Interface("record", item).Msg("unexpected record spotted")
 			}
 		}
 
+		// history & summary records outlive the session itself
+		if ret.SessionID == "" {
+			return nil, nil
+		}
+		ret.Participants = dedupeParticipants(ret.Participants)
 		return ret, nil
 	}
 }
@@ -360,6 +629,34 @@ func userRangeKey(connectionID string, userType UserType) *dynamodb.AttributeVal
 	}
 }
 
+func convertSession(s CompleteSessionView, expiration *dynamodb.AttributeValue) map[string]*dynamodb.AttributeValue {
+	ret := map[string]*dynamodb.AttributeValue{
+		"SessionID":                {S: aws.String(s.SessionID)},
+		"RangeKey":                 {S: aws.String(sessionRecordRangeKeyValue)},
+		"VotesShown":               {BOOL: aws.Bool(s.VotesShown)},
+		"FacilitatorSessionKey":    {S: aws.String(s.FacilitatorSessionKey)},
+		"FacilitatorPoints":        {BOOL: aws.Bool(s.FacilitatorPoints)},
+		"AutoReveal":               {BOOL: aws.Bool(s.AutoReveal)},
+		"AnonymousReveal":          {BOOL: aws.Bool(s.AnonymousReveal)},
+		"AnonymizeFacilitatorView": {BOOL: aws.Bool(s.AnonymizeFacilitatorView)},
+		"SkipIdle":                 {BOOL: aws.Bool(s.SkipIdle)},
+		"Round":                    {N: aws.String(strconv.Itoa(s.Round))},
+		// duplicating facilitator info so it can be resurrected in the event of a reload without having to have the client keep track
+		"FacilitatorName":   {S: aws.String(s.Facilitator.Name)},
+		"FacilitatorHandle": {S: aws.String(s.Facilitator.Handle)},
+		"FacilitatorUserID": {S: aws.String(s.Facilitator.UserID)},
+		"Expiration":        expiration,
+	}
+	convertTimer(s, ret)
+	convertStories(s, ret)
+	convertIntegrationMessage(s, ret)
+	convertTeamSettings(s, ret)
+	convertBanner(s, ret)
+	convertSessionType(s, ret)
+	convertAsyncDeadline(s, ret)
+	return ret
+}
+
 func convertUser(sessionID string, userType UserType, u User, expiration *dynamodb.AttributeValue) map[string]*dynamodb.AttributeValue {
 	ret := map[string]*dynamodb.AttributeValue{
 		"SessionID":  {S: aws.String(sessionID)},
@@ -373,18 +670,55 @@ func convertUser(sessionID string, userType UserType, u User, expiration *dynamo
 	if u.CurrentVote != nil {
 		ret["CurrentVote"] = &dynamodb.AttributeValue{S: u.CurrentVote}
 	}
+	if u.Confidence != "" {
+		ret["Confidence"] = &dynamodb.AttributeValue{S: aws.String(string(u.Confidence))}
+	}
+	if u.Rationale != "" {
+		ret["Rationale"] = &dynamodb.AttributeValue{S: aws.String(u.Rationale)}
+	}
+	if u.ResumeToken != "" {
+		ret["ResumeToken"] = &dynamodb.AttributeValue{S: aws.String(u.ResumeToken)}
+	}
+	if !u.LastActive.IsZero() {
+		ret["LastActive"] = &dynamodb.AttributeValue{N: aws.String(strconv.FormatInt(u.LastActive.Unix(), 10))}
+	}
 	return ret
 }
 
+// settings added after the fact won't be present on older sessions
+func readOptionalBool(item map[string]*dynamodb.AttributeValue, name string) bool {
+	if v, ok := item[name]; ok && v.BOOL != nil {
+		return *v.BOOL
+	}
+	return false
+}
+
 func readUser(r map[string]*dynamodb.AttributeValue) User {
 	ret := User{
-		UserID:   *r["UserID"].S,
-		Name:     *r["Name"].S,
-		Handle:   *r["Handle"].S,
-		SocketID: *r["SocketID"].S,
+		UserID: *r["UserID"].S,
+		Name:   *r["Name"].S,
+		Handle: *r["Handle"].S,
+	}
+	if r["SocketID"] != nil {
+		ret.SocketID = *r["SocketID"].S
+	} else {
+		// released on disconnect, the range key still says which connection the record was for
+		ret.SocketID = strings.TrimPrefix(*r["RangeKey"].S, participantRecordRangeKeyPrefix)
 	}
 	if r["CurrentVote"] != nil {
 		ret.CurrentVote = r["CurrentVote"].S
+		ret.HasVoted = true
+	}
+	if r["Confidence"] != nil {
+		ret.Confidence = Confidence(*r["Confidence"].S)
+	}
+	if r["Rationale"] != nil {
+		ret.Rationale = *r["Rationale"].S
+	}
+	if r["ResumeToken"] != nil {
+		ret.ResumeToken = *r["ResumeToken"].S
 	}
+	ret.LastActive = readLastActive(r)
+	ret.Status = Presence(ret, time.Now())
 	return ret
 }
@@ -0,0 +1,287 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/jonsabados/pointypoints/session/testutil"
+)
+
+func Test_ValidateStart(t *testing.T) {
+	now := time.Unix(1000, 0)
+
+	testCases := []struct {
+		desc           string
+		input          StartRequest
+		expectedErrors []string
+	}{
+		{
+			desc:           "happy path",
+			input:          StartRequest{Facilitator: User{UserID: "f", Name: "Fred"}, ConnectionID: "abc"},
+			expectedErrors: []string{},
+		},
+		{
+			desc:  "nothing provided",
+			input: StartRequest{Type: SessionType("poker")},
+			expectedErrors: []string{
+				"facilitator name is required",
+				"facilitator user id is required",
+				"connection id is required",
+				"unknown session type",
+			},
+		},
+		{
+			desc: "async checked",
+			input: StartRequest{
+				Facilitator:  User{UserID: "f", Name: "Fred"},
+				ConnectionID: "abc",
+				Async:        &AsyncRequest{Deadline: now, Stories: []Story{{Key: "PP-1", Title: "Login"}}},
+			},
+			expectedErrors: []string{"deadline must be in the future"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			assert.Equal(t, tc.expectedErrors, ValidateStart(&tc.input, now))
+		})
+	}
+}
+
+func Test_NewSaver_OnlyWritesChanges(t *testing.T) {
+	asserter := assert.New(t)
+
+	inputCtx := testutil.NewTestContext()
+	dynamo := &testutil.MockDynamoClient{}
+	tableName := "sessions"
+
+	original := CompleteSessionView{
+		SessionID:   "abcdefg",
+		Facilitator: User{UserID: "f", Name: "Fred", SocketID: "facilitator"},
+		Participants: []User{
+			{UserID: "a", Handle: "A", SocketID: "aaaa"},
+			{UserID: "b", Handle: "B", CurrentVote: aws.String("3"), HasVoted: true, SocketID: "bbbb"},
+		},
+	}
+	toSave := Clone(original)
+	toSave.VotesShown = true
+	toSave.Participants[0].CurrentVote = aws.String("5")
+	toSave.Participants[0].HasVoted = true
+	toSave.Participants = append(toSave.Participants, User{UserID: "c", SocketID: "cccc"})
+
+	var written []*dynamodb.TransactWriteItemsInput
+	dynamo.On("TransactWriteItemsWithContext", inputCtx, mock.Anything, emptyOpts).Run(func(args mock.Arguments) {
+		written = append(written, args.Get(1).(*dynamodb.TransactWriteItemsInput))
+	}).Return(&dynamodb.TransactWriteItemsOutput{}, nil)
+	dynamo.On("UpdateItemWithContext", inputCtx, mock.Anything, emptyOpts).Return(&dynamodb.UpdateItemOutput{}, nil)
+
+	var notified []CompleteSessionView
+	notifier := ChangeNotifier(func(ctx context.Context, updated CompleteSessionView) error {
+		notified = append(notified, updated)
+		return nil
+	})
+
+	err := NewSaver(dynamo, tableName, notifier, time.Hour)(inputCtx, original, toSave)
+	asserter.NoError(err)
+	asserter.Equal([]CompleteSessionView{toSave}, notified)
+	asserter.Nil(original.Participants[0].CurrentVote)
+
+	if asserter.Len(written, 1) && asserter.Len(written[0].TransactItems, 3) {
+		items := written[0].TransactItems
+		asserter.Equal("user:aaaa", *items[0].Put.Item["RangeKey"].S)
+		asserter.Equal("5", *items[0].Put.Item["CurrentVote"].S)
+		asserter.Equal("user:cccc", *items[1].Put.Item["RangeKey"].S)
+		asserter.Equal("session", *items[2].Put.Item["RangeKey"].S)
+		asserter.True(*items[2].Put.Item["VotesShown"].BOOL)
+	}
+}
+
+func Test_NewSaver_ChunksLargeSaves(t *testing.T) {
+	asserter := assert.New(t)
+
+	inputCtx := testutil.NewTestContext()
+	dynamo := &testutil.MockDynamoClient{}
+
+	original := CompleteSessionView{
+		SessionID:   "abcdefg",
+		Facilitator: User{UserID: "f", SocketID: "facilitator"},
+	}
+	toSave := Clone(original)
+	toSave.Facilitator.Name = "Fred"
+	for i := 0; i < 150; i++ {
+		toSave.Participants = append(toSave.Participants, User{UserID: fmt.Sprintf("u%d", i), SocketID: fmt.Sprintf("s%d", i)})
+	}
+
+	var written []*dynamodb.TransactWriteItemsInput
+	dynamo.On("TransactWriteItemsWithContext", inputCtx, mock.Anything, emptyOpts).Run(func(args mock.Arguments) {
+		written = append(written, args.Get(1).(*dynamodb.TransactWriteItemsInput))
+	}).Return(&dynamodb.TransactWriteItemsOutput{}, nil)
+	dynamo.On("UpdateItemWithContext", inputCtx, mock.Anything, emptyOpts).Return(&dynamodb.UpdateItemOutput{}, nil)
+
+	notifier := ChangeNotifier(func(ctx context.Context, updated CompleteSessionView) error {
+		return nil
+	})
+
+	err := NewSaver(dynamo, "sessions", notifier, time.Hour)(inputCtx, original, toSave)
+	asserter.NoError(err)
+	if asserter.Len(written, 2) {
+		asserter.Len(written[0].TransactItems, 100)
+		asserter.Equal("facilitator", *written[0].TransactItems[0].Put.Item["RangeKey"].S)
+		if asserter.Len(written[1].TransactItems, 52) {
+			asserter.Equal("session", *written[1].TransactItems[51].Put.Item["RangeKey"].S)
+		}
+	}
+}
+
+func Test_NewSaver_StopsOnFailedChunk(t *testing.T) {
+	asserter := assert.New(t)
+
+	inputCtx := testutil.NewTestContext()
+	dynamo := &testutil.MockDynamoClient{}
+
+	original := CompleteSessionView{SessionID: "abcdefg"}
+	toSave := Clone(original)
+	for i := 0; i < 120; i++ {
+		toSave.Participants = append(toSave.Participants, User{UserID: fmt.Sprintf("u%d", i), SocketID: fmt.Sprintf("s%d", i)})
+	}
+
+	dynamo.On("TransactWriteItemsWithContext", inputCtx, mock.Anything, emptyOpts).Return(nil, fmt.Errorf("kablam")).Once()
+
+	notifier := ChangeNotifier(func(ctx context.Context, updated CompleteSessionView) error {
+		asserter.Fail("nobody should be notified of a failed save")
+		return nil
+	})
+
+	err := NewSaver(dynamo, "sessions", notifier, time.Hour)(inputCtx, original, toSave)
+	asserter.Error(err)
+	dynamo.AssertNumberOfCalls(t, "TransactWriteItemsWithContext", 1)
+}
+
+func Test_NewSettingsSaver(t *testing.T) {
+	deadline := time.Now().Add(time.Hour)
+	testCases := []struct {
+		desc               string
+		sess               CompleteSessionView
+		request            UpdateRequest
+		expectedExpression string
+		expectedValues     []string
+	}{
+		{
+			desc:               "reveal",
+			sess:               CompleteSessionView{SessionID: "abcdefg", VotesShown: true},
+			request:            UpdateRequest{VotesShown: aws.Bool(true)},
+			expectedExpression: "SET Expiration = :expiration, VotesShown = :VotesShown REMOVE ScheduleShard, ScheduledAt",
+			expectedValues:     []string{":expiration", ":VotesShown"},
+		},
+		{
+			desc:               "hide",
+			sess:               CompleteSessionView{SessionID: "abcdefg"},
+			request:            UpdateRequest{VotesShown: aws.Bool(false)},
+			expectedExpression: "SET Expiration = :expiration, VotesShown = :VotesShown",
+			expectedValues:     []string{":expiration", ":VotesShown"},
+		},
+		{
+			desc:               "settings and story",
+			sess:               CompleteSessionView{SessionID: "abcdefg"},
+			request:            UpdateRequest{SkipIdle: aws.Bool(false), AutoReveal: aws.Bool(true), CurrentStory: aws.String("s1")},
+			expectedExpression: "SET Expiration = :expiration, AutoReveal = :AutoReveal, SkipIdle = :SkipIdle, CurrentStory = :CurrentStory",
+			expectedValues:     []string{":expiration", ":AutoReveal", ":SkipIdle", ":CurrentStory"},
+		},
+		{
+			desc:               "story cleared",
+			sess:               CompleteSessionView{SessionID: "abcdefg"},
+			request:            UpdateRequest{CurrentStory: aws.String("")},
+			expectedExpression: "SET Expiration = :expiration REMOVE CurrentStory",
+			expectedValues:     []string{":expiration"},
+		},
+		{
+			desc:               "async reveal",
+			sess:               CompleteSessionView{SessionID: "abcdefg", VotesShown: true, Deadline: &deadline},
+			request:            UpdateRequest{VotesShown: aws.Bool(true)},
+			expectedExpression: "SET Expiration = :expiration, VotesShown = :VotesShown REMOVE ScheduleShard, ScheduledAt",
+			expectedValues:     []string{":expiration", ":VotesShown"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			asserter := assert.New(t)
+
+			inputCtx := testutil.NewTestContext()
+			dynamo := &testutil.MockDynamoClient{}
+			tableName := "sessions"
+
+			var updates []*dynamodb.UpdateItemInput
+			dynamo.On("UpdateItemWithContext", inputCtx, mock.Anything, emptyOpts).Run(func(args mock.Arguments) {
+				updates = append(updates, args.Get(1).(*dynamodb.UpdateItemInput))
+			}).Return(&dynamodb.UpdateItemOutput{}, nil)
+
+			var notified []CompleteSessionView
+			notifier := ChangeNotifier(func(ctx context.Context, updated CompleteSessionView) error {
+				notified = append(notified, updated)
+				return nil
+			})
+
+			err := NewSettingsSaver(dynamo, tableName, notifier, time.Hour)(inputCtx, tc.sess, tc.request)
+			asserter.NoError(err)
+			asserter.Equal([]CompleteSessionView{tc.sess}, notified)
+			dynamo.AssertNotCalled(t, "TransactWriteItemsWithContext", mock.Anything, mock.Anything, mock.Anything)
+			dynamo.AssertNotCalled(t, "PutItemWithContext", mock.Anything, mock.Anything, mock.Anything)
+
+			// the second update is the summary activity record
+			if asserter.Len(updates, 2) {
+				update := updates[0]
+				asserter.Equal("session", *update.Key["RangeKey"].S)
+				asserter.Equal("abcdefg", *update.Key["SessionID"].S)
+				asserter.Equal(tc.expectedExpression, *update.UpdateExpression)
+				asserter.Equal("attribute_exists(SessionID)", *update.ConditionExpression)
+				var keys []string
+				for k := range update.ExpressionAttributeValues {
+					keys = append(keys, k)
+				}
+				asserter.ElementsMatch(tc.expectedValues, keys)
+			}
+		})
+	}
+}
+
+func Test_Clone(t *testing.T) {
+	asserter := assert.New(t)
+
+	original := CompleteSessionView{
+		SessionID:    "abcdefg",
+		Participants: []User{{UserID: "a"}},
+		Stories:      []Story{{Key: "1"}},
+		Deck:         []string{"1", "2"},
+	}
+	cloned := Clone(original)
+	asserter.Equal(original, cloned)
+
+	cloned.Participants[0].CurrentVote = aws.String("5")
+	cloned.Stories[0].Estimate = "5"
+	cloned.Deck[0] = "0"
+	asserter.Nil(original.Participants[0].CurrentVote)
+	asserter.Equal("", original.Stories[0].Estimate)
+	asserter.Equal("1", original.Deck[0])
+
+	asserter.Equal([]User{}, Clone(CompleteSessionView{Participants: []User{}}).Participants)
+}
+
+func Test_UpdateRequest_Apply(t *testing.T) {
+	asserter := assert.New(t)
+
+	sess := CompleteSessionView{AutoReveal: true, AnonymousReveal: true, SkipIdle: true, CurrentStory: "PP-1"}
+	UpdateRequest{VotesShown: aws.Bool(true), SkipIdle: aws.Bool(false)}.Apply(&sess)
+	asserter.Equal(CompleteSessionView{VotesShown: true, AutoReveal: true, AnonymousReveal: true, CurrentStory: "PP-1"}, sess)
+
+	UpdateRequest{}.Apply(&sess)
+	asserter.Equal(CompleteSessionView{VotesShown: true, AutoReveal: true, AnonymousReveal: true, CurrentStory: "PP-1"}, sess)
+
+	UpdateRequest{CurrentStory: aws.String("PP-2")}.Apply(&sess)
+	asserter.Equal("PP-2", sess.CurrentStory)
+}
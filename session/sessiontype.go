@@ -0,0 +1,102 @@
+package session
+
+import (
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// SessionType tells clients what kind of voting is going on, and so which UI to show
+type SessionType string
+
+const (
+	SessionTypeEstimate = SessionType("estimate")
+	// SessionTypeFistOfFive has everyone show zero to five fingers, anything under three is a concern
+	SessionTypeFistOfFive = SessionType("fist-of-five")
+	// SessionTypeThumbs is roman voting, thumbs up, sideways or down
+	SessionTypeThumbs = SessionType("thumbs")
+)
+
+var (
+	FistOfFiveDeck = []string{"0", "1", "2", "3", "4", "5"}
+	ThumbsDeck     = []string{"up", "sideways", "down"}
+)
+
+const fistOfFiveSupportThreshold = 3
+
+// Valid reports if the type is known, blank is valid and means an estimation session
+func (t SessionType) Valid() bool {
+	switch t {
+	case "", SessionTypeEstimate, SessionTypeFistOfFive, SessionTypeThumbs:
+		return true
+	default:
+		return false
+	}
+}
+
+// Poll reports if the session is a quick agreement poll rather than an estimate
+func (t SessionType) Poll() bool {
+	return t == SessionTypeFistOfFive || t == SessionTypeThumbs
+}
+
+// ValidVote checks a vote against what the session's type allows. Estimates accept anything since teams bring their
+// own cards, and blank votes are always allowed since that is how votes get taken back.
+func ValidVote(s CompleteSessionView, vote string) bool {
+	if vote == "" || !s.Type.Poll() {
+		return true
+	}
+	for _, c := range SessionDeck(s) {
+		if c == vote {
+			return true
+		}
+	}
+	return false
+}
+
+// pollStats fills in the counts and whether the poll reached consensus, an estimate has neither
+func pollStats(s CompleteSessionView, stats *VoteStats) {
+	if !s.Type.Poll() {
+		return
+	}
+	stats.Counts = make(map[string]int)
+	consensus := stats.Votes > 0
+	countVote := func(u User) {
+		if u.CurrentVote == nil {
+			return
+		}
+		stats.Counts[*u.CurrentVote]++
+		switch s.Type {
+		case SessionTypeFistOfFive:
+			fingers, err := strconv.Atoi(*u.CurrentVote)
+			if err != nil || fingers < fistOfFiveSupportThreshold {
+				consensus = false
+			}
+		case SessionTypeThumbs:
+			if *u.CurrentVote == "down" {
+				consensus = false
+			}
+		}
+	}
+	if s.FacilitatorPoints {
+		countVote(s.Facilitator)
+	}
+	for _, p := range s.Participants {
+		countVote(p)
+	}
+	stats.Consensus = &consensus
+}
+
+func convertSessionType(s CompleteSessionView, item map[string]*dynamodb.AttributeValue) {
+	if s.Type != "" {
+		item["SessionType"] = &dynamodb.AttributeValue{S: aws.String(string(s.Type))}
+	}
+}
+
+// sessions from before types were a thing are all estimates
+func readSessionType(item map[string]*dynamodb.AttributeValue) SessionType {
+	if t, ok := item["SessionType"]; ok {
+		return SessionType(*t.S)
+	}
+	return SessionTypeEstimate
+}
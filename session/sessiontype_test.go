@@ -0,0 +1,184 @@
+package session
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_SessionType_Valid(t *testing.T) {
+	asserter := assert.New(t)
+
+	asserter.True(SessionType("").Valid())
+	asserter.True(SessionTypeEstimate.Valid())
+	asserter.True(SessionTypeFistOfFive.Valid())
+	asserter.True(SessionTypeThumbs.Valid())
+	asserter.False(SessionType("dot-voting").Valid())
+}
+
+func Test_ValidVote(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		input    CompleteSessionView
+		vote     string
+		expected bool
+	}{
+		{
+			desc:     "estimates take anything",
+			input:    CompleteSessionView{Type: SessionTypeEstimate},
+			vote:     "∞",
+			expected: true,
+		},
+		{
+			desc:     "fingers",
+			input:    CompleteSessionView{Type: SessionTypeFistOfFive, Deck: []string{"S", "M"}},
+			vote:     "4",
+			expected: true,
+		},
+		{
+			desc:     "too many fingers",
+			input:    CompleteSessionView{Type: SessionTypeFistOfFive},
+			vote:     "6",
+			expected: false,
+		},
+		{
+			desc:     "thumbs",
+			input:    CompleteSessionView{Type: SessionTypeThumbs},
+			vote:     "sideways",
+			expected: true,
+		},
+		{
+			desc:     "points in a thumbs session",
+			input:    CompleteSessionView{Type: SessionTypeThumbs},
+			vote:     "3",
+			expected: false,
+		},
+		{
+			desc:     "taking a vote back",
+			input:    CompleteSessionView{Type: SessionTypeThumbs},
+			vote:     "",
+			expected: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			assert.Equal(t, tc.expected, ValidVote(tc.input, tc.vote))
+		})
+	}
+}
+
+func Test_RevealStats_Polls(t *testing.T) {
+	yes := true
+	no := false
+	float := func(f float64) *float64 {
+		return &f
+	}
+
+	testCases := []struct {
+		desc     string
+		input    CompleteSessionView
+		expected *VoteStats
+	}{
+		{
+			desc: "fist of five in agreement",
+			input: CompleteSessionView{
+				Type:       SessionTypeFistOfFive,
+				VotesShown: true,
+				Participants: []User{
+					{CurrentVote: aws.String("3")},
+					{CurrentVote: aws.String("5")},
+					{},
+				},
+			},
+			expected: &VoteStats{
+				Votes:           2,
+				Average:         float(4),
+				WeightedAverage: float(4),
+				Counts:          map[string]int{"3": 1, "5": 1},
+				Consensus:       &yes,
+			},
+		},
+		{
+			desc: "fist of five with a concern",
+			input: CompleteSessionView{
+				Type:              SessionTypeFistOfFive,
+				VotesShown:        true,
+				FacilitatorPoints: true,
+				Facilitator:       User{CurrentVote: aws.String("2")},
+				Participants:      []User{{CurrentVote: aws.String("4")}},
+			},
+			expected: &VoteStats{
+				Votes:           2,
+				Average:         float(3),
+				WeightedAverage: float(3),
+				Counts:          map[string]int{"2": 1, "4": 1},
+				Consensus:       &no,
+			},
+		},
+		{
+			desc: "thumbs with someone on the fence",
+			input: CompleteSessionView{
+				Type:       SessionTypeThumbs,
+				VotesShown: true,
+				Participants: []User{
+					{CurrentVote: aws.String("up")},
+					{CurrentVote: aws.String("up")},
+					{CurrentVote: aws.String("sideways")},
+				},
+			},
+			expected: &VoteStats{
+				Votes:     3,
+				Counts:    map[string]int{"up": 2, "sideways": 1},
+				Consensus: &yes,
+			},
+		},
+		{
+			desc: "thumbs down",
+			input: CompleteSessionView{
+				Type:       SessionTypeThumbs,
+				VotesShown: true,
+				Participants: []User{
+					{CurrentVote: aws.String("up")},
+					{CurrentVote: aws.String("down")},
+				},
+			},
+			expected: &VoteStats{
+				Votes:     2,
+				Counts:    map[string]int{"up": 1, "down": 1},
+				Consensus: &no,
+			},
+		},
+		{
+			desc: "nobody voted",
+			input: CompleteSessionView{
+				Type:       SessionTypeThumbs,
+				VotesShown: true,
+			},
+			expected: &VoteStats{
+				Counts:    map[string]int{},
+				Consensus: &no,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			assert.Equal(t, tc.expected, RevealStats(tc.input))
+		})
+	}
+}
+
+func Test_SessionTypeRoundTrip(t *testing.T) {
+	asserter := assert.New(t)
+
+	item := make(map[string]*dynamodb.AttributeValue)
+	convertSessionType(CompleteSessionView{}, item)
+	asserter.Empty(item)
+	asserter.Equal(SessionTypeEstimate, readSessionType(item), "older sessions are estimates")
+
+	convertSessionType(CompleteSessionView{Type: SessionTypeThumbs}, item)
+	asserter.Equal(SessionTypeThumbs, readSessionType(item))
+}
@@ -0,0 +1,102 @@
+package session
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+type Story struct {
+	Key      string `json:"key"`
+	Title    string `json:"title"`
+	URL      string `json:"url,omitempty"`
+	Source   string `json:"source"`
+	Estimate string `json:"estimate,omitempty"`
+}
+
+type ImportStoriesRequest struct {
+	Tracker string `json:"tracker"`
+	Query   string `json:"query"`
+}
+
+// CurrentStory returns the story currently being pointed, if the facilitator has picked one
+func CurrentStory(s CompleteSessionView) *Story {
+	return FindStory(s.Stories, s.CurrentStory)
+}
+
+func FindStory(stories []Story, key string) *Story {
+	if key == "" {
+		return nil
+	}
+	for i := range stories {
+		if stories[i].Key == key {
+			return &stories[i]
+		}
+	}
+	return nil
+}
+
+// AgreedEstimate returns the estimate everyone who voted this round landed on, if they all landed on the same thing
+func AgreedEstimate(s CompleteSessionView) (string, bool) {
+	votes := currentRoundVotes(s)
+	if len(votes) == 0 {
+		return "", false
+	}
+	for _, v := range votes[1:] {
+		if v.Vote != votes[0].Vote {
+			return "", false
+		}
+	}
+	return votes[0].Vote, true
+}
+
+func convertStories(s CompleteSessionView, item map[string]*dynamodb.AttributeValue) {
+	if len(s.Stories) == 0 {
+		return
+	}
+	stories := make([]*dynamodb.AttributeValue, len(s.Stories))
+	for i, story := range s.Stories {
+		m := map[string]*dynamodb.AttributeValue{
+			"Key":    {S: aws.String(story.Key)},
+			"Title":  {S: aws.String(story.Title)},
+			"Source": {S: aws.String(story.Source)},
+		}
+		// dynamo doesn't allow empty strings in all contexts, so leave out anything that isn't set
+		if story.URL != "" {
+			m["URL"] = &dynamodb.AttributeValue{S: aws.String(story.URL)}
+		}
+		if story.Estimate != "" {
+			m["Estimate"] = &dynamodb.AttributeValue{S: aws.String(story.Estimate)}
+		}
+		stories[i] = &dynamodb.AttributeValue{M: m}
+	}
+	item["Stories"] = &dynamodb.AttributeValue{L: stories}
+	if s.CurrentStory != "" {
+		item["CurrentStory"] = &dynamodb.AttributeValue{S: aws.String(s.CurrentStory)}
+	}
+}
+
+func readStories(item map[string]*dynamodb.AttributeValue) ([]Story, string) {
+	stories, ok := item["Stories"]
+	if !ok {
+		return nil, ""
+	}
+	ret := make([]Story, len(stories.L))
+	for i, s := range stories.L {
+		ret[i] = Story{
+			Key:    *s.M["Key"].S,
+			Title:  *s.M["Title"].S,
+			Source: *s.M["Source"].S,
+		}
+		if url, ok := s.M["URL"]; ok {
+			ret[i].URL = *url.S
+		}
+		if estimate, ok := s.M["Estimate"]; ok {
+			ret[i].Estimate = *estimate.S
+		}
+	}
+	current := ""
+	if c, ok := item["CurrentStory"]; ok {
+		current = *c.S
+	}
+	return ret, current
+}
@@ -0,0 +1,91 @@
+package session
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Stories_RoundTrip(t *testing.T) {
+	asserter := assert.New(t)
+
+	s := CompleteSessionView{
+		Stories: []Story{
+			{Key: "PP-1", Title: "First", URL: "https://example.atlassian.net/browse/PP-1", Source: "jira", Estimate: "3"},
+			{Key: "PP-2", Title: "Second", Source: "jira"},
+		},
+		CurrentStory: "PP-2",
+	}
+	item := make(map[string]*dynamodb.AttributeValue)
+	convertStories(s, item)
+
+	stories, current := readStories(item)
+	asserter.Equal(s.Stories, stories)
+	asserter.Equal("PP-2", current)
+
+	empty := make(map[string]*dynamodb.AttributeValue)
+	convertStories(CompleteSessionView{}, empty)
+	asserter.Empty(empty)
+	stories, current = readStories(empty)
+	asserter.Nil(stories)
+	asserter.Empty(current)
+}
+
+func Test_AgreedEstimate(t *testing.T) {
+	testCases := []struct {
+		name          string
+		sess          CompleteSessionView
+		expected      string
+		expectedAgree bool
+	}{
+		{
+			name: "nobody voted",
+			sess: CompleteSessionView{Participants: []User{{UserID: "a"}}},
+		},
+		{
+			name: "split",
+			sess: CompleteSessionView{Participants: []User{
+				{UserID: "a", CurrentVote: aws.String("3")},
+				{UserID: "b", CurrentVote: aws.String("5")},
+			}},
+		},
+		{
+			name: "unanimous among those who voted",
+			sess: CompleteSessionView{Participants: []User{
+				{UserID: "a", CurrentVote: aws.String("3")},
+				{UserID: "b"},
+				{UserID: "c", CurrentVote: aws.String("3")},
+			}},
+			expected:      "3",
+			expectedAgree: true,
+		},
+		{
+			name: "facilitator not pointing",
+			sess: CompleteSessionView{
+				Facilitator:  User{UserID: "f", CurrentVote: aws.String("8")},
+				Participants: []User{{UserID: "a", CurrentVote: aws.String("3")}},
+			},
+			expected:      "3",
+			expectedAgree: true,
+		},
+		{
+			name: "facilitator disagrees",
+			sess: CompleteSessionView{
+				FacilitatorPoints: true,
+				Facilitator:       User{UserID: "f", CurrentVote: aws.String("8")},
+				Participants:      []User{{UserID: "a", CurrentVote: aws.String("3")}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			asserter := assert.New(t)
+			estimate, agreed := AgreedEstimate(tc.sess)
+			asserter.Equal(tc.expected, estimate)
+			asserter.Equal(tc.expectedAgree, agreed)
+		})
+	}
+}
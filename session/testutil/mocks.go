@@ -20,6 +20,15 @@ func (m *MockDynamoClient) QueryWithContext(ctx aws.Context, input *dynamodb.Que
 	return ret.(*dynamodb.QueryOutput), args.Error(1)
 }
 
+func (m *MockDynamoClient) ScanWithContext(ctx aws.Context, input *dynamodb.ScanInput, opts ...request.Option) (*dynamodb.ScanOutput, error) {
+	args := m.Called(ctx, input, opts)
+	ret := args.Get(0)
+	if ret == nil {
+		return nil, args.Error(1)
+	}
+	return ret.(*dynamodb.ScanOutput), args.Error(1)
+}
+
 func (m *MockDynamoClient) TransactWriteItemsWithContext(ctx aws.Context, input *dynamodb.TransactWriteItemsInput, opts ...request.Option) (*dynamodb.TransactWriteItemsOutput, error) {
 	args := m.Called(ctx, input, opts)
 	ret := args.Get(0)
@@ -55,3 +64,30 @@ func (m *MockDynamoClient) DeleteItemWithContext(ctx aws.Context, input *dynamod
 	}
 	return ret.(*dynamodb.DeleteItemOutput), args.Error(1)
 }
+
+func (m *MockDynamoClient) UpdateItemWithContext(ctx aws.Context, input *dynamodb.UpdateItemInput, opts ...request.Option) (*dynamodb.UpdateItemOutput, error) {
+	args := m.Called(ctx, input, opts)
+	ret := args.Get(0)
+	if ret == nil {
+		return nil, args.Error(1)
+	}
+	return ret.(*dynamodb.UpdateItemOutput), args.Error(1)
+}
+
+func (m *MockDynamoClient) BatchGetItemWithContext(ctx aws.Context, input *dynamodb.BatchGetItemInput, opts ...request.Option) (*dynamodb.BatchGetItemOutput, error) {
+	args := m.Called(ctx, input, opts)
+	ret := args.Get(0)
+	if ret == nil {
+		return nil, args.Error(1)
+	}
+	return ret.(*dynamodb.BatchGetItemOutput), args.Error(1)
+}
+
+func (m *MockDynamoClient) BatchWriteItemWithContext(ctx aws.Context, input *dynamodb.BatchWriteItemInput, opts ...request.Option) (*dynamodb.BatchWriteItemOutput, error) {
+	args := m.Called(ctx, input, opts)
+	ret := args.Get(0)
+	if ret == nil {
+		return nil, args.Error(1)
+	}
+	return ret.(*dynamodb.BatchWriteItemOutput), args.Error(1)
+}
@@ -0,0 +1,155 @@
+package session
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+type TimerExpiryAction string
+
+const (
+	TimerExpiryNone        = TimerExpiryAction("")
+	TimerExpiryRejectVotes = TimerExpiryAction("rejectVotes")
+	TimerExpiryReveal      = TimerExpiryAction("reveal")
+)
+
+// only records with something pending have this set, making the schedule index sparse
+const scheduleShardPending = "pending"
+
+type StartTimerRequest struct {
+	DurationSeconds int               `json:"durationSeconds"`
+	ExpiryAction    TimerExpiryAction `json:"expiryAction,omitempty"`
+}
+
+type RoundTimer struct {
+	StartedAt       time.Time         `json:"startedAt"`
+	DurationSeconds int               `json:"durationSeconds"`
+	ExpiryAction    TimerExpiryAction `json:"expiryAction,omitempty"`
+}
+
+func (t RoundTimer) ExpiresAt() time.Time {
+	return t.StartedAt.Add(time.Duration(t.DurationSeconds) * time.Second)
+}
+
+func (t RoundTimer) Expired(now time.Time) bool {
+	return !now.Before(t.ExpiresAt())
+}
+
+// VotingClosed indicates if the session has a timer that has run out and is configured to stop accepting votes
+func VotingClosed(s CompleteSessionView, now time.Time) bool {
+	return s.Timer != nil && s.Timer.ExpiryAction == TimerExpiryRejectVotes && s.Timer.Expired(now)
+}
+
+type ExpiredTimerSweeper func(ctx context.Context, now time.Time) error
+
+func NewExpiredTimerSweeper(dynamo DynamoClient, tableName string, scheduleIndexName string, loadSession Loader, notifyParticipants ChangeNotifier) ExpiredTimerSweeper {
+	return func(ctx context.Context, now time.Time) error {
+		records, err := queryAll(ctx, dynamo, &dynamodb.QueryInput{
+			TableName: aws.String(tableName),
+			IndexName: aws.String(scheduleIndexName),
+			KeyConditions: map[string]*dynamodb.Condition{
+				"ScheduleShard": {
+					ComparisonOperator: aws.String("EQ"),
+					AttributeValueList: []*dynamodb.AttributeValue{
+						{S: aws.String(scheduleShardPending)},
+					},
+				},
+				"ScheduledAt": {
+					ComparisonOperator: aws.String("LE"),
+					AttributeValueList: []*dynamodb.AttributeValue{
+						{N: aws.String(strconv.FormatInt(now.Unix(), 10))},
+					},
+				},
+			},
+		})
+		if err != nil {
+			return err
+		}
+
+		var sweepErr error
+		for _, r := range records {
+			sessionID := *r["SessionID"].S
+			err := revealExpiredTimer(ctx, dynamo, tableName, sessionID, r["ScheduledAt"], loadSession, notifyParticipants)
+			if err != nil {
+				// keep going so one bad session doesn't hold up everyone else
+				zerolog.Ctx(ctx).Error().Err(err).Str("sessionID", sessionID).Msg("error revealing expired timer")
+				sweepErr = err
+			}
+		}
+		return sweepErr
+	}
+}
+
+func revealExpiredTimer(ctx context.Context, dynamo DynamoClient, tableName string, sessionID string, scheduledAt *dynamodb.AttributeValue, loadSession Loader, notifyParticipants ChangeNotifier) error {
+	// the facilitator may have restarted the timer (or revealed) since we looked, so only reveal if the schedule is unchanged
+	res, err := dynamo.UpdateItemWithContext(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(tableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"SessionID": {S: aws.String(sessionID)},
+			"RangeKey":  {S: aws.String(sessionRecordRangeKeyValue)},
+		},
+		UpdateExpression:    aws.String("SET VotesShown = :shown REMOVE ScheduleShard, ScheduledAt"),
+		ConditionExpression: aws.String("ScheduledAt = :scheduledAt"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":shown":       {BOOL: aws.Bool(true)},
+			":scheduledAt": scheduledAt,
+		},
+		ReturnValues: aws.String(dynamodb.ReturnValueUpdatedOld),
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+			zerolog.Ctx(ctx).Info().Str("sessionID", sessionID).Msg("timer changed before it could be swept")
+			return nil
+		}
+		return errors.WithStack(err)
+	}
+	// whoever revealed first has already told everyone
+	if readOptionalBool(res.Attributes, "VotesShown") {
+		return nil
+	}
+
+	sess, err := loadSession(ctx, sessionID)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if sess == nil {
+		return nil
+	}
+	return errors.WithStack(notifyParticipants(ctx, *sess))
+}
+
+func convertTimer(s CompleteSessionView, item map[string]*dynamodb.AttributeValue) {
+	if s.Timer == nil {
+		return
+	}
+	item["TimerStart"] = &dynamodb.AttributeValue{N: aws.String(strconv.FormatInt(s.Timer.StartedAt.Unix(), 10))}
+	item["TimerDuration"] = &dynamodb.AttributeValue{N: aws.String(strconv.Itoa(s.Timer.DurationSeconds))}
+	item["TimerExpiryAction"] = &dynamodb.AttributeValue{S: aws.String(string(s.Timer.ExpiryAction))}
+	// once a timer has run out it is either swept or the facilitator has taken over, in which case hiding votes again
+	// shouldn't trigger another reveal
+	if s.Timer.ExpiryAction == TimerExpiryReveal && !s.VotesShown && !s.Timer.Expired(time.Now()) {
+		item["ScheduleShard"] = &dynamodb.AttributeValue{S: aws.String(scheduleShardPending)}
+		item["ScheduledAt"] = &dynamodb.AttributeValue{N: aws.String(strconv.FormatInt(s.Timer.ExpiresAt().Unix(), 10))}
+	}
+}
+
+func readTimer(item map[string]*dynamodb.AttributeValue) *RoundTimer {
+	start, ok := item["TimerStart"]
+	if !ok {
+		return nil
+	}
+	startedAt, _ := strconv.ParseInt(*start.N, 10, 64)
+	duration, _ := strconv.Atoi(*item["TimerDuration"].N)
+	return &RoundTimer{
+		StartedAt:       time.Unix(startedAt, 0).UTC(),
+		DurationSeconds: duration,
+		ExpiryAction:    TimerExpiryAction(*item["TimerExpiryAction"].S),
+	}
+}
@@ -0,0 +1,125 @@
+package session
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jonsabados/pointypoints/session/testutil"
+)
+
+func Test_Timer_RoundTrip(t *testing.T) {
+	asserter := assert.New(t)
+
+	timer := &RoundTimer{
+		StartedAt:       time.Now().UTC().Truncate(time.Second),
+		DurationSeconds: 90,
+		ExpiryAction:    TimerExpiryReveal,
+	}
+	item := make(map[string]*dynamodb.AttributeValue)
+	convertTimer(CompleteSessionView{Timer: timer}, item)
+
+	asserter.Equal(timer, readTimer(item))
+	asserter.Equal("pending", *item["ScheduleShard"].S)
+	asserter.Equal(strconv.FormatInt(timer.ExpiresAt().Unix(), 10), *item["ScheduledAt"].N)
+
+	shown := make(map[string]*dynamodb.AttributeValue)
+	convertTimer(CompleteSessionView{Timer: timer, VotesShown: true}, shown)
+	asserter.NotContains(shown, "ScheduleShard")
+	asserter.NotContains(shown, "ScheduledAt")
+
+	asserter.Nil(readTimer(map[string]*dynamodb.AttributeValue{}))
+}
+
+func Test_VotingClosed(t *testing.T) {
+	now := time.Now()
+	expired := &RoundTimer{StartedAt: now.Add(-time.Minute), DurationSeconds: 30, ExpiryAction: TimerExpiryRejectVotes}
+	running := &RoundTimer{StartedAt: now, DurationSeconds: 30, ExpiryAction: TimerExpiryRejectVotes}
+	informational := &RoundTimer{StartedAt: now.Add(-time.Minute), DurationSeconds: 30}
+
+	asserter := assert.New(t)
+	asserter.False(VotingClosed(CompleteSessionView{}, now))
+	asserter.True(VotingClosed(CompleteSessionView{Timer: expired}, now))
+	asserter.False(VotingClosed(CompleteSessionView{Timer: running}, now))
+	asserter.False(VotingClosed(CompleteSessionView{Timer: informational}, now))
+}
+
+func Test_NewExpiredTimerSweeper(t *testing.T) {
+	asserter := assert.New(t)
+
+	inputCtx := testutil.NewTestContext()
+	dynamo := &testutil.MockDynamoClient{}
+	tableName := "sessions"
+	indexName := "schedule"
+	now := time.Unix(1000, 0)
+
+	dynamo.On("QueryWithContext", inputCtx, &dynamodb.QueryInput{
+		TableName: aws.String(tableName),
+		IndexName: aws.String(indexName),
+		KeyConditions: map[string]*dynamodb.Condition{
+			"ScheduleShard": {
+				ComparisonOperator: aws.String("EQ"),
+				AttributeValueList: []*dynamodb.AttributeValue{
+					{S: aws.String("pending")},
+				},
+			},
+			"ScheduledAt": {
+				ComparisonOperator: aws.String("LE"),
+				AttributeValueList: []*dynamodb.AttributeValue{
+					{N: aws.String("1000")},
+				},
+			},
+		},
+	}, emptyOpts).Return(&dynamodb.QueryOutput{
+		Items: []map[string]*dynamodb.AttributeValue{
+			{"SessionID": {S: aws.String("expired")}, "RangeKey": {S: aws.String("session")}, "ScheduledAt": {N: aws.String("990")}},
+			{"SessionID": {S: aws.String("restarted")}, "RangeKey": {S: aws.String("session")}, "ScheduledAt": {N: aws.String("995")}},
+			{"SessionID": {S: aws.String("revealed")}, "RangeKey": {S: aws.String("session")}, "ScheduledAt": {N: aws.String("998")}},
+		},
+	}, nil)
+
+	expectReveal := func(sessionID string, scheduledAt string, wasShown bool, err error) {
+		dynamo.On("UpdateItemWithContext", inputCtx, &dynamodb.UpdateItemInput{
+			TableName: aws.String(tableName),
+			Key: map[string]*dynamodb.AttributeValue{
+				"SessionID": {S: aws.String(sessionID)},
+				"RangeKey":  {S: aws.String("session")},
+			},
+			UpdateExpression:    aws.String("SET VotesShown = :shown REMOVE ScheduleShard, ScheduledAt"),
+			ConditionExpression: aws.String("ScheduledAt = :scheduledAt"),
+			ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+				":shown":       {BOOL: aws.Bool(true)},
+				":scheduledAt": {N: aws.String(scheduledAt)},
+			},
+			ReturnValues: aws.String("UPDATED_OLD"),
+		}, emptyOpts).Return(&dynamodb.UpdateItemOutput{
+			Attributes: map[string]*dynamodb.AttributeValue{"VotesShown": {BOOL: aws.Bool(wasShown)}},
+		}, err)
+	}
+	expectReveal("expired", "990", false, nil)
+	expectReveal("restarted", "995", false, awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "changed", nil))
+	// votes that were already out, say from everyone voting, have already been announced
+	expectReveal("revealed", "998", true, nil)
+
+	loader := Loader(func(ctx context.Context, sessionID string) (*CompleteSessionView, error) {
+		asserter.Equal("expired", sessionID)
+		return &CompleteSessionView{SessionID: sessionID, VotesShown: true}, nil
+	})
+
+	notified := make([]string, 0)
+	notifier := ChangeNotifier(func(ctx context.Context, updated CompleteSessionView) error {
+		notified = append(notified, updated.SessionID)
+		return nil
+	})
+
+	err := NewExpiredTimerSweeper(dynamo, tableName, indexName, loader, notifier)(inputCtx, now)
+	asserter.NoError(err)
+	asserter.Equal([]string{"expired"}, notified)
+	dynamo.AssertExpectations(t)
+}
@@ -0,0 +1,30 @@
+package session
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/pkg/errors"
+)
+
+// maxTransactItems is the most actions DynamoDB will accept in a single TransactWriteItems call
+const maxTransactItems = 100
+
+// transactInChunks writes actions in as few transactions as it can, in order. Each chunk is atomic but the write as a
+// whole is not, so callers should order actions such that the records making a change visible come last, and every
+// action needs to be safe to repeat should a caller retry after a partial failure.
+func transactInChunks(ctx context.Context, dynamo DynamoClient, actions []*dynamodb.TransactWriteItem) error {
+	for start := 0; start < len(actions); start += maxTransactItems {
+		end := start + maxTransactItems
+		if end > len(actions) {
+			end = len(actions)
+		}
+		_, err := dynamo.TransactWriteItemsWithContext(ctx, &dynamodb.TransactWriteItemsInput{
+			TransactItems: actions[start:end],
+		})
+		if err != nil {
+			return errors.Wrapf(err, "error writing records %d through %d of %d", start, end, len(actions))
+		}
+	}
+	return nil
+}
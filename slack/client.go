@@ -0,0 +1,92 @@
+package slack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+const DefaultAPIURL = "https://slack.com/api"
+
+type apiResponse struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error"`
+	TS    string `json:"ts"`
+}
+
+type webClient struct {
+	apiURL   string
+	botToken string
+	client   *http.Client
+}
+
+// NewClient returns a Client backed by the slack web api, authenticating as the bot the app was installed with
+func NewClient(apiURL string, botToken string, client *http.Client) Client {
+	if apiURL == "" {
+		apiURL = DefaultAPIURL
+	}
+	return &webClient{
+		apiURL:   apiURL,
+		botToken: botToken,
+		client:   client,
+	}
+}
+
+func (w *webClient) PostMessage(ctx context.Context, channel string, msg Message) (string, error) {
+	res, err := w.call(ctx, "chat.postMessage", map[string]interface{}{
+		"channel": channel,
+		"text":    msg.Text,
+		"blocks":  msg.Blocks,
+	})
+	if err != nil {
+		return "", err
+	}
+	return res.TS, nil
+}
+
+func (w *webClient) UpdateMessage(ctx context.Context, channel string, ts string, msg Message) error {
+	_, err := w.call(ctx, "chat.update", map[string]interface{}{
+		"channel": channel,
+		"ts":      ts,
+		"text":    msg.Text,
+		"blocks":  msg.Blocks,
+	})
+	return err
+}
+
+func (w *webClient) call(ctx context.Context, method string, body interface{}) (apiResponse, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return apiResponse{}, errors.WithStack(err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/%s", w.apiURL, method), bytes.NewReader(payload))
+	if err != nil {
+		return apiResponse{}, errors.WithStack(err)
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", w.botToken))
+
+	res, err := w.client.Do(req)
+	if err != nil {
+		return apiResponse{}, errors.WithStack(err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return apiResponse{}, errors.Errorf("unexpected status %d calling %s", res.StatusCode, method)
+	}
+
+	ret := apiResponse{}
+	err = json.NewDecoder(res.Body).Decode(&ret)
+	if err != nil {
+		return apiResponse{}, errors.WithStack(err)
+	}
+	// slack reports most failures with a 200 and ok set to false
+	if !ret.OK {
+		return ret, errors.Errorf("error calling %s: %s", method, ret.Error)
+	}
+	return ret, nil
+}
@@ -0,0 +1,86 @@
+package slack_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jonsabados/pointypoints/session/testutil"
+	"github.com/jonsabados/pointypoints/slack"
+)
+
+type recordedCall struct {
+	path          string
+	authorization string
+	body          map[string]interface{}
+}
+
+func newFakeSlack(t *testing.T, response string) (*httptest.Server, *[]recordedCall) {
+	calls := make([]recordedCall, 0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		body := make(map[string]interface{})
+		assert.NoError(t, json.Unmarshal(raw, &body))
+		calls = append(calls, recordedCall{
+			path:          r.URL.Path,
+			authorization: r.Header.Get("Authorization"),
+			body:          body,
+		})
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(response))
+	}))
+	return server, &calls
+}
+
+func TestClient_PostMessage(t *testing.T) {
+	asserter := assert.New(t)
+
+	server, calls := newFakeSlack(t, `{"ok":true,"channel":"C1","ts":"1600000000.000100"}`)
+	defer server.Close()
+
+	client := slack.NewClient(server.URL, "xoxb-token", server.Client())
+	ts, err := client.PostMessage(testutil.NewTestContext(), "C1", slack.Message{Text: "hello"})
+	asserter.NoError(err)
+	asserter.Equal("1600000000.000100", ts)
+
+	if asserter.Len(*calls, 1) {
+		call := (*calls)[0]
+		asserter.Equal("/chat.postMessage", call.path)
+		asserter.Equal("Bearer xoxb-token", call.authorization)
+		asserter.Equal("C1", call.body["channel"])
+		asserter.Equal("hello", call.body["text"])
+	}
+}
+
+func TestClient_UpdateMessage(t *testing.T) {
+	asserter := assert.New(t)
+
+	server, calls := newFakeSlack(t, `{"ok":true}`)
+	defer server.Close()
+
+	client := slack.NewClient(server.URL, "xoxb-token", server.Client())
+	err := client.UpdateMessage(testutil.NewTestContext(), "C1", "1600000000.000100", slack.Message{Text: "updated"})
+	asserter.NoError(err)
+
+	if asserter.Len(*calls, 1) {
+		call := (*calls)[0]
+		asserter.Equal("/chat.update", call.path)
+		asserter.Equal("C1", call.body["channel"])
+		asserter.Equal("1600000000.000100", call.body["ts"])
+		asserter.Equal("updated", call.body["text"])
+	}
+}
+
+func TestClient_NotOK(t *testing.T) {
+	server, _ := newFakeSlack(t, `{"ok":false,"error":"channel_not_found"}`)
+	defer server.Close()
+
+	client := slack.NewClient(server.URL, "xoxb-token", server.Client())
+	_, err := client.PostMessage(testutil.NewTestContext(), "C1", slack.Message{Text: "hello"})
+	assert.EqualError(t, err, "error calling chat.postMessage: channel_not_found")
+}
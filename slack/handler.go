@@ -0,0 +1,180 @@
+package slack
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+
+	"github.com/jonsabados/pointypoints/session"
+	"github.com/jonsabados/pointypoints/webhook"
+)
+
+const storyKey = "slack"
+
+// CommandResponse is sent back to whoever ran the command, and only they can see it
+type CommandResponse struct {
+	ResponseType string `json:"response_type"`
+	Text         string `json:"text"`
+}
+
+func ephemeral(text string) CommandResponse {
+	return CommandResponse{
+		ResponseType: "ephemeral",
+		Text:         text,
+	}
+}
+
+// CommandHandler starts a session for `/point <story>` and posts the voting message to the channel it was run in
+type CommandHandler func(ctx context.Context, cmd SlashCommand) (CommandResponse, error)
+
+func NewCommandHandler(client Client, startSession session.Starter, saveSession session.Saver, publish webhook.Publisher) CommandHandler {
+	return func(ctx context.Context, cmd SlashCommand) (CommandResponse, error) {
+		if cmd.Text == "" {
+			return ephemeral(fmt.Sprintf("Usage: %s <story>", cmd.Command)), nil
+		}
+
+		principal, facilitator := Participant(cmd.TeamID, cmd.UserID, cmd.UserName)
+		// whoever kicks things off from a channel is usually on the team doing the pointing
+		facilitatorPoints := true
+		sess, err := startSession(ctx, principal, session.StartRequest{
+			Facilitator:       facilitator,
+			FacilitatorPoints: &facilitatorPoints,
+			ConnectionID:      facilitator.SocketID,
+		})
+		if err != nil {
+			return CommandResponse{}, errors.WithStack(err)
+		}
+
+		err = publish(ctx, webhook.NewSessionStartedEvent(sess))
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("error publishing webhook event")
+		}
+
+		original := session.Clone(sess)
+		sess.Stories = []session.Story{{Key: storyKey, Title: cmd.Text}}
+		sess.CurrentStory = storyKey
+		ts, err := client.PostMessage(ctx, cmd.ChannelID, VotingMessage(sess))
+		if err != nil {
+			return CommandResponse{}, errors.WithStack(err)
+		}
+		sess.IntegrationMessage = &session.IntegrationMessage{
+			Provider:  IntegrationName,
+			Channel:   cmd.ChannelID,
+			MessageID: ts,
+		}
+		err = saveSession(ctx, original, sess)
+		if err != nil {
+			return CommandResponse{}, errors.WithStack(err)
+		}
+
+		return ephemeral(fmt.Sprintf("Pointing started for %s, hit Reveal when everyone is in", cmd.Text)), nil
+	}
+}
+
+// ActionHandler deals with button clicks on the voting message
+type ActionHandler func(ctx context.Context, interaction Interaction) error
+
+func NewActionHandler(client Client, loadSession session.Loader, saveJoin session.JoinSaver, recordVote session.VoteRecorder, autoReveal session.AutoRevealer, showVotes session.VotesShownSaver, notifyParticipants session.ChangeNotifier, publish webhook.Publisher) ActionHandler {
+	vote := func(ctx context.Context, interaction Interaction, sess *session.CompleteSessionView, card string) (*session.CompleteSessionView, error) {
+		if sess.Deadline != nil || session.VotingClosed(*sess, time.Now()) {
+			zerolog.Ctx(ctx).Info().Str("sessionID", sess.SessionID).Msg("ignoring vote after voting closed")
+			return sess, nil
+		}
+
+		principal, voter := Participant(interaction.Team.ID, interaction.User.ID, interaction.User.Username)
+		userType := session.Participant
+		var user *session.User
+		if sess.FacilitatorPoints && sess.Facilitator.UserID == voter.UserID {
+			userType = session.Facilitator
+			user = &sess.Facilitator
+		} else {
+			for i := range sess.Participants {
+				if sess.Participants[i].UserID == voter.UserID {
+					user = &sess.Participants[i]
+					break
+				}
+			}
+		}
+		if user == nil {
+			// anybody in the channel can point, the first click is as good as joining
+			err := saveJoin(ctx, principal, sess.SessionID, voter, session.Participant)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			user = &voter
+		}
+
+		user.CurrentVote = &card
+		user.HasVoted = true
+		err := recordVote(ctx, principal, sess.SessionID, *user, userType)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		updated, err := autoReveal(ctx, sess.SessionID)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if updated == nil {
+			return nil, errors.WithStack(session.ErrorSessionNotFound)
+		}
+		return updated, errors.WithStack(notifyParticipants(ctx, *updated))
+	}
+
+	reveal := func(ctx context.Context, interaction Interaction, sess *session.CompleteSessionView) (*session.CompleteSessionView, error) {
+		_, clicker := Participant(interaction.Team.ID, interaction.User.ID, interaction.User.Username)
+		if clicker.UserID != sess.Facilitator.UserID {
+			zerolog.Ctx(ctx).Info().Str("sessionID", sess.SessionID).Str("userID", clicker.UserID).Msg("ignoring reveal from someone other than the facilitator")
+			return sess, nil
+		}
+		sess.VotesShown = true
+		return sess, errors.WithStack(showVotes(ctx, *sess))
+	}
+
+	return func(ctx context.Context, interaction Interaction) error {
+		for _, action := range interaction.Actions {
+			sess, err := loadSession(ctx, action.Value)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			if sess == nil {
+				zerolog.Ctx(ctx).Warn().Str("sessionID", action.Value).Msg("session not found")
+				continue
+			}
+			if sess.VotesShown {
+				// the message has already been swapped over to the results, so this is a stale click
+				continue
+			}
+
+			switch {
+			case strings.HasPrefix(action.ActionID, VoteActionPrefix):
+				sess, err = vote(ctx, interaction, sess, strings.TrimPrefix(action.ActionID, VoteActionPrefix))
+			case action.ActionID == RevealActionID:
+				sess, err = reveal(ctx, interaction, sess)
+			default:
+				zerolog.Ctx(ctx).Warn().Str("actionID", action.ActionID).Msg("unexpected action")
+				continue
+			}
+			if err != nil {
+				return err
+			}
+
+			msg := VotingMessage(*sess)
+			if sess.VotesShown {
+				msg = ResultsMessage(*sess)
+				err = publish(ctx, webhook.NewVotesRevealedEvent(*sess))
+				if err != nil {
+					zerolog.Ctx(ctx).Error().Err(err).Msg("error publishing webhook event")
+				}
+			}
+			err = client.UpdateMessage(ctx, interaction.Channel.ID, interaction.Message.TS, msg)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+		}
+		return nil
+	}
+}
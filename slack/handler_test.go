@@ -0,0 +1,272 @@
+package slack_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/jonsabados/goauth"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jonsabados/pointypoints/session"
+	"github.com/jonsabados/pointypoints/session/testutil"
+	"github.com/jonsabados/pointypoints/slack"
+	slacktestutil "github.com/jonsabados/pointypoints/slack/testutil"
+	"github.com/jonsabados/pointypoints/webhook"
+)
+
+func noopPublish(ctx context.Context, event webhook.Event) error {
+	return nil
+}
+
+func TestNewCommandHandler(t *testing.T) {
+	t.Run("usage", func(t *testing.T) {
+		asserter := assert.New(t)
+
+		client := &slacktestutil.FakeClient{}
+		res, err := slack.NewCommandHandler(client, func(ctx context.Context, initiator goauth.Principal, toStart session.StartRequest) (session.CompleteSessionView, error) {
+			t.Fatal("session should not have been started")
+			return session.CompleteSessionView{}, nil
+		}, func(ctx context.Context, original session.CompleteSessionView, toSave session.CompleteSessionView) error {
+			t.Fatal("session should not have been saved")
+			return nil
+		}, noopPublish)(testutil.NewTestContext(), slack.SlashCommand{Command: "/point"})
+
+		asserter.NoError(err)
+		asserter.Equal(slack.CommandResponse{ResponseType: "ephemeral", Text: "Usage: /point <story>"}, res)
+		asserter.Empty(client.Posted)
+	})
+
+	t.Run("starts session", func(t *testing.T) {
+		asserter := assert.New(t)
+
+		var started session.StartRequest
+		var startedBy goauth.Principal
+		var saved session.CompleteSessionView
+		client := &slacktestutil.FakeClient{}
+		res, err := slack.NewCommandHandler(client, func(ctx context.Context, initiator goauth.Principal, toStart session.StartRequest) (session.CompleteSessionView, error) {
+			started = toStart
+			startedBy = initiator
+			return session.CompleteSessionView{
+				SessionID:         "abcdefg",
+				Facilitator:       toStart.Facilitator,
+				FacilitatorPoints: *toStart.FacilitatorPoints,
+				Round:             1,
+			}, nil
+		}, func(ctx context.Context, original session.CompleteSessionView, toSave session.CompleteSessionView) error {
+			asserter.Nil(original.IntegrationMessage)
+			saved = toSave
+			return nil
+		}, noopPublish)(testutil.NewTestContext(), slack.SlashCommand{
+			TeamID:    "T1",
+			ChannelID: "C1",
+			UserID:    "U1",
+			UserName:  "bob",
+			Command:   "/point",
+			Text:      "PP-12 login page",
+		})
+
+		asserter.NoError(err)
+		asserter.Equal("ephemeral", res.ResponseType)
+
+		asserter.Equal("slack:T1:U1", startedBy.UserID)
+		asserter.Equal("slack:T1:U1", started.Facilitator.UserID)
+		asserter.Equal("slack:T1:U1", started.ConnectionID)
+		asserter.True(*started.FacilitatorPoints)
+
+		if asserter.Len(client.Posted, 1) {
+			asserter.Equal("C1", client.Posted[0].Channel)
+			asserter.Equal("Pointing PP-12 login page", client.Posted[0].Message.Text)
+		}
+
+		asserter.Equal("abcdefg", saved.SessionID)
+		asserter.Equal(&session.IntegrationMessage{
+			Provider:  "slack",
+			Channel:   "C1",
+			MessageID: client.Posted[0].TS,
+		}, saved.IntegrationMessage)
+		asserter.Equal("PP-12 login page", session.CurrentStory(saved).Title)
+	})
+}
+
+type actionFixture struct {
+	sess      session.CompleteSessionView
+	joined    []session.User
+	votes     []session.User
+	voteTypes []session.UserType
+	saved     []session.CompleteSessionView
+	notified  []session.CompleteSessionView
+	published []webhook.Event
+	client    *slacktestutil.FakeClient
+}
+
+func (f *actionFixture) handler() slack.ActionHandler {
+	f.client = &slacktestutil.FakeClient{}
+	return slack.NewActionHandler(f.client, func(ctx context.Context, sessionID string) (*session.CompleteSessionView, error) {
+		ret := f.sess
+		ret.Participants = append([]session.User{}, f.sess.Participants...)
+		return &ret, nil
+	}, func(ctx context.Context, initiator goauth.Principal, sessionID string, user session.User, userType session.UserType) error {
+		f.joined = append(f.joined, user)
+		return nil
+	}, func(ctx context.Context, initiator goauth.Principal, sessionID string, user session.User, userType session.UserType) error {
+		f.votes = append(f.votes, user)
+		f.voteTypes = append(f.voteTypes, userType)
+		return nil
+	}, func(ctx context.Context, sessionID string) (*session.CompleteSessionView, error) {
+		ret := f.sess
+		return &ret, nil
+	}, func(ctx context.Context, toSave session.CompleteSessionView) error {
+		f.saved = append(f.saved, toSave)
+		return nil
+	}, func(ctx context.Context, updated session.CompleteSessionView) error {
+		f.notified = append(f.notified, updated)
+		return nil
+	}, func(ctx context.Context, event webhook.Event) error {
+		f.published = append(f.published, event)
+		return nil
+	})
+}
+
+func interaction(userID string, actionID string) slack.Interaction {
+	ret := slack.Interaction{
+		Actions: []slack.Action{{ActionID: actionID, Value: "abcdefg"}},
+	}
+	ret.Team.ID = "T1"
+	ret.User.ID = userID
+	ret.User.Username = userID
+	ret.Channel.ID = "C1"
+	ret.Message.TS = "1600000000.000001"
+	return ret
+}
+
+func baseSession() session.CompleteSessionView {
+	return session.CompleteSessionView{
+		SessionID:         "abcdefg",
+		Facilitator:       session.User{UserID: "slack:T1:FAC", Name: "FAC", SocketID: "slack:T1:FAC"},
+		FacilitatorPoints: true,
+		Round:             1,
+		Participants: []session.User{
+			{UserID: "slack:T1:U1", Name: "U1", SocketID: "slack:T1:U1"},
+		},
+	}
+}
+
+func TestNewActionHandler_Vote(t *testing.T) {
+	testCases := []struct {
+		name         string
+		userID       string
+		expectedJoin bool
+		expectedType session.UserType
+	}{
+		{
+			name:         "existing participant",
+			userID:       "U1",
+			expectedType: session.Participant,
+		},
+		{
+			name:         "new participant",
+			userID:       "U2",
+			expectedJoin: true,
+			expectedType: session.Participant,
+		},
+		{
+			name:         "facilitator",
+			userID:       "FAC",
+			expectedType: session.Facilitator,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			asserter := assert.New(t)
+
+			f := &actionFixture{sess: baseSession()}
+			err := f.handler()(testutil.NewTestContext(), interaction(tc.userID, "vote:5"))
+			asserter.NoError(err)
+
+			if tc.expectedJoin {
+				if asserter.Len(f.joined, 1) {
+					asserter.Equal("slack:T1:"+tc.userID, f.joined[0].UserID)
+				}
+			} else {
+				asserter.Empty(f.joined)
+			}
+			if asserter.Len(f.votes, 1) {
+				asserter.Equal("slack:T1:"+tc.userID, f.votes[0].UserID)
+				asserter.Equal(aws.String("5"), f.votes[0].CurrentVote)
+				asserter.Equal(tc.expectedType, f.voteTypes[0])
+			}
+			asserter.Len(f.notified, 1)
+			asserter.Empty(f.saved)
+			asserter.Empty(f.published)
+			if asserter.Len(f.client.Updated, 1) {
+				asserter.Equal("C1", f.client.Updated[0].Channel)
+				asserter.Equal("1600000000.000001", f.client.Updated[0].TS)
+				asserter.Equal("Pointing Pointing", f.client.Updated[0].Message.Text)
+			}
+		})
+	}
+}
+
+func TestNewActionHandler_VoteAsync(t *testing.T) {
+	asserter := assert.New(t)
+
+	deadline := time.Now().Add(time.Hour)
+	f := &actionFixture{sess: baseSession()}
+	f.sess.Deadline = &deadline
+	err := f.handler()(testutil.NewTestContext(), interaction("U1", "vote:5"))
+	asserter.NoError(err)
+	asserter.Empty(f.joined)
+	asserter.Empty(f.votes)
+}
+
+func TestNewActionHandler_Reveal(t *testing.T) {
+	t.Run("facilitator", func(t *testing.T) {
+		asserter := assert.New(t)
+
+		f := &actionFixture{sess: baseSession()}
+		f.sess.Participants[0].CurrentVote = aws.String("3")
+		err := f.handler()(testutil.NewTestContext(), interaction("FAC", "reveal"))
+		asserter.NoError(err)
+
+		if asserter.Len(f.saved, 1) {
+			asserter.True(f.saved[0].VotesShown)
+		}
+		if asserter.Len(f.published, 1) {
+			asserter.Equal(webhook.VotesRevealed, f.published[0].Type)
+		}
+		if asserter.Len(f.client.Updated, 1) {
+			msg := f.client.Updated[0].Message
+			asserter.Equal("Results for Pointing", msg.Text)
+			asserter.Equal("*U1*: 3", msg.Blocks[1].Text.Text)
+		}
+	})
+
+	t.Run("not the facilitator", func(t *testing.T) {
+		asserter := assert.New(t)
+
+		f := &actionFixture{sess: baseSession()}
+		err := f.handler()(testutil.NewTestContext(), interaction("U1", "reveal"))
+		asserter.NoError(err)
+
+		asserter.Empty(f.saved)
+		asserter.Empty(f.published)
+		if asserter.Len(f.client.Updated, 1) {
+			asserter.Equal("Pointing Pointing", f.client.Updated[0].Message.Text)
+		}
+	})
+
+	t.Run("already revealed", func(t *testing.T) {
+		asserter := assert.New(t)
+
+		f := &actionFixture{sess: baseSession()}
+		f.sess.VotesShown = true
+		err := f.handler()(testutil.NewTestContext(), interaction("FAC", "reveal"))
+		asserter.NoError(err)
+
+		asserter.Empty(f.saved)
+		asserter.Empty(f.client.Updated)
+	})
+}
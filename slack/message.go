@@ -0,0 +1,137 @@
+package slack
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jonsabados/pointypoints/session"
+)
+
+const (
+	// vote buttons carry the card in their action id and the session in their value
+	VoteActionPrefix = "vote:"
+	RevealActionID   = "reveal"
+)
+
+type Message struct {
+	// Text is the fallback shown in notifications and by clients that can't render blocks
+	Text   string  `json:"text"`
+	Blocks []Block `json:"blocks"`
+}
+
+type Text struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type Button struct {
+	Type     string `json:"type"`
+	Text     Text   `json:"text"`
+	ActionID string `json:"action_id"`
+	Value    string `json:"value"`
+	Style    string `json:"style,omitempty"`
+}
+
+type Block struct {
+	Type string `json:"type"`
+	Text *Text  `json:"text,omitempty"`
+	// Elements are Text for context blocks and Button for actions blocks
+	Elements []interface{} `json:"elements,omitempty"`
+}
+
+func markdown(text string) *Text {
+	return &Text{Type: "mrkdwn", Text: text}
+}
+
+func button(label string, actionID string, value string) Button {
+	return Button{
+		Type:     "button",
+		Text:     Text{Type: "plain_text", Text: label},
+		ActionID: actionID,
+		Value:    value,
+	}
+}
+
+func storyTitle(s session.CompleteSessionView) string {
+	if story := session.CurrentStory(s); story != nil {
+		return story.Title
+	}
+	return "Pointing"
+}
+
+func voters(s session.CompleteSessionView) []session.User {
+	ret := s.Participants
+	if s.FacilitatorPoints {
+		ret = append([]session.User{s.Facilitator}, ret...)
+	}
+	return ret
+}
+
+// VotingMessage is posted when a session starts and kept up to date as people vote
+func VotingMessage(s session.CompleteSessionView) Message {
+	title := storyTitle(s)
+
+	voted := make([]string, 0)
+	for _, u := range voters(s) {
+		if u.HasVoted {
+			voted = append(voted, u.Name)
+		}
+	}
+	status := "Nobody has voted yet"
+	if len(voted) > 0 {
+		status = fmt.Sprintf("Voted: %s", strings.Join(voted, ", "))
+	}
+
+	deck := session.SessionDeck(s)
+	cards := make([]interface{}, len(deck))
+	for i, card := range deck {
+		cards[i] = button(card, VoteActionPrefix+card, s.SessionID)
+	}
+	reveal := button("Reveal", RevealActionID, s.SessionID)
+	reveal.Style = "primary"
+
+	return Message{
+		Text: fmt.Sprintf("Pointing %s", title),
+		Blocks: []Block{
+			{Type: "section", Text: markdown(fmt.Sprintf("*%s*\nRound %d", title, s.Round))},
+			{Type: "context", Elements: []interface{}{markdown(status)}},
+			{Type: "actions", Elements: cards},
+			{Type: "actions", Elements: []interface{}{reveal}},
+		},
+	}
+}
+
+// ResultsMessage replaces the voting message once votes are revealed, respecting anonymous reveal
+func ResultsMessage(s session.CompleteSessionView) Message {
+	title := storyTitle(s)
+
+	lines := make([]string, 0)
+	if s.AnonymousReveal {
+		view := session.ToParticipantView(s, "")
+		if len(view.RevealedVotes) > 0 {
+			lines = append(lines, strings.Join(view.RevealedVotes, ", "))
+		}
+	} else {
+		for _, u := range voters(s) {
+			if u.CurrentVote != nil {
+				lines = append(lines, fmt.Sprintf("*%s*: %s", u.Name, *u.CurrentVote))
+			}
+		}
+	}
+	results := "Nobody voted"
+	if len(lines) > 0 {
+		results = strings.Join(lines, "\n")
+	}
+
+	blocks := []Block{
+		{Type: "section", Text: markdown(fmt.Sprintf("*%s*\nRound %d results", title, s.Round))},
+		{Type: "section", Text: markdown(results)},
+	}
+	if estimate, agreed := session.AgreedEstimate(s); agreed {
+		blocks = append(blocks, Block{Type: "context", Elements: []interface{}{markdown(fmt.Sprintf("Everyone agreed on %s", estimate))}})
+	}
+	return Message{
+		Text:   fmt.Sprintf("Results for %s", title),
+		Blocks: blocks,
+	}
+}
@@ -0,0 +1,42 @@
+package slack_test
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jonsabados/pointypoints/slack"
+)
+
+func TestVotingMessage(t *testing.T) {
+	asserter := assert.New(t)
+
+	sess := baseSession()
+	sess.Participants[0].HasVoted = true
+	sess.Deck = []string{"1", "2"}
+	msg := slack.VotingMessage(sess)
+
+	asserter.Equal("Voted: U1", msg.Blocks[1].Elements[0].(*slack.Text).Text)
+	cards := msg.Blocks[2].Elements
+	if asserter.Len(cards, 2) {
+		asserter.Equal("vote:1", cards[0].(slack.Button).ActionID)
+		asserter.Equal("abcdefg", cards[0].(slack.Button).Value)
+	}
+	asserter.Equal("reveal", msg.Blocks[3].Elements[0].(slack.Button).ActionID)
+}
+
+func TestResultsMessage_Anonymous(t *testing.T) {
+	asserter := assert.New(t)
+
+	sess := baseSession()
+	sess.VotesShown = true
+	sess.AnonymousReveal = true
+	sess.Facilitator.CurrentVote = aws.String("5")
+	sess.Participants[0].CurrentVote = aws.String("5")
+	msg := slack.ResultsMessage(sess)
+
+	asserter.Equal("5, 5", msg.Blocks[1].Text.Text)
+	asserter.NotContains(msg.Blocks[1].Text.Text, "U1")
+	asserter.Equal("Everyone agreed on 5", msg.Blocks[2].Elements[0].(*slack.Text).Text)
+}
@@ -0,0 +1,25 @@
+package slack
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/jonsabados/pointypoints/session"
+)
+
+// NewMessageNotifier keeps the message posted for sessions started from slack in step with the session, so reveals
+// from the web or timers show up in the channel too. Sessions that didn't come from slack are left alone.
+func NewMessageNotifier(client Client) session.ChangeNotifier {
+	return func(ctx context.Context, updated session.CompleteSessionView) error {
+		posted := updated.IntegrationMessage
+		if posted == nil || posted.Provider != IntegrationName {
+			return nil
+		}
+		msg := VotingMessage(updated)
+		if updated.VotesShown {
+			msg = ResultsMessage(updated)
+		}
+		return errors.WithStack(client.UpdateMessage(ctx, posted.Channel, posted.MessageID, msg))
+	}
+}
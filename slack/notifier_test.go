@@ -0,0 +1,53 @@
+package slack_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jonsabados/pointypoints/session"
+	"github.com/jonsabados/pointypoints/session/testutil"
+	"github.com/jonsabados/pointypoints/slack"
+	slacktestutil "github.com/jonsabados/pointypoints/slack/testutil"
+)
+
+func TestNewMessageNotifier(t *testing.T) {
+	sess := session.CompleteSessionView{
+		SessionID:   "abcdefg",
+		Facilitator: session.User{UserID: "slack:T1:U1", Name: "fred"},
+		IntegrationMessage: &session.IntegrationMessage{
+			Provider:  slack.IntegrationName,
+			Channel:   "C1",
+			MessageID: "1600000000.000001",
+		},
+	}
+
+	t.Run("voting", func(t *testing.T) {
+		asserter := assert.New(t)
+
+		client := &slacktestutil.FakeClient{}
+		err := slack.NewMessageNotifier(client)(testutil.NewTestContext(), sess)
+		asserter.NoError(err)
+		asserter.Equal([]slacktestutil.PostedMessage{{Channel: "C1", TS: "1600000000.000001", Message: slack.VotingMessage(sess)}}, client.Updated)
+	})
+
+	t.Run("revealed", func(t *testing.T) {
+		asserter := assert.New(t)
+
+		revealed := sess
+		revealed.VotesShown = true
+		client := &slacktestutil.FakeClient{}
+		err := slack.NewMessageNotifier(client)(testutil.NewTestContext(), revealed)
+		asserter.NoError(err)
+		asserter.Equal([]slacktestutil.PostedMessage{{Channel: "C1", TS: "1600000000.000001", Message: slack.ResultsMessage(revealed)}}, client.Updated)
+	})
+
+	t.Run("not from slack", func(t *testing.T) {
+		asserter := assert.New(t)
+
+		client := &slacktestutil.FakeClient{}
+		err := slack.NewMessageNotifier(client)(testutil.NewTestContext(), session.CompleteSessionView{SessionID: "abcdefg", VotesShown: true})
+		asserter.NoError(err)
+		asserter.Empty(client.Updated)
+	})
+}
@@ -0,0 +1,137 @@
+package slack
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jonsabados/goauth"
+	"github.com/pkg/errors"
+
+	"github.com/jonsabados/pointypoints/session"
+)
+
+const (
+	IntegrationName = "slack"
+
+	SignatureHeader = "X-Slack-Signature"
+	TimestampHeader = "X-Slack-Request-Timestamp"
+
+	// MaxRequestAge is how old a signed request can be before it is treated as a replay
+	MaxRequestAge = 5 * time.Minute
+)
+
+var ErrorInvalidSignature = errors.New("invalid slack signature")
+
+// VerifySignature checks that a request really came from slack, see https://api.slack.com/authentication/verifying-requests-from-slack
+func VerifySignature(signingSecret string, headers map[string]string, body string, now time.Time) error {
+	timestamp, err := strconv.ParseInt(header(headers, TimestampHeader), 10, 64)
+	if err != nil {
+		return ErrorInvalidSignature
+	}
+	age := now.Sub(time.Unix(timestamp, 0))
+	if age > MaxRequestAge || age < -MaxRequestAge {
+		return ErrorInvalidSignature
+	}
+	if !hmac.Equal([]byte(header(headers, SignatureHeader)), []byte(Sign(signingSecret, timestamp, body))) {
+		return ErrorInvalidSignature
+	}
+	return nil
+}
+
+func Sign(signingSecret string, timestamp int64, body string) string {
+	mac := hmac.New(sha256.New, []byte(signingSecret))
+	mac.Write([]byte(fmt.Sprintf("v0:%d:%s", timestamp, body)))
+	return fmt.Sprintf("v0=%s", hex.EncodeToString(mac.Sum(nil)))
+}
+
+func header(headers map[string]string, name string) string {
+	for k, v := range headers {
+		if strings.EqualFold(k, name) {
+			return v
+		}
+	}
+	return ""
+}
+
+type SlashCommand struct {
+	TeamID    string
+	ChannelID string
+	UserID    string
+	UserName  string
+	Command   string
+	Text      string
+}
+
+func ParseSlashCommand(form url.Values) SlashCommand {
+	return SlashCommand{
+		TeamID:    form.Get("team_id"),
+		ChannelID: form.Get("channel_id"),
+		UserID:    form.Get("user_id"),
+		UserName:  form.Get("user_name"),
+		Command:   form.Get("command"),
+		Text:      strings.TrimSpace(form.Get("text")),
+	}
+}
+
+type Action struct {
+	ActionID string `json:"action_id"`
+	Value    string `json:"value"`
+}
+
+// Interaction is what slack sends when someone clicks a button on one of our messages
+type Interaction struct {
+	Type string `json:"type"`
+	Team struct {
+		ID string `json:"id"`
+	} `json:"team"`
+	User struct {
+		ID       string `json:"id"`
+		Username string `json:"username"`
+		Name     string `json:"name"`
+	} `json:"user"`
+	Channel struct {
+		ID string `json:"id"`
+	} `json:"channel"`
+	Message struct {
+		TS string `json:"ts"`
+	} `json:"message"`
+	Actions []Action `json:"actions"`
+}
+
+// ParseInteraction reads an interaction, which slack sends as json in the payload field of a form post
+func ParseInteraction(form url.Values) (Interaction, error) {
+	ret := Interaction{}
+	err := json.Unmarshal([]byte(form.Get("payload")), &ret)
+	return ret, errors.Wrap(err, "error reading interaction payload")
+}
+
+// Participant maps a slack user onto the principal and session user they act as. Slack user ids are only unique within
+// a workspace, so the team is folded in.
+func Participant(teamID string, userID string, name string) (goauth.Principal, session.User) {
+	id := session.IntegrationSocketID(IntegrationName, fmt.Sprintf("%s:%s", teamID, userID))
+	principal := goauth.Principal{
+		UserID: id,
+		Name:   name,
+	}
+	user := session.User{
+		UserID:   id,
+		Name:     name,
+		Handle:   name,
+		SocketID: id,
+	}
+	return principal, user
+}
+
+// Client is the slice of the slack web api needed to keep a channel up to date with a session
+type Client interface {
+	PostMessage(ctx context.Context, channel string, msg Message) (string, error)
+	UpdateMessage(ctx context.Context, channel string, ts string, msg Message) error
+}
@@ -0,0 +1,138 @@
+package slack_test
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jonsabados/pointypoints/slack"
+)
+
+// the example from https://api.slack.com/authentication/verifying-requests-from-slack
+const (
+	exampleSecret    = "8f742231b10e8888abcd99yyyzzz85a5"
+	exampleTimestamp = 1531420618
+	exampleBody      = "token=xyzz0WbapA4vBCDEFasx0q6G&team_id=T1DC2JH3J&team_domain=testteamnow&channel_id=G8PSS9T3V&channel_name=foobar&user_id=U2CERLKJA&user_name=roadrunner&command=%2Fwebhook-collect&text=&response_url=https%3A%2F%2Fhooks.slack.com%2Fcommands%2FT1DC2JH3J%2F397700885554%2F96rGlfmibIGlgcZRskXaIFfN&trigger_id=398738663015.47445629121.803a0bc887a14d10d2c447fce8b6703c"
+	exampleSignature = "v0=a2114d57b48eac39b9ad189dd8316235a7b4a8d21a10bd27519666489c69b503"
+)
+
+func TestVerifySignature(t *testing.T) {
+	requestTime := time.Unix(exampleTimestamp, 0)
+
+	testCases := []struct {
+		name     string
+		headers  map[string]string
+		body     string
+		now      time.Time
+		expected error
+	}{
+		{
+			name: "valid",
+			headers: map[string]string{
+				"X-Slack-Signature":         exampleSignature,
+				"X-Slack-Request-Timestamp": "1531420618",
+			},
+			body: exampleBody,
+			now:  requestTime.Add(time.Minute),
+		},
+		{
+			name: "header case ignored",
+			headers: map[string]string{
+				"x-slack-signature":         exampleSignature,
+				"x-slack-request-timestamp": "1531420618",
+			},
+			body: exampleBody,
+			now:  requestTime,
+		},
+		{
+			name: "tampered body",
+			headers: map[string]string{
+				"X-Slack-Signature":         exampleSignature,
+				"X-Slack-Request-Timestamp": "1531420618",
+			},
+			body:     exampleBody + "&text=sneaky",
+			now:      requestTime,
+			expected: slack.ErrorInvalidSignature,
+		},
+		{
+			name: "too old",
+			headers: map[string]string{
+				"X-Slack-Signature":         exampleSignature,
+				"X-Slack-Request-Timestamp": "1531420618",
+			},
+			body:     exampleBody,
+			now:      requestTime.Add(6 * time.Minute),
+			expected: slack.ErrorInvalidSignature,
+		},
+		{
+			name: "from the future",
+			headers: map[string]string{
+				"X-Slack-Signature":         exampleSignature,
+				"X-Slack-Request-Timestamp": "1531420618",
+			},
+			body:     exampleBody,
+			now:      requestTime.Add(-6 * time.Minute),
+			expected: slack.ErrorInvalidSignature,
+		},
+		{
+			name:     "missing headers",
+			headers:  map[string]string{},
+			body:     exampleBody,
+			now:      requestTime,
+			expected: slack.ErrorInvalidSignature,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, slack.VerifySignature(exampleSecret, tc.headers, tc.body, tc.now))
+		})
+	}
+}
+
+func TestParseSlashCommand(t *testing.T) {
+	form, err := url.ParseQuery("team_id=T1&channel_id=C1&user_id=U1&user_name=bob&command=%2Fpoint&text=+PP-12+login+page+")
+	assert.NoError(t, err)
+
+	assert.Equal(t, slack.SlashCommand{
+		TeamID:    "T1",
+		ChannelID: "C1",
+		UserID:    "U1",
+		UserName:  "bob",
+		Command:   "/point",
+		Text:      "PP-12 login page",
+	}, slack.ParseSlashCommand(form))
+}
+
+func TestParseInteraction(t *testing.T) {
+	asserter := assert.New(t)
+
+	form := url.Values{}
+	form.Set("payload", `{"type":"block_actions","team":{"id":"T1"},"user":{"id":"U1","username":"bob","name":"bob"},"channel":{"id":"C1"},"message":{"ts":"1600000000.000001"},"actions":[{"action_id":"vote:5","value":"abcdefg"}]}`)
+
+	result, err := slack.ParseInteraction(form)
+	asserter.NoError(err)
+	asserter.Equal("T1", result.Team.ID)
+	asserter.Equal("U1", result.User.ID)
+	asserter.Equal("bob", result.User.Username)
+	asserter.Equal("C1", result.Channel.ID)
+	asserter.Equal("1600000000.000001", result.Message.TS)
+	asserter.Equal([]slack.Action{{ActionID: "vote:5", Value: "abcdefg"}}, result.Actions)
+
+	_, err = slack.ParseInteraction(url.Values{})
+	asserter.Error(err)
+}
+
+func TestParticipant(t *testing.T) {
+	asserter := assert.New(t)
+
+	principal, user := slack.Participant("T1", "U1", "bob")
+	asserter.Equal("slack:T1:U1", principal.UserID)
+	asserter.Equal("bob", principal.Name)
+	asserter.Equal("slack:T1:U1", user.UserID)
+	asserter.Equal("slack:T1:U1", user.SocketID)
+	asserter.Equal("bob", user.Name)
+	asserter.Equal("bob", user.Handle)
+}
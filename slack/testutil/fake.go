@@ -0,0 +1,46 @@
+package testutil
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jonsabados/pointypoints/slack"
+)
+
+type PostedMessage struct {
+	Channel string
+	TS      string
+	Message slack.Message
+}
+
+// FakeClient stands in for slack, keeping track of what would have been sent to channels
+type FakeClient struct {
+	Posted  []PostedMessage
+	Updated []PostedMessage
+	Err     error
+}
+
+func (f *FakeClient) PostMessage(ctx context.Context, channel string, msg slack.Message) (string, error) {
+	if f.Err != nil {
+		return "", f.Err
+	}
+	ts := fmt.Sprintf("1600000000.%06d", len(f.Posted)+1)
+	f.Posted = append(f.Posted, PostedMessage{
+		Channel: channel,
+		TS:      ts,
+		Message: msg,
+	})
+	return ts, nil
+}
+
+func (f *FakeClient) UpdateMessage(ctx context.Context, channel string, ts string, msg slack.Message) error {
+	if f.Err != nil {
+		return f.Err
+	}
+	f.Updated = append(f.Updated, PostedMessage{
+		Channel: channel,
+		TS:      ts,
+		Message: msg,
+	})
+	return nil
+}
@@ -0,0 +1,219 @@
+package team
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+const (
+	teamRecordRangeKeyValue    = "team"
+	memberRecordRangeKeyPrefix = "member:"
+)
+
+type DynamoClient interface {
+	QueryWithContext(ctx aws.Context, input *dynamodb.QueryInput, opts ...request.Option) (*dynamodb.QueryOutput, error)
+	TransactWriteItemsWithContext(ctx aws.Context, input *dynamodb.TransactWriteItemsInput, opts ...request.Option) (*dynamodb.TransactWriteItemsOutput, error)
+}
+
+// Saver writes a team along with its roster, removing anyone no longer on it
+type Saver func(ctx context.Context, t Team, removedMembers []string) error
+
+func NewSaver(dynamo DynamoClient, tableName string) Saver {
+	return func(ctx context.Context, t Team, removedMembers []string) error {
+		item := map[string]*dynamodb.AttributeValue{
+			"TeamID":            {S: aws.String(t.ID)},
+			"RangeKey":          {S: aws.String(teamRecordRangeKeyValue)},
+			"TeamName":          {S: aws.String(t.Name)},
+			"FacilitatorPoints": {BOOL: aws.Bool(t.FacilitatorPoints)},
+			"CreatedAt":         {N: aws.String(strconv.FormatInt(t.CreatedAt.Unix(), 10))},
+		}
+		if len(t.DefaultDeck) > 0 {
+			cards := make([]*dynamodb.AttributeValue, len(t.DefaultDeck))
+			for i, c := range t.DefaultDeck {
+				cards[i] = &dynamodb.AttributeValue{S: aws.String(c)}
+			}
+			item["DefaultDeck"] = &dynamodb.AttributeValue{L: cards}
+		}
+
+		actions := []*dynamodb.TransactWriteItem{
+			{
+				Put: &dynamodb.Put{
+					TableName: aws.String(tableName),
+					Item:      item,
+				},
+			},
+		}
+		for _, m := range t.Members {
+			// the team name rides along on member records so the membership index can list teams without more reads
+			member := map[string]*dynamodb.AttributeValue{
+				"TeamID":   {S: aws.String(t.ID)},
+				"RangeKey": {S: aws.String(memberRecordRangeKeyPrefix + m.Email)},
+				"Email":    {S: aws.String(m.Email)},
+				"Role":     {S: aws.String(string(m.Role))},
+				"TeamName": {S: aws.String(t.Name)},
+			}
+			if m.Name != "" {
+				member["MemberName"] = &dynamodb.AttributeValue{S: aws.String(m.Name)}
+			}
+			actions = append(actions, &dynamodb.TransactWriteItem{
+				Put: &dynamodb.Put{
+					TableName: aws.String(tableName),
+					Item:      member,
+				},
+			})
+		}
+		for _, email := range removedMembers {
+			actions = append(actions, &dynamodb.TransactWriteItem{
+				Delete: &dynamodb.Delete{
+					TableName: aws.String(tableName),
+					Key: map[string]*dynamodb.AttributeValue{
+						"TeamID":   {S: aws.String(t.ID)},
+						"RangeKey": {S: aws.String(memberRecordRangeKeyPrefix + email)},
+					},
+				},
+			})
+		}
+
+		// big rosters take more than one transaction, every action is a plain put or delete so a failed save can just be
+		// retried
+		return errors.Wrap(transactInChunks(ctx, dynamo, actions), "error saving team")
+	}
+}
+
+type Loader func(ctx context.Context, teamID string) (*Team, error)
+
+func NewLoader(dynamo DynamoClient, tableName string) Loader {
+	return func(ctx context.Context, teamID string) (*Team, error) {
+		items, err := queryAll(ctx, dynamo, &dynamodb.QueryInput{
+			TableName: aws.String(tableName),
+			KeyConditions: map[string]*dynamodb.Condition{
+				"TeamID": {
+					ComparisonOperator: aws.String("EQ"),
+					AttributeValueList: []*dynamodb.AttributeValue{
+						{S: aws.String(teamID)},
+					},
+				},
+			},
+			ConsistentRead: aws.Bool(true),
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "error reading team")
+		}
+
+		var ret *Team
+		members := make([]Member, 0)
+		for _, item := range items {
+			rangeKey := *item["RangeKey"].S
+			if rangeKey == teamRecordRangeKeyValue {
+				createdAt, _ := strconv.ParseInt(*item["CreatedAt"].N, 10, 64)
+				ret = &Team{
+					ID:                teamID,
+					Name:              *item["TeamName"].S,
+					FacilitatorPoints: *item["FacilitatorPoints"].BOOL,
+					CreatedAt:         time.Unix(createdAt, 0).UTC(),
+				}
+				if deck, ok := item["DefaultDeck"]; ok {
+					for _, c := range deck.L {
+						ret.DefaultDeck = append(ret.DefaultDeck, *c.S)
+					}
+				}
+			} else if strings.HasPrefix(rangeKey, memberRecordRangeKeyPrefix) {
+				m := Member{
+					Email: *item["Email"].S,
+					Role:  Role(*item["Role"].S),
+				}
+				if name, ok := item["MemberName"]; ok {
+					m.Name = *name.S
+				}
+				members = append(members, m)
+			} else {
+				zerolog.Ctx(ctx).Warn().Interface("record", item).Msg("unexpected record spotted")
+			}
+		}
+		if ret == nil {
+			return nil, nil
+		}
+		ret.Members = members
+		return ret, nil
+	}
+}
+
+// MembershipLister returns the teams someone belongs to
+type MembershipLister func(ctx context.Context, email string) ([]Membership, error)
+
+func NewMembershipLister(dynamo DynamoClient, tableName string, indexName string) MembershipLister {
+	return func(ctx context.Context, email string) ([]Membership, error) {
+		items, err := queryAll(ctx, dynamo, &dynamodb.QueryInput{
+			TableName: aws.String(tableName),
+			IndexName: aws.String(indexName),
+			KeyConditions: map[string]*dynamodb.Condition{
+				"Email": {
+					ComparisonOperator: aws.String("EQ"),
+					AttributeValueList: []*dynamodb.AttributeValue{
+						{S: aws.String(normalizeEmail(email))},
+					},
+				},
+			},
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "error reading team memberships")
+		}
+		ret := make([]Membership, len(items))
+		for i, item := range items {
+			ret[i] = Membership{
+				TeamID:   *item["TeamID"].S,
+				TeamName: *item["TeamName"].S,
+				Role:     Role(*item["Role"].S),
+			}
+		}
+		return ret, nil
+	}
+}
+
+// maxTransactItems is the most actions DynamoDB will accept in a single TransactWriteItems call
+const maxTransactItems = 100
+
+// transactInChunks writes actions in as few transactions as it can, in order. Each chunk is atomic but the write as a
+// whole is not.
+func transactInChunks(ctx context.Context, dynamo DynamoClient, actions []*dynamodb.TransactWriteItem) error {
+	for start := 0; start < len(actions); start += maxTransactItems {
+		end := start + maxTransactItems
+		if end > len(actions) {
+			end = len(actions)
+		}
+		_, err := dynamo.TransactWriteItemsWithContext(ctx, &dynamodb.TransactWriteItemsInput{
+			TransactItems: actions[start:end],
+		})
+		if err != nil {
+			return errors.Wrapf(err, "error writing records %d through %d of %d", start, end, len(actions))
+		}
+	}
+	return nil
+}
+
+// queryAll runs a query to completion, following LastEvaluatedKey until every page has been read
+func queryAll(ctx context.Context, dynamo DynamoClient, input *dynamodb.QueryInput) ([]map[string]*dynamodb.AttributeValue, error) {
+	var ret []map[string]*dynamodb.AttributeValue
+	page := input
+	for {
+		res, err := dynamo.QueryWithContext(ctx, page)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		ret = append(ret, res.Items...)
+		if len(res.LastEvaluatedKey) == 0 {
+			return ret, nil
+		}
+		next := *input
+		next.ExclusiveStartKey = res.LastEvaluatedKey
+		page = &next
+	}
+}
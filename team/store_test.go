@@ -0,0 +1,196 @@
+package team_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/jonsabados/pointypoints/session/testutil"
+	"github.com/jonsabados/pointypoints/team"
+)
+
+var emptyOpts []request.Option
+
+func TestTeam_RoundTrip(t *testing.T) {
+	asserter := assert.New(t)
+
+	ctx := testutil.NewTestContext()
+	dynamo := &testutil.MockDynamoClient{}
+	tableName := "teams"
+
+	toSave := team.Team{
+		ID:                "team1",
+		Name:              "Platform",
+		DefaultDeck:       []string{"S", "M", "L"},
+		FacilitatorPoints: true,
+		Members: []team.Member{
+			{Email: "bob@example.com", Name: "Bob", Role: team.RoleFacilitator},
+			{Email: "sue@example.com", Role: team.RoleMember},
+		},
+		CreatedAt: time.Unix(5000, 0).UTC(),
+	}
+
+	var saved *dynamodb.TransactWriteItemsInput
+	dynamo.On("TransactWriteItemsWithContext", ctx, mock.Anything, emptyOpts).Run(func(args mock.Arguments) {
+		saved = args.Get(1).(*dynamodb.TransactWriteItemsInput)
+	}).Return(&dynamodb.TransactWriteItemsOutput{}, nil)
+
+	err := team.NewSaver(dynamo, tableName)(ctx, toSave, []string{"joe@example.com"})
+	asserter.NoError(err)
+
+	items := make([]map[string]*dynamodb.AttributeValue, 0)
+	if asserter.Len(saved.TransactItems, 4) {
+		for _, i := range saved.TransactItems[:3] {
+			asserter.Equal(tableName, *i.Put.TableName)
+			items = append(items, i.Put.Item)
+		}
+		asserter.Equal(&dynamodb.Delete{
+			TableName: aws.String(tableName),
+			Key: map[string]*dynamodb.AttributeValue{
+				"TeamID":   {S: aws.String("team1")},
+				"RangeKey": {S: aws.String("member:joe@example.com")},
+			},
+		}, saved.TransactItems[3].Delete)
+	}
+	asserter.Equal("member:bob@example.com", *items[1]["RangeKey"].S)
+	asserter.Equal("Platform", *items[1]["TeamName"].S)
+
+	dynamo.On("QueryWithContext", ctx, &dynamodb.QueryInput{
+		TableName: aws.String(tableName),
+		KeyConditions: map[string]*dynamodb.Condition{
+			"TeamID": {
+				ComparisonOperator: aws.String("EQ"),
+				AttributeValueList: []*dynamodb.AttributeValue{
+					{S: aws.String("team1")},
+				},
+			},
+		},
+		ConsistentRead: aws.Bool(true),
+	}, emptyOpts).Return(&dynamodb.QueryOutput{Items: items}, nil)
+
+	loaded, err := team.NewLoader(dynamo, tableName)(ctx, "team1")
+	asserter.NoError(err)
+	asserter.Equal(&toSave, loaded)
+}
+
+func TestNewSaver_ChunksLargeRosters(t *testing.T) {
+	asserter := assert.New(t)
+
+	ctx := testutil.NewTestContext()
+	dynamo := &testutil.MockDynamoClient{}
+
+	toSave := team.Team{ID: "team1", Name: "Everyone"}
+	for i := 0; i < 150; i++ {
+		toSave.Members = append(toSave.Members, team.Member{Email: fmt.Sprintf("%d@example.com", i), Role: team.RoleMember})
+	}
+
+	var chunks []int
+	dynamo.On("TransactWriteItemsWithContext", ctx, mock.Anything, emptyOpts).Run(func(args mock.Arguments) {
+		chunks = append(chunks, len(args.Get(1).(*dynamodb.TransactWriteItemsInput).TransactItems))
+	}).Return(&dynamodb.TransactWriteItemsOutput{}, nil)
+
+	err := team.NewSaver(dynamo, "teams")(ctx, toSave, []string{"gone@example.com"})
+	asserter.NoError(err)
+	asserter.Equal([]int{100, 52}, chunks)
+}
+
+func TestNewLoader_MultiplePages(t *testing.T) {
+	asserter := assert.New(t)
+
+	ctx := testutil.NewTestContext()
+	dynamo := &testutil.MockDynamoClient{}
+	lastKey := map[string]*dynamodb.AttributeValue{"TeamID": {S: aws.String("team1")}, "RangeKey": {S: aws.String("member:a@example.com")}}
+	dynamo.On("QueryWithContext", ctx, mock.MatchedBy(func(in *dynamodb.QueryInput) bool {
+		return in.ExclusiveStartKey == nil
+	}), emptyOpts).Return(&dynamodb.QueryOutput{
+		Items: []map[string]*dynamodb.AttributeValue{
+			{
+				"TeamID":            {S: aws.String("team1")},
+				"RangeKey":          {S: aws.String("team")},
+				"TeamName":          {S: aws.String("Platform")},
+				"FacilitatorPoints": {BOOL: aws.Bool(false)},
+				"CreatedAt":         {N: aws.String("5000")},
+			},
+			{
+				"TeamID":   {S: aws.String("team1")},
+				"RangeKey": {S: aws.String("member:a@example.com")},
+				"Email":    {S: aws.String("a@example.com")},
+				"Role":     {S: aws.String("member")},
+			},
+		},
+		LastEvaluatedKey: lastKey,
+	}, nil)
+	dynamo.On("QueryWithContext", ctx, mock.MatchedBy(func(in *dynamodb.QueryInput) bool {
+		return in.ExclusiveStartKey != nil
+	}), emptyOpts).Return(&dynamodb.QueryOutput{
+		Items: []map[string]*dynamodb.AttributeValue{
+			{
+				"TeamID":   {S: aws.String("team1")},
+				"RangeKey": {S: aws.String("member:b@example.com")},
+				"Email":    {S: aws.String("b@example.com")},
+				"Role":     {S: aws.String("member")},
+			},
+		},
+	}, nil)
+
+	loaded, err := team.NewLoader(dynamo, "teams")(ctx, "team1")
+	asserter.NoError(err)
+	if asserter.NotNil(loaded) {
+		asserter.Equal([]team.Member{
+			{Email: "a@example.com", Role: team.RoleMember},
+			{Email: "b@example.com", Role: team.RoleMember},
+		}, loaded.Members)
+	}
+	dynamo.AssertExpectations(t)
+}
+
+func TestNewLoader_NotFound(t *testing.T) {
+	asserter := assert.New(t)
+
+	ctx := testutil.NewTestContext()
+	dynamo := &testutil.MockDynamoClient{}
+	dynamo.On("QueryWithContext", ctx, mock.Anything, emptyOpts).Return(&dynamodb.QueryOutput{}, nil)
+
+	loaded, err := team.NewLoader(dynamo, "teams")(ctx, "team1")
+	asserter.NoError(err)
+	asserter.Nil(loaded)
+}
+
+func TestNewMembershipLister(t *testing.T) {
+	asserter := assert.New(t)
+
+	ctx := testutil.NewTestContext()
+	dynamo := &testutil.MockDynamoClient{}
+	dynamo.On("QueryWithContext", ctx, &dynamodb.QueryInput{
+		TableName: aws.String("teams"),
+		IndexName: aws.String("members"),
+		KeyConditions: map[string]*dynamodb.Condition{
+			"Email": {
+				ComparisonOperator: aws.String("EQ"),
+				AttributeValueList: []*dynamodb.AttributeValue{
+					{S: aws.String("bob@example.com")},
+				},
+			},
+		},
+	}, emptyOpts).Return(&dynamodb.QueryOutput{
+		Items: []map[string]*dynamodb.AttributeValue{
+			{
+				"TeamID":   {S: aws.String("team1")},
+				"RangeKey": {S: aws.String("member:bob@example.com")},
+				"Email":    {S: aws.String("bob@example.com")},
+				"TeamName": {S: aws.String("Platform")},
+				"Role":     {S: aws.String("facilitator")},
+			},
+		},
+	}, nil)
+
+	result, err := team.NewMembershipLister(dynamo, "teams", "members")(ctx, "Bob@Example.com")
+	asserter.NoError(err)
+	asserter.Equal([]team.Membership{{TeamID: "team1", TeamName: "Platform", Role: team.RoleFacilitator}}, result)
+}
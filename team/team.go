@@ -0,0 +1,175 @@
+package team
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jonsabados/goauth"
+
+	"github.com/jonsabados/pointypoints/api"
+	"github.com/jonsabados/pointypoints/session"
+)
+
+type Role string
+
+const (
+	// RoleFacilitator members can run sessions for the team and manage it
+	RoleFacilitator = Role("facilitator")
+	RoleMember      = Role("member")
+)
+
+func (r Role) Valid() bool {
+	return r == RoleFacilitator || r == RoleMember
+}
+
+// Member is keyed on email rather than user id since that's what people know each other by
+type Member struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+	Role  Role   `json:"role"`
+}
+
+type Team struct {
+	ID                string    `json:"id"`
+	Name              string    `json:"name"`
+	DefaultDeck       []string  `json:"defaultDeck,omitempty"`
+	FacilitatorPoints bool      `json:"facilitatorPoints"`
+	Members           []Member  `json:"members"`
+	CreatedAt         time.Time `json:"createdAt"`
+}
+
+// SaveRequest is used both to create a team and to replace its settings and roster
+type SaveRequest struct {
+	Name              string   `json:"name"`
+	DefaultDeck       []string `json:"defaultDeck,omitempty"`
+	FacilitatorPoints bool     `json:"facilitatorPoints"`
+	Members           []Member `json:"members"`
+}
+
+// Membership is a team as seen from one of its members
+type Membership struct {
+	TeamID   string `json:"teamId"`
+	TeamName string `json:"teamName"`
+	Role     Role   `json:"role"`
+}
+
+func Validate(r SaveRequest) []api.FieldValidationError {
+	ret := make([]api.FieldValidationError, 0)
+	if strings.TrimSpace(r.Name) == "" {
+		ret = append(ret, api.FieldValidationError{
+			Field: "name",
+			Error: "is required",
+		})
+	}
+	for _, c := range r.DefaultDeck {
+		if strings.TrimSpace(c) == "" {
+			ret = append(ret, api.FieldValidationError{
+				Field: "defaultDeck",
+				Error: "cards can not be blank",
+			})
+			break
+		}
+	}
+	seen := make(map[string]bool)
+	for _, m := range r.Members {
+		email := normalizeEmail(m.Email)
+		if email == "" || !strings.Contains(email, "@") {
+			ret = append(ret, api.FieldValidationError{
+				Field: "members",
+				Error: "must have a valid email",
+			})
+		} else if seen[email] {
+			ret = append(ret, api.FieldValidationError{
+				Field: "members",
+				Error: "duplicate member " + email,
+			})
+		}
+		seen[email] = true
+		if !m.Role.Valid() {
+			ret = append(ret, api.FieldValidationError{
+				Field: "members",
+				Error: "unknown role " + string(m.Role),
+			})
+		}
+	}
+	return ret
+}
+
+// NewTeam sets up a team from a request, making sure whoever created it is able to manage it
+func NewTeam(creator goauth.Principal, r SaveRequest, now time.Time) Team {
+	ret := Team{
+		ID:        uuid.New().String(),
+		CreatedAt: now.UTC().Truncate(time.Second),
+	}
+	ret = Apply(ret, r)
+	if m := ret.Member(creator.Email); m != nil {
+		m.Role = RoleFacilitator
+	} else {
+		ret.Members = append(ret.Members, Member{
+			Email: normalizeEmail(creator.Email),
+			Name:  creator.Name,
+			Role:  RoleFacilitator,
+		})
+	}
+	return ret
+}
+
+// Apply replaces a teams settings and roster with those in the request
+func Apply(t Team, r SaveRequest) Team {
+	t.Name = strings.TrimSpace(r.Name)
+	t.DefaultDeck = r.DefaultDeck
+	t.FacilitatorPoints = r.FacilitatorPoints
+	t.Members = make([]Member, len(r.Members))
+	for i, m := range r.Members {
+		m.Email = normalizeEmail(m.Email)
+		t.Members[i] = m
+	}
+	return t
+}
+
+func (t *Team) Member(email string) *Member {
+	email = normalizeEmail(email)
+	for i := range t.Members {
+		if t.Members[i].Email == email {
+			return &t.Members[i]
+		}
+	}
+	return nil
+}
+
+func (t Team) IsMember(email string) bool {
+	return t.Member(email) != nil
+}
+
+func (t Team) CanFacilitate(email string) bool {
+	m := t.Member(email)
+	return m != nil && m.Role == RoleFacilitator
+}
+
+// ApplyDefaults fills in anything a session start request leaves up to the team
+func ApplyDefaults(t Team, r *session.StartRequest) {
+	r.TeamID = t.ID
+	if len(r.Deck) == 0 {
+		r.Deck = t.DefaultDeck
+	}
+	if r.FacilitatorPoints == nil {
+		facilitatorPoints := t.FacilitatorPoints
+		r.FacilitatorPoints = &facilitatorPoints
+	}
+}
+
+// RemovedMembers returns the emails of anyone dropped from a team's roster
+func RemovedMembers(previous Team, updated Team) []string {
+	ret := make([]string, 0)
+	for _, m := range previous.Members {
+		if !updated.IsMember(m.Email) {
+			ret = append(ret, m.Email)
+		}
+	}
+	return ret
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
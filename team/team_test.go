@@ -0,0 +1,147 @@
+package team_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jonsabados/goauth"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jonsabados/pointypoints/api"
+	"github.com/jonsabados/pointypoints/session"
+	"github.com/jonsabados/pointypoints/team"
+)
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		name     string
+		request  team.SaveRequest
+		expected []api.FieldValidationError
+	}{
+		{
+			name: "valid",
+			request: team.SaveRequest{
+				Name:        "Platform",
+				DefaultDeck: []string{"1", "2", "3"},
+				Members: []team.Member{
+					{Email: "bob@example.com", Role: team.RoleFacilitator},
+					{Email: "sue@example.com", Role: team.RoleMember},
+				},
+			},
+			expected: []api.FieldValidationError{},
+		},
+		{
+			name: "everything wrong",
+			request: team.SaveRequest{
+				Name:        " ",
+				DefaultDeck: []string{"1", ""},
+				Members: []team.Member{
+					{Email: "bob", Role: team.RoleMember},
+					{Email: "sue@example.com", Role: "boss"},
+					{Email: "SUE@example.com", Role: team.RoleMember},
+				},
+			},
+			expected: []api.FieldValidationError{
+				{Field: "name", Error: "is required"},
+				{Field: "defaultDeck", Error: "cards can not be blank"},
+				{Field: "members", Error: "must have a valid email"},
+				{Field: "members", Error: "unknown role boss"},
+				{Field: "members", Error: "duplicate member sue@example.com"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, team.Validate(tc.request))
+		})
+	}
+}
+
+func TestNewTeam(t *testing.T) {
+	creator := goauth.Principal{UserID: "123", Email: "Bob@Example.com", Name: "Bob"}
+	now := time.Date(2021, 3, 4, 5, 6, 7, 8, time.UTC)
+
+	t.Run("creator added", func(t *testing.T) {
+		asserter := assert.New(t)
+
+		result := team.NewTeam(creator, team.SaveRequest{
+			Name:    " Platform ",
+			Members: []team.Member{{Email: "sue@example.com", Role: team.RoleMember}},
+		}, now)
+		asserter.NotEmpty(result.ID)
+		asserter.Equal("Platform", result.Name)
+		asserter.Equal(time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC), result.CreatedAt)
+		asserter.Equal([]team.Member{
+			{Email: "sue@example.com", Role: team.RoleMember},
+			{Email: "bob@example.com", Name: "Bob", Role: team.RoleFacilitator},
+		}, result.Members)
+	})
+
+	t.Run("creator promoted", func(t *testing.T) {
+		result := team.NewTeam(creator, team.SaveRequest{
+			Name:    "Platform",
+			Members: []team.Member{{Email: "bob@example.com", Name: "Bobby", Role: team.RoleMember}},
+		}, now)
+		assert.Equal(t, []team.Member{{Email: "bob@example.com", Name: "Bobby", Role: team.RoleFacilitator}}, result.Members)
+	})
+}
+
+func TestTeam_Roles(t *testing.T) {
+	asserter := assert.New(t)
+
+	tm := team.Team{Members: []team.Member{
+		{Email: "bob@example.com", Role: team.RoleFacilitator},
+		{Email: "sue@example.com", Role: team.RoleMember},
+	}}
+	asserter.True(tm.IsMember("SUE@example.com"))
+	asserter.False(tm.CanFacilitate("sue@example.com"))
+	asserter.True(tm.CanFacilitate("bob@example.com"))
+	asserter.False(tm.IsMember("joe@example.com"))
+	asserter.False(tm.CanFacilitate("joe@example.com"))
+}
+
+func TestApplyDefaults(t *testing.T) {
+	tm := team.Team{
+		ID:                "team1",
+		DefaultDeck:       []string{"S", "M", "L"},
+		FacilitatorPoints: true,
+	}
+
+	t.Run("defaults used", func(t *testing.T) {
+		asserter := assert.New(t)
+
+		r := session.StartRequest{}
+		team.ApplyDefaults(tm, &r)
+		asserter.Equal("team1", r.TeamID)
+		asserter.Equal([]string{"S", "M", "L"}, r.Deck)
+		asserter.True(*r.FacilitatorPoints)
+	})
+
+	t.Run("request wins", func(t *testing.T) {
+		asserter := assert.New(t)
+
+		facilitatorPoints := false
+		r := session.StartRequest{
+			FacilitatorPoints: &facilitatorPoints,
+			Deck:              []string{"1", "2"},
+		}
+		team.ApplyDefaults(tm, &r)
+		asserter.Equal("team1", r.TeamID)
+		asserter.Equal([]string{"1", "2"}, r.Deck)
+		asserter.False(*r.FacilitatorPoints)
+	})
+}
+
+func TestRemovedMembers(t *testing.T) {
+	previous := team.Team{Members: []team.Member{
+		{Email: "bob@example.com"},
+		{Email: "sue@example.com"},
+		{Email: "joe@example.com"},
+	}}
+	updated := team.Team{Members: []team.Member{
+		{Email: "sue@example.com"},
+		{Email: "ann@example.com"},
+	}}
+	assert.Equal(t, []string{"bob@example.com", "joe@example.com"}, team.RemovedMembers(previous, updated))
+}
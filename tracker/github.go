@@ -0,0 +1,148 @@
+package tracker
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	gitHubPageSize = 100
+
+	DefaultGitHubAPIURL        = "https://api.github.com"
+	DefaultEstimateLabelPrefix = "estimate: "
+)
+
+type GitHubConfig struct {
+	APIURL string
+	Owner  string
+	Repo   string
+	Token  string
+	// GitHub has no notion of story points, so estimates are tracked as labels with this prefix
+	EstimateLabelPrefix string
+}
+
+type GitHub struct {
+	config GitHubConfig
+	client *http.Client
+}
+
+func NewGitHub(config GitHubConfig, client *http.Client) *GitHub {
+	if config.APIURL == "" {
+		config.APIURL = DefaultGitHubAPIURL
+	}
+	config.APIURL = strings.TrimSuffix(config.APIURL, "/")
+	if config.EstimateLabelPrefix == "" {
+		config.EstimateLabelPrefix = DefaultEstimateLabelPrefix
+	}
+	return &GitHub{
+		config: config,
+		client: client,
+	}
+}
+
+type gitHubLabel struct {
+	Name string `json:"name"`
+}
+
+type gitHubIssue struct {
+	Number      int           `json:"number"`
+	Title       string        `json:"title"`
+	HTMLURL     string        `json:"html_url"`
+	Labels      []gitHubLabel `json:"labels"`
+	PullRequest interface{}   `json:"pull_request"`
+}
+
+// ImportStories pulls open issues with the given labels, which should be comma separated if there is more than one
+func (g *GitHub) ImportStories(ctx context.Context, query string) ([]Story, error) {
+	ret := make([]Story, 0)
+	for page := 1; ; page++ {
+		params := url.Values{}
+		params.Set("labels", query)
+		params.Set("state", "open")
+		params.Set("per_page", strconv.Itoa(gitHubPageSize))
+		params.Set("page", strconv.Itoa(page))
+
+		req, err := g.newRequest(ctx, http.MethodGet, fmt.Sprintf("%s/issues?%s", g.repoPath(), params.Encode()), nil)
+		if err != nil {
+			return nil, err
+		}
+		issues := make([]gitHubIssue, 0)
+		err = doJSON(g.client, req, &issues)
+		if err != nil {
+			return nil, errors.Wrap(err, "error listing github issues")
+		}
+
+		for _, issue := range issues {
+			// the issues api happily returns pull requests too
+			if issue.PullRequest != nil {
+				continue
+			}
+			s := Story{
+				Key:   strconv.Itoa(issue.Number),
+				Title: issue.Title,
+				URL:   issue.HTMLURL,
+			}
+			for _, l := range issue.Labels {
+				if strings.HasPrefix(l.Name, g.config.EstimateLabelPrefix) {
+					s.Estimate = strings.TrimPrefix(l.Name, g.config.EstimateLabelPrefix)
+				}
+			}
+			ret = append(ret, s)
+		}
+
+		if len(issues) < gitHubPageSize {
+			return ret, nil
+		}
+	}
+}
+
+func (g *GitHub) WriteEstimate(ctx context.Context, storyKey string, estimate string) error {
+	labelsPath := fmt.Sprintf("%s/issues/%s/labels", g.repoPath(), url.PathEscape(storyKey))
+
+	req, err := g.newRequest(ctx, http.MethodGet, labelsPath, nil)
+	if err != nil {
+		return err
+	}
+	existing := make([]gitHubLabel, 0)
+	err = doJSON(g.client, req, &existing)
+	if err != nil {
+		return errors.Wrap(err, "error reading github issue labels")
+	}
+
+	// replace any previous estimate rather than piling up labels on re-estimates
+	labels := make([]string, 0, len(existing)+1)
+	for _, l := range existing {
+		if !strings.HasPrefix(l.Name, g.config.EstimateLabelPrefix) {
+			labels = append(labels, l.Name)
+		}
+	}
+	labels = append(labels, g.config.EstimateLabelPrefix+estimate)
+
+	req, err = g.newRequest(ctx, http.MethodPut, labelsPath, map[string][]string{
+		"labels": labels,
+	})
+	if err != nil {
+		return err
+	}
+	return errors.Wrap(doJSON(g.client, req, nil), "error updating github issue labels")
+}
+
+func (g *GitHub) repoPath() string {
+	return fmt.Sprintf("/repos/%s/%s", url.PathEscape(g.config.Owner), url.PathEscape(g.config.Repo))
+}
+
+func (g *GitHub) newRequest(ctx context.Context, method string, path string, body interface{}) (*http.Request, error) {
+	req, err := newJSONRequest(ctx, method, g.config.APIURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", g.config.Token))
+	return req, nil
+}
@@ -0,0 +1,82 @@
+package tracker_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jonsabados/pointypoints/session/testutil"
+	"github.com/jonsabados/pointypoints/tracker"
+)
+
+func gitHubConfig(apiURL string) tracker.GitHubConfig {
+	return tracker.GitHubConfig{
+		APIURL: apiURL,
+		Owner:  "jonsabados",
+		Repo:   "pointypoints",
+		Token:  "sekret",
+	}
+}
+
+func TestGitHub_ImportStories(t *testing.T) {
+	asserter := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		asserter.Equal("Bearer sekret", r.Header.Get("Authorization"))
+		asserter.Equal("/repos/jonsabados/pointypoints/issues", r.URL.Path)
+		asserter.Equal("needs-estimate,backend", r.URL.Query().Get("labels"))
+		asserter.Equal("open", r.URL.Query().Get("state"))
+
+		switch r.URL.Query().Get("page") {
+		case "1":
+			// a full page means there may be more
+			issues := make([]string, 100)
+			issues[0] = `{"number":1,"title":"First","html_url":"https://github.com/jonsabados/pointypoints/issues/1","labels":[{"name":"backend"},{"name":"estimate: 5"}]}`
+			issues[1] = `{"number":2,"title":"A PR","html_url":"https://github.com/jonsabados/pointypoints/pull/2","labels":[],"pull_request":{}}`
+			for i := 2; i < len(issues); i++ {
+				issues[i] = `{"number":2,"title":"Filler","pull_request":{}}`
+			}
+			fmt.Fprintf(w, "[%s]", strings.Join(issues, ","))
+		case "2":
+			fmt.Fprint(w, `[{"number":3,"title":"Third","html_url":"https://github.com/jonsabados/pointypoints/issues/3","labels":[]}]`)
+		default:
+			t.Errorf("unexpected page %s", r.URL.Query().Get("page"))
+		}
+	}))
+	defer server.Close()
+
+	res, err := tracker.NewGitHub(gitHubConfig(server.URL), server.Client()).ImportStories(testutil.NewTestContext(), "needs-estimate,backend")
+	asserter.NoError(err)
+	asserter.Equal([]tracker.Story{
+		{Key: "1", Title: "First", URL: "https://github.com/jonsabados/pointypoints/issues/1", Estimate: "5"},
+		{Key: "3", Title: "Third", URL: "https://github.com/jonsabados/pointypoints/issues/3"},
+	}, res)
+}
+
+func TestGitHub_WriteEstimate(t *testing.T) {
+	asserter := assert.New(t)
+
+	var received map[string][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		asserter.Equal("/repos/jonsabados/pointypoints/issues/42/labels", r.URL.Path)
+		switch r.Method {
+		case http.MethodGet:
+			fmt.Fprint(w, `[{"name":"backend"},{"name":"estimate: 3"}]`)
+		case http.MethodPut:
+			asserter.NoError(json.NewDecoder(r.Body).Decode(&received))
+			fmt.Fprint(w, `[]`)
+		default:
+			t.Errorf("unexpected method %s", r.Method)
+		}
+	}))
+	defer server.Close()
+
+	err := tracker.NewGitHub(gitHubConfig(server.URL), server.Client()).WriteEstimate(testutil.NewTestContext(), "42", "8")
+	asserter.NoError(err)
+	asserter.Equal(map[string][]string{"labels": {"backend", "estimate: 8"}}, received)
+}
@@ -0,0 +1,113 @@
+package tracker
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const jiraPageSize = 50
+
+type JiraConfig struct {
+	BaseURL  string
+	User     string
+	APIToken string
+	// StoryPointField is the id of the custom field holding story points, which varies from instance to instance
+	StoryPointField string
+}
+
+type Jira struct {
+	config JiraConfig
+	client *http.Client
+}
+
+func NewJira(config JiraConfig, client *http.Client) *Jira {
+	config.BaseURL = strings.TrimSuffix(config.BaseURL, "/")
+	return &Jira{
+		config: config,
+		client: client,
+	}
+}
+
+type jiraSearchResponse struct {
+	StartAt    int         `json:"startAt"`
+	MaxResults int         `json:"maxResults"`
+	Total      int         `json:"total"`
+	Issues     []jiraIssue `json:"issues"`
+}
+
+type jiraIssue struct {
+	Key    string                 `json:"key"`
+	Fields map[string]interface{} `json:"fields"`
+}
+
+func (j *Jira) ImportStories(ctx context.Context, query string) ([]Story, error) {
+	ret := make([]Story, 0)
+	for startAt := 0; ; {
+		params := url.Values{}
+		params.Set("jql", query)
+		params.Set("fields", fmt.Sprintf("summary,%s", j.config.StoryPointField))
+		params.Set("startAt", strconv.Itoa(startAt))
+		params.Set("maxResults", strconv.Itoa(jiraPageSize))
+
+		req, err := j.newRequest(ctx, http.MethodGet, fmt.Sprintf("/rest/api/2/search?%s", params.Encode()), nil)
+		if err != nil {
+			return nil, err
+		}
+		res := new(jiraSearchResponse)
+		err = doJSON(j.client, req, res)
+		if err != nil {
+			return nil, errors.Wrap(err, "error searching jira")
+		}
+
+		for _, issue := range res.Issues {
+			s := Story{
+				Key: issue.Key,
+				URL: fmt.Sprintf("%s/browse/%s", j.config.BaseURL, issue.Key),
+			}
+			if summary, ok := issue.Fields["summary"].(string); ok {
+				s.Title = summary
+			}
+			if points, ok := issue.Fields[j.config.StoryPointField].(float64); ok {
+				s.Estimate = strconv.FormatFloat(points, 'f', -1, 64)
+			}
+			ret = append(ret, s)
+		}
+
+		startAt = res.StartAt + len(res.Issues)
+		if len(res.Issues) == 0 || startAt >= res.Total {
+			return ret, nil
+		}
+	}
+}
+
+func (j *Jira) WriteEstimate(ctx context.Context, storyKey string, estimate string) error {
+	// the story point field is numeric, so things like t-shirt sizes or ? can't go back
+	points, err := strconv.ParseFloat(estimate, 64)
+	if err != nil {
+		return errors.Errorf("estimate %s is not numeric", estimate)
+	}
+	req, err := j.newRequest(ctx, http.MethodPut, fmt.Sprintf("/rest/api/2/issue/%s", url.PathEscape(storyKey)), map[string]interface{}{
+		"fields": map[string]interface{}{
+			j.config.StoryPointField: points,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return errors.Wrap(doJSON(j.client, req, nil), "error updating jira issue")
+}
+
+func (j *Jira) newRequest(ctx context.Context, method string, path string, body interface{}) (*http.Request, error) {
+	req, err := newJSONRequest(ctx, method, j.config.BaseURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(j.config.User, j.config.APIToken)
+	return req, nil
+}
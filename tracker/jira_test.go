@@ -0,0 +1,92 @@
+package tracker_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jonsabados/pointypoints/session/testutil"
+	"github.com/jonsabados/pointypoints/tracker"
+)
+
+const storyPointField = "customfield_10016"
+
+func jiraConfig(baseURL string) tracker.JiraConfig {
+	return tracker.JiraConfig{
+		BaseURL:         baseURL,
+		User:            "bob@example.com",
+		APIToken:        "sekret",
+		StoryPointField: storyPointField,
+	}
+}
+
+func TestJira_ImportStories(t *testing.T) {
+	asserter := assert.New(t)
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		user, pass, ok := r.BasicAuth()
+		asserter.True(ok)
+		asserter.Equal("bob@example.com", user)
+		asserter.Equal("sekret", pass)
+		asserter.Equal("/rest/api/2/search", r.URL.Path)
+		asserter.Equal("project = PP AND sprint in openSprints()", r.URL.Query().Get("jql"))
+		asserter.Equal(fmt.Sprintf("summary,%s", storyPointField), r.URL.Query().Get("fields"))
+
+		// two pages, to make sure we keep asking until we have everything
+		switch r.URL.Query().Get("startAt") {
+		case "0":
+			fmt.Fprintf(w, `{"startAt":0,"maxResults":1,"total":2,"issues":[{"key":"PP-1","fields":{"summary":"First","%s":3}}]}`, storyPointField)
+		case "1":
+			fmt.Fprint(w, `{"startAt":1,"maxResults":1,"total":2,"issues":[{"key":"PP-2","fields":{"summary":"Second"}}]}`)
+		default:
+			t.Errorf("unexpected startAt %s", r.URL.Query().Get("startAt"))
+		}
+	}))
+	defer server.Close()
+
+	res, err := tracker.NewJira(jiraConfig(server.URL+"/"), server.Client()).ImportStories(testutil.NewTestContext(), "project = PP AND sprint in openSprints()")
+	asserter.NoError(err)
+	asserter.Equal(2, requests)
+	asserter.Equal([]tracker.Story{
+		{Key: "PP-1", Title: "First", URL: server.URL + "/browse/PP-1", Estimate: "3"},
+		{Key: "PP-2", Title: "Second", URL: server.URL + "/browse/PP-2"},
+	}, res)
+}
+
+func TestJira_ImportStories_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"errorMessages":["bad jql"]}`)
+	}))
+	defer server.Close()
+
+	_, err := tracker.NewJira(jiraConfig(server.URL), server.Client()).ImportStories(testutil.NewTestContext(), "nope")
+	assert.EqualError(t, err, `error searching jira: unexpected status 400 from GET /rest/api/2/search: {"errorMessages":["bad jql"]}`)
+}
+
+func TestJira_WriteEstimate(t *testing.T) {
+	asserter := assert.New(t)
+
+	var received map[string]map[string]float64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		asserter.Equal(http.MethodPut, r.Method)
+		asserter.Equal("/rest/api/2/issue/PP-1", r.URL.Path)
+		asserter.Equal("application/json", r.Header.Get("Content-Type"))
+		asserter.NoError(json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	j := tracker.NewJira(jiraConfig(server.URL), server.Client())
+
+	asserter.NoError(j.WriteEstimate(testutil.NewTestContext(), "PP-1", "0.5"))
+	asserter.Equal(map[string]map[string]float64{"fields": {storyPointField: 0.5}}, received)
+
+	asserter.EqualError(j.WriteEstimate(testutil.NewTestContext(), "PP-1", "XL"), "estimate XL is not numeric")
+}
@@ -0,0 +1,113 @@
+package tracker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+
+	"github.com/jonsabados/pointypoints/session"
+)
+
+const (
+	SourceJira   = "jira"
+	SourceGitHub = "github"
+)
+
+type Story struct {
+	Key      string
+	Title    string
+	URL      string
+	Estimate string
+}
+
+// Tracker is an adapter to an issue tracker that stories can be pulled from and estimates pushed back to
+type Tracker interface {
+	// ImportStories returns the stories matching a tracker specific query, JQL for Jira or labels for GitHub
+	ImportStories(ctx context.Context, query string) ([]Story, error)
+	WriteEstimate(ctx context.Context, storyKey string, estimate string) error
+}
+
+// ToSessionStories converts imported stories for use in a session, tagging them with where they came from so estimates
+// can find their way back
+func ToSessionStories(source string, stories []Story) []session.Story {
+	ret := make([]session.Story, len(stories))
+	for i, s := range stories {
+		ret[i] = session.Story{
+			Key:      s.Key,
+			Title:    s.Title,
+			URL:      s.URL,
+			Source:   source,
+			Estimate: s.Estimate,
+		}
+	}
+	return ret
+}
+
+// EstimateWriter pushes the agreed upon estimate for the current story back to its tracker once votes are revealed
+type EstimateWriter func(ctx context.Context, sess session.CompleteSessionView) error
+
+func NewEstimateWriter(trackers map[string]Tracker) EstimateWriter {
+	return func(ctx context.Context, sess session.CompleteSessionView) error {
+		story := session.CurrentStory(sess)
+		// stories typed in by hand, like the ones started from chat, have nowhere to write back to
+		if !sess.VotesShown || story == nil || story.Source == "" {
+			return nil
+		}
+		// poll results aren't story points, so they stay out of the tracker
+		if sess.Type.Poll() {
+			return nil
+		}
+		estimate, agreed := session.AgreedEstimate(sess)
+		if !agreed {
+			zerolog.Ctx(ctx).Info().Str("sessionID", sess.SessionID).Str("story", story.Key).Msg("no agreed estimate to write back")
+			return nil
+		}
+		t, ok := trackers[story.Source]
+		if !ok {
+			return errors.Errorf("no tracker configured for %s", story.Source)
+		}
+		return errors.Wrapf(t.WriteEstimate(ctx, story.Key, estimate), "error writing estimate for %s", story.Key)
+	}
+}
+
+func doJSON(client *http.Client, req *http.Request, out interface{}) error {
+	res, err := client.Do(req)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return errors.Errorf("unexpected status %d from %s %s: %s", res.StatusCode, req.Method, req.URL.Path, string(body))
+	}
+	if out == nil {
+		return nil
+	}
+	return errors.WithStack(json.NewDecoder(res.Body).Decode(out))
+}
+
+func newJSONRequest(ctx context.Context, method string, url string, body interface{}) (*http.Request, error) {
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		reader = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return req, nil
+}
@@ -0,0 +1,120 @@
+package tracker_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jonsabados/pointypoints/session"
+	"github.com/jonsabados/pointypoints/session/testutil"
+	"github.com/jonsabados/pointypoints/tracker"
+)
+
+type writtenEstimate struct {
+	storyKey string
+	estimate string
+}
+
+type fakeTracker struct {
+	written []writtenEstimate
+	err     error
+}
+
+func (f *fakeTracker) ImportStories(ctx context.Context, query string) ([]tracker.Story, error) {
+	return nil, nil
+}
+
+func (f *fakeTracker) WriteEstimate(ctx context.Context, storyKey string, estimate string) error {
+	f.written = append(f.written, writtenEstimate{storyKey, estimate})
+	return f.err
+}
+
+func TestNewEstimateWriter(t *testing.T) {
+	sessionWithVotes := func(votesShown bool, current string, votes ...string) session.CompleteSessionView {
+		ret := session.CompleteSessionView{
+			SessionID:  "abcdefg",
+			VotesShown: votesShown,
+			Stories: []session.Story{
+				{Key: "PP-1", Source: tracker.SourceJira},
+				{Key: "7", Source: tracker.SourceGitHub},
+				{Key: "typed-in"},
+			},
+			CurrentStory: current,
+		}
+		for _, v := range votes {
+			ret.Participants = append(ret.Participants, session.User{CurrentVote: aws.String(v)})
+		}
+		return ret
+	}
+
+	testCases := []struct {
+		name          string
+		sess          session.CompleteSessionView
+		trackerErr    error
+		expected      []writtenEstimate
+		expectedError string
+	}{
+		{
+			name: "votes hidden",
+			sess: sessionWithVotes(false, "PP-1", "3", "3"),
+		},
+		{
+			name: "no current story",
+			sess: sessionWithVotes(true, "", "3", "3"),
+		},
+		{
+			name: "no agreement",
+			sess: sessionWithVotes(true, "PP-1", "3", "5"),
+		},
+		{
+			name: "story without a tracker",
+			sess: sessionWithVotes(true, "typed-in", "3", "3"),
+		},
+		{
+			name:     "agreed",
+			sess:     sessionWithVotes(true, "PP-1", "3", "3"),
+			expected: []writtenEstimate{{"PP-1", "3"}},
+		},
+		{
+			name: "poll",
+			sess: func() session.CompleteSessionView {
+				ret := sessionWithVotes(true, "PP-1", "5", "5")
+				ret.Type = session.SessionTypeFistOfFive
+				return ret
+			}(),
+		},
+		{
+			name:          "tracker not configured",
+			sess:          sessionWithVotes(true, "7", "3", "3"),
+			expectedError: "no tracker configured for github",
+		},
+		{
+			name:          "tracker error",
+			sess:          sessionWithVotes(true, "PP-1", "3"),
+			trackerErr:    errors.New("kaboom"),
+			expected:      []writtenEstimate{{"PP-1", "3"}},
+			expectedError: "error writing estimate for PP-1: kaboom",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			asserter := assert.New(t)
+
+			jira := &fakeTracker{err: tc.trackerErr}
+			err := tracker.NewEstimateWriter(map[string]tracker.Tracker{
+				tracker.SourceJira: jira,
+			})(testutil.NewTestContext(), tc.sess)
+
+			if tc.expectedError != "" {
+				asserter.EqualError(err, tc.expectedError)
+			} else {
+				asserter.NoError(err)
+			}
+			asserter.Equal(tc.expected, jira.written)
+		})
+	}
+}
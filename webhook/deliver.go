@@ -0,0 +1,122 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	SignatureHeader = "X-Pointypoints-Signature"
+	TimestampHeader = "X-Pointypoints-Timestamp"
+	EventHeader     = "X-Pointypoints-Event"
+	DeliveryHeader  = "X-Pointypoints-Delivery"
+)
+
+type Backoff struct {
+	Attempts int
+	Initial  time.Duration
+	Max      time.Duration
+}
+
+var DefaultBackoff = Backoff{
+	Attempts: 5,
+	Initial:  500 * time.Millisecond,
+	Max:      8 * time.Second,
+}
+
+func (b Backoff) delay(attempt int) time.Duration {
+	ret := b.Initial << (attempt - 1)
+	if ret > b.Max || ret <= 0 {
+		return b.Max
+	}
+	return ret
+}
+
+// Sign computes the signature sent along with each delivery. It covers the timestamp as well as the body so receivers
+// can reject replays of old deliveries.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return fmt.Sprintf("sha256=%s", hex.EncodeToString(mac.Sum(nil)))
+}
+
+// Deliverer posts an event to a webhook, retrying as needed, and reports on how it went
+type Deliverer func(ctx context.Context, r Registration, event Event) Delivery
+
+func NewDeliverer(client *http.Client, backoff Backoff) Deliverer {
+	return func(ctx context.Context, r Registration, event Event) Delivery {
+		ret := Delivery{
+			WebhookID: r.ID,
+			EventID:   event.ID,
+			EventType: event.Type,
+		}
+
+		body, err := json.Marshal(event)
+		if err != nil {
+			ret.Error = err.Error()
+			ret.CompletedAt = time.Now().UTC()
+			return ret
+		}
+
+		for ret.Attempts < backoff.Attempts {
+			if ret.Attempts > 0 {
+				select {
+				case <-ctx.Done():
+					ret.Error = ctx.Err().Error()
+					ret.CompletedAt = time.Now().UTC()
+					return ret
+				case <-time.After(backoff.delay(ret.Attempts)):
+				}
+			}
+			ret.Attempts++
+
+			retry := attemptDelivery(ctx, client, r, event, body, &ret)
+			if ret.Delivered || !retry {
+				break
+			}
+		}
+		ret.CompletedAt = time.Now().UTC()
+		return ret
+	}
+}
+
+func attemptDelivery(ctx context.Context, client *http.Client, r Registration, event Event, body []byte, d *Delivery) bool {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.URL, bytes.NewReader(body))
+	if err != nil {
+		d.Error = err.Error()
+		return false
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(SignatureHeader, Sign(r.Secret, timestamp, body))
+	req.Header.Set(EventHeader, string(event.Type))
+	req.Header.Set(DeliveryHeader, event.ID)
+
+	res, err := client.Do(req)
+	if err != nil {
+		d.Error = err.Error()
+		return true
+	}
+	res.Body.Close()
+
+	d.StatusCode = res.StatusCode
+	if res.StatusCode >= 200 && res.StatusCode <= 299 {
+		d.Delivered = true
+		d.Error = ""
+		return false
+	}
+	d.Error = fmt.Sprintf("unexpected status %d", res.StatusCode)
+	// other client errors aren't going to get better by trying again
+	return res.StatusCode >= 500 || res.StatusCode == http.StatusTooManyRequests
+}
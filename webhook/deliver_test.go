@@ -0,0 +1,110 @@
+package webhook_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jonsabados/pointypoints/session/testutil"
+	"github.com/jonsabados/pointypoints/webhook"
+)
+
+var testBackoff = webhook.Backoff{
+	Attempts: 3,
+	Initial:  time.Millisecond,
+	Max:      5 * time.Millisecond,
+}
+
+func TestNewDeliverer(t *testing.T) {
+	testCases := []struct {
+		name               string
+		statuses           []int
+		expectedAttempts   int
+		expectedDelivered  bool
+		expectedStatusCode int
+		expectedError      string
+	}{
+		{
+			name:               "first time",
+			statuses:           []int{http.StatusOK},
+			expectedAttempts:   1,
+			expectedDelivered:  true,
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "after server errors",
+			statuses:           []int{http.StatusBadGateway, http.StatusTooManyRequests, http.StatusNoContent},
+			expectedAttempts:   3,
+			expectedDelivered:  true,
+			expectedStatusCode: http.StatusNoContent,
+		},
+		{
+			name:               "gives up eventually",
+			statuses:           []int{http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError},
+			expectedAttempts:   3,
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedError:      "unexpected status 500",
+		},
+		{
+			name:               "client errors not retried",
+			statuses:           []int{http.StatusNotFound},
+			expectedAttempts:   1,
+			expectedStatusCode: http.StatusNotFound,
+			expectedError:      "unexpected status 404",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			asserter := assert.New(t)
+
+			registration := webhook.Registration{
+				ID:     "hook",
+				Secret: "sekret",
+			}
+			event := webhook.Event{
+				ID:        "event",
+				Type:      webhook.VotesRevealed,
+				SessionID: "abcdefg",
+			}
+
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				asserter.NoError(err)
+				timestamp, err := strconv.ParseInt(r.Header.Get(webhook.TimestampHeader), 10, 64)
+				asserter.NoError(err)
+				asserter.Equal(webhook.Sign("sekret", timestamp, body), r.Header.Get(webhook.SignatureHeader))
+				asserter.Equal("votes.revealed", r.Header.Get(webhook.EventHeader))
+				asserter.Equal("event", r.Header.Get(webhook.DeliveryHeader))
+				asserter.JSONEq(`{"id":"event","type":"votes.revealed","sessionId":"abcdefg","occurredAt":"0001-01-01T00:00:00Z"}`, string(body))
+
+				w.WriteHeader(tc.statuses[requests])
+				requests++
+			}))
+			defer server.Close()
+			registration.URL = server.URL
+
+			res := webhook.NewDeliverer(server.Client(), testBackoff)(testutil.NewTestContext(), registration, event)
+			asserter.Equal(tc.expectedAttempts, requests)
+			asserter.Equal("hook", res.WebhookID)
+			asserter.Equal("event", res.EventID)
+			asserter.Equal(webhook.VotesRevealed, res.EventType)
+			asserter.Equal(tc.expectedAttempts, res.Attempts)
+			asserter.Equal(tc.expectedDelivered, res.Delivered)
+			asserter.Equal(tc.expectedStatusCode, res.StatusCode)
+			asserter.Equal(tc.expectedError, res.Error)
+			asserter.False(res.CompletedAt.IsZero())
+		})
+	}
+}
+
+func TestSign(t *testing.T) {
+	// computed independently with: printf '1000.{}' | openssl dgst -sha256 -hmac sekret
+	assert.Equal(t, "sha256=ff0853ee2bfb9e1cd3d36d952f4de9bded6850050b65c77be80a0c91ef48ddd7", webhook.Sign("sekret", 1000, []byte("{}")))
+}
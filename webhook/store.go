@@ -0,0 +1,276 @@
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+const (
+	registrationRangeKeyPrefix = "hook:"
+	deliveryRangeKeyPrefix     = "delivery:"
+
+	// DeliveryLogRetention is how long a record of each delivery sticks around
+	DeliveryLogRetention = time.Hour * 24 * 7
+)
+
+type DynamoClient interface {
+	QueryWithContext(ctx aws.Context, input *dynamodb.QueryInput, opts ...request.Option) (*dynamodb.QueryOutput, error)
+	PutItemWithContext(ctx aws.Context, input *dynamodb.PutItemInput, opts ...request.Option) (*dynamodb.PutItemOutput, error)
+	DeleteItemWithContext(ctx aws.Context, input *dynamodb.DeleteItemInput, opts ...request.Option) (*dynamodb.DeleteItemOutput, error)
+}
+
+type RegisterRequest struct {
+	URL    string      `json:"url"`
+	Events []EventType `json:"events"`
+}
+
+type Registration struct {
+	ID      string `json:"id"`
+	OwnerID string `json:"-"`
+	URL     string `json:"url"`
+	// Secret is only handed out when the webhook is registered
+	Secret string `json:"secret,omitempty"`
+	// Events the webhook is interested in, empty for everything
+	Events    []EventType `json:"events,omitempty"`
+	CreatedAt time.Time   `json:"createdAt"`
+}
+
+func (r Registration) Subscribed(t EventType) bool {
+	if len(r.Events) == 0 {
+		return true
+	}
+	for _, e := range r.Events {
+		if e == t {
+			return true
+		}
+	}
+	return false
+}
+
+type Delivery struct {
+	WebhookID   string    `json:"webhookId"`
+	EventID     string    `json:"eventId"`
+	EventType   EventType `json:"eventType"`
+	Delivered   bool      `json:"delivered"`
+	Attempts    int       `json:"attempts"`
+	StatusCode  int       `json:"statusCode,omitempty"`
+	Error       string    `json:"error,omitempty"`
+	CompletedAt time.Time `json:"completedAt"`
+}
+
+// NewRegistration sets up a registration with a fresh id and signing secret
+func NewRegistration(ownerID string, r RegisterRequest, now time.Time) (Registration, error) {
+	secret := make([]byte, 32)
+	_, err := rand.Read(secret)
+	if err != nil {
+		return Registration{}, errors.WithStack(err)
+	}
+	return Registration{
+		ID:        uuid.New().String(),
+		OwnerID:   ownerID,
+		URL:       r.URL,
+		Secret:    hex.EncodeToString(secret),
+		Events:    r.Events,
+		CreatedAt: now.UTC().Truncate(time.Second),
+	}, nil
+}
+
+func SessionOwner(sessionID string) string {
+	return fmt.Sprintf("session:%s", sessionID)
+}
+
+func TeamOwner(teamID string) string {
+	return fmt.Sprintf("team:%s", teamID)
+}
+
+// Registrar saves a webhook registration, which will be cleaned up at expiration unless it is zero
+type Registrar func(ctx context.Context, r Registration, expiration time.Time) error
+
+func NewRegistrar(dynamo DynamoClient, tableName string) Registrar {
+	return func(ctx context.Context, r Registration, expiration time.Time) error {
+		item := map[string]*dynamodb.AttributeValue{
+			"OwnerID":   {S: aws.String(r.OwnerID)},
+			"RangeKey":  {S: aws.String(registrationRangeKeyPrefix + r.ID)},
+			"URL":       {S: aws.String(r.URL)},
+			"Secret":    {S: aws.String(r.Secret)},
+			"CreatedAt": {N: aws.String(strconv.FormatInt(r.CreatedAt.Unix(), 10))},
+		}
+		if len(r.Events) > 0 {
+			events := make([]*string, len(r.Events))
+			for i, e := range r.Events {
+				events[i] = aws.String(string(e))
+			}
+			item["Events"] = &dynamodb.AttributeValue{SS: events}
+		}
+		if !expiration.IsZero() {
+			item["Expiration"] = &dynamodb.AttributeValue{N: aws.String(strconv.FormatInt(expiration.Unix(), 10))}
+		}
+		_, err := dynamo.PutItemWithContext(ctx, &dynamodb.PutItemInput{
+			TableName: aws.String(tableName),
+			Item:      item,
+		})
+		return errors.Wrap(err, "error saving webhook registration")
+	}
+}
+
+type Lister func(ctx context.Context, ownerID string) ([]Registration, error)
+
+func NewLister(dynamo DynamoClient, tableName string) Lister {
+	return func(ctx context.Context, ownerID string) ([]Registration, error) {
+		items, err := queryAll(ctx, dynamo, &dynamodb.QueryInput{
+			TableName:      aws.String(tableName),
+			ConsistentRead: aws.Bool(true),
+			KeyConditions:  ownerRecordsCondition(ownerID, registrationRangeKeyPrefix),
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "error reading webhook registrations")
+		}
+		ret := make([]Registration, len(items))
+		for i, item := range items {
+			createdAt, _ := strconv.ParseInt(*item["CreatedAt"].N, 10, 64)
+			ret[i] = Registration{
+				ID:        strings.TrimPrefix(*item["RangeKey"].S, registrationRangeKeyPrefix),
+				OwnerID:   ownerID,
+				URL:       *item["URL"].S,
+				Secret:    *item["Secret"].S,
+				CreatedAt: time.Unix(createdAt, 0).UTC(),
+			}
+			if events, ok := item["Events"]; ok {
+				for _, e := range events.SS {
+					ret[i].Events = append(ret[i].Events, EventType(*e))
+				}
+			}
+		}
+		return ret, nil
+	}
+}
+
+type Remover func(ctx context.Context, ownerID string, webhookID string) error
+
+func NewRemover(dynamo DynamoClient, tableName string) Remover {
+	return func(ctx context.Context, ownerID string, webhookID string) error {
+		_, err := dynamo.DeleteItemWithContext(ctx, &dynamodb.DeleteItemInput{
+			TableName: aws.String(tableName),
+			Key: map[string]*dynamodb.AttributeValue{
+				"OwnerID":  {S: aws.String(ownerID)},
+				"RangeKey": {S: aws.String(registrationRangeKeyPrefix + webhookID)},
+			},
+		})
+		return errors.Wrap(err, "error removing webhook registration")
+	}
+}
+
+type DeliveryLogger func(ctx context.Context, ownerID string, d Delivery) error
+
+func NewDeliveryLogger(dynamo DynamoClient, tableName string) DeliveryLogger {
+	return func(ctx context.Context, ownerID string, d Delivery) error {
+		item := map[string]*dynamodb.AttributeValue{
+			"OwnerID": {S: aws.String(ownerID)},
+			// zero padded nanos first so the log reads back in chronological order across all of an owners webhooks
+			"RangeKey":    {S: aws.String(fmt.Sprintf("%s%020d:%s", deliveryRangeKeyPrefix, d.CompletedAt.UnixNano(), d.WebhookID))},
+			"WebhookID":   {S: aws.String(d.WebhookID)},
+			"EventID":     {S: aws.String(d.EventID)},
+			"EventType":   {S: aws.String(string(d.EventType))},
+			"Delivered":   {BOOL: aws.Bool(d.Delivered)},
+			"Attempts":    {N: aws.String(strconv.Itoa(d.Attempts))},
+			"StatusCode":  {N: aws.String(strconv.Itoa(d.StatusCode))},
+			"CompletedAt": {N: aws.String(strconv.FormatInt(d.CompletedAt.Unix(), 10))},
+			"Expiration":  {N: aws.String(strconv.FormatInt(d.CompletedAt.Add(DeliveryLogRetention).Unix(), 10))},
+		}
+		if d.Error != "" {
+			item["Error"] = &dynamodb.AttributeValue{S: aws.String(d.Error)}
+		}
+		_, err := dynamo.PutItemWithContext(ctx, &dynamodb.PutItemInput{
+			TableName: aws.String(tableName),
+			Item:      item,
+		})
+		return errors.Wrap(err, "error logging webhook delivery")
+	}
+}
+
+// DeliveryLister returns the most recent deliveries for an owner, newest first
+type DeliveryLister func(ctx context.Context, ownerID string, limit int) ([]Delivery, error)
+
+func NewDeliveryLister(dynamo DynamoClient, tableName string) DeliveryLister {
+	return func(ctx context.Context, ownerID string, limit int) ([]Delivery, error) {
+		items, err := queryAll(ctx, dynamo, &dynamodb.QueryInput{
+			TableName:        aws.String(tableName),
+			KeyConditions:    ownerRecordsCondition(ownerID, deliveryRangeKeyPrefix),
+			ScanIndexForward: aws.Bool(false),
+			Limit:            aws.Int64(int64(limit)),
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "error reading webhook deliveries")
+		}
+		ret := make([]Delivery, len(items))
+		for i, item := range items {
+			attempts, _ := strconv.Atoi(*item["Attempts"].N)
+			statusCode, _ := strconv.Atoi(*item["StatusCode"].N)
+			completedAt, _ := strconv.ParseInt(*item["CompletedAt"].N, 10, 64)
+			ret[i] = Delivery{
+				WebhookID:   *item["WebhookID"].S,
+				EventID:     *item["EventID"].S,
+				EventType:   EventType(*item["EventType"].S),
+				Delivered:   *item["Delivered"].BOOL,
+				Attempts:    attempts,
+				StatusCode:  statusCode,
+				CompletedAt: time.Unix(completedAt, 0).UTC(),
+			}
+			if e, ok := item["Error"]; ok {
+				ret[i].Error = *e.S
+			}
+		}
+		return ret, nil
+	}
+}
+
+// queryAll follows LastEvaluatedKey until every page has been read, or until Limit items have been for queries that
+// set one, since a single query stops at 1MB regardless
+func queryAll(ctx context.Context, dynamo DynamoClient, input *dynamodb.QueryInput) ([]map[string]*dynamodb.AttributeValue, error) {
+	var ret []map[string]*dynamodb.AttributeValue
+	page := input
+	for {
+		res, err := dynamo.QueryWithContext(ctx, page)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		ret = append(ret, res.Items...)
+		if len(res.LastEvaluatedKey) == 0 || (input.Limit != nil && int64(len(ret)) >= *input.Limit) {
+			return ret, nil
+		}
+		next := *input
+		next.ExclusiveStartKey = res.LastEvaluatedKey
+		if input.Limit != nil {
+			next.Limit = aws.Int64(*input.Limit - int64(len(ret)))
+		}
+		page = &next
+	}
+}
+
+func ownerRecordsCondition(ownerID string, rangeKeyPrefix string) map[string]*dynamodb.Condition {
+	return map[string]*dynamodb.Condition{
+		"OwnerID": {
+			ComparisonOperator: aws.String("EQ"),
+			AttributeValueList: []*dynamodb.AttributeValue{
+				{S: aws.String(ownerID)},
+			},
+		},
+		"RangeKey": {
+			ComparisonOperator: aws.String("BEGINS_WITH"),
+			AttributeValueList: []*dynamodb.AttributeValue{
+				{S: aws.String(rangeKeyPrefix)},
+			},
+		},
+	}
+}
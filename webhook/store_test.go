@@ -0,0 +1,121 @@
+package webhook_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/jonsabados/pointypoints/session/testutil"
+	"github.com/jonsabados/pointypoints/webhook"
+)
+
+var emptyOpts []request.Option
+
+func TestRegistration_RoundTrip(t *testing.T) {
+	asserter := assert.New(t)
+
+	ctx := testutil.NewTestContext()
+	dynamo := &testutil.MockDynamoClient{}
+	tableName := "webhooks"
+
+	registration, err := webhook.NewRegistration(webhook.SessionOwner("abcdefg"), webhook.RegisterRequest{
+		URL:    "https://example.com/hook",
+		Events: []webhook.EventType{webhook.VotesRevealed},
+	}, time.Now())
+	asserter.NoError(err)
+	asserter.Len(registration.Secret, 64)
+	asserter.NotEmpty(registration.ID)
+
+	var saved map[string]*dynamodb.AttributeValue
+	dynamo.On("PutItemWithContext", ctx, mock.Anything, emptyOpts).Run(func(args mock.Arguments) {
+		input := args.Get(1).(*dynamodb.PutItemInput)
+		asserter.Equal(tableName, *input.TableName)
+		saved = input.Item
+	}).Return(&dynamodb.PutItemOutput{}, nil)
+
+	expiration := time.Unix(5000, 0)
+	err = webhook.NewRegistrar(dynamo, tableName)(ctx, registration, expiration)
+	asserter.NoError(err)
+	asserter.Equal("session:abcdefg", *saved["OwnerID"].S)
+	asserter.Equal("5000", *saved["Expiration"].N)
+
+	dynamo.On("QueryWithContext", ctx, &dynamodb.QueryInput{
+		TableName:      aws.String(tableName),
+		ConsistentRead: aws.Bool(true),
+		KeyConditions: map[string]*dynamodb.Condition{
+			"OwnerID": {
+				ComparisonOperator: aws.String("EQ"),
+				AttributeValueList: []*dynamodb.AttributeValue{
+					{S: aws.String("session:abcdefg")},
+				},
+			},
+			"RangeKey": {
+				ComparisonOperator: aws.String("BEGINS_WITH"),
+				AttributeValueList: []*dynamodb.AttributeValue{
+					{S: aws.String("hook:")},
+				},
+			},
+		},
+	}, emptyOpts).Return(&dynamodb.QueryOutput{
+		Items: []map[string]*dynamodb.AttributeValue{saved},
+	}, nil)
+
+	res, err := webhook.NewLister(dynamo, tableName)(ctx, "session:abcdefg")
+	asserter.NoError(err)
+	asserter.Equal([]webhook.Registration{registration}, res)
+	dynamo.AssertExpectations(t)
+}
+
+func TestNewDeliveryLister_MultiplePages(t *testing.T) {
+	asserter := assert.New(t)
+
+	ctx := testutil.NewTestContext()
+	dynamo := &testutil.MockDynamoClient{}
+	tableName := "webhooks"
+
+	delivery := func(id string) map[string]*dynamodb.AttributeValue {
+		return map[string]*dynamodb.AttributeValue{
+			"OwnerID":     {S: aws.String("session:abcdefg")},
+			"RangeKey":    {S: aws.String("delivery:" + id)},
+			"WebhookID":   {S: aws.String("hook")},
+			"EventID":     {S: aws.String(id)},
+			"EventType":   {S: aws.String(string(webhook.VotesRevealed))},
+			"Delivered":   {BOOL: aws.Bool(true)},
+			"Attempts":    {N: aws.String("1")},
+			"StatusCode":  {N: aws.String("200")},
+			"CompletedAt": {N: aws.String("1000")},
+		}
+	}
+
+	var limits []int64
+	dynamo.On("QueryWithContext", ctx, mock.MatchedBy(func(in *dynamodb.QueryInput) bool {
+		return in.ExclusiveStartKey == nil
+	}), emptyOpts).Run(func(args mock.Arguments) {
+		limits = append(limits, *args.Get(1).(*dynamodb.QueryInput).Limit)
+	}).Return(&dynamodb.QueryOutput{
+		Items:            []map[string]*dynamodb.AttributeValue{delivery("e3"), delivery("e2")},
+		LastEvaluatedKey: delivery("e2"),
+	}, nil).Once()
+	dynamo.On("QueryWithContext", ctx, mock.MatchedBy(func(in *dynamodb.QueryInput) bool {
+		return in.ExclusiveStartKey != nil
+	}), emptyOpts).Run(func(args mock.Arguments) {
+		limits = append(limits, *args.Get(1).(*dynamodb.QueryInput).Limit)
+	}).Return(&dynamodb.QueryOutput{
+		Items:            []map[string]*dynamodb.AttributeValue{delivery("e1")},
+		LastEvaluatedKey: delivery("e1"),
+	}, nil).Once()
+
+	res, err := webhook.NewDeliveryLister(dynamo, tableName)(ctx, "session:abcdefg", 3)
+	asserter.NoError(err)
+	asserter.Equal([]int64{3, 1}, limits)
+	if asserter.Len(res, 3) {
+		asserter.Equal("e3", res[0].EventID)
+		asserter.Equal("e1", res[2].EventID)
+	}
+	dynamo.AssertExpectations(t)
+}
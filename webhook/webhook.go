@@ -0,0 +1,155 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	awslambda "github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+
+	"github.com/jonsabados/pointypoints/session"
+)
+
+type EventType string
+
+const (
+	SessionStarted    = EventType("session.started")
+	ParticipantJoined = EventType("participant.joined")
+	ParticipantLeft   = EventType("participant.left")
+	VotesRevealed     = EventType("votes.revealed")
+	VotesCleared      = EventType("votes.cleared")
+)
+
+var EventTypes = []EventType{
+	SessionStarted,
+	ParticipantJoined,
+	ParticipantLeft,
+	VotesRevealed,
+	VotesCleared,
+}
+
+func (t EventType) Valid() bool {
+	for _, e := range EventTypes {
+		if t == e {
+			return true
+		}
+	}
+	return false
+}
+
+type Event struct {
+	ID        string    `json:"id"`
+	Type      EventType `json:"type"`
+	SessionID string    `json:"sessionId"`
+	// TeamID is set for events in sessions run by a team, which go to the team's webhooks as well as the session's
+	TeamID     string    `json:"teamId,omitempty"`
+	OccurredAt time.Time `json:"occurredAt"`
+	// Session is what a participant would see, so hidden and anonymous votes stay that way
+	Session     *session.ParticipantSessionView `json:"session,omitempty"`
+	Participant *session.User                   `json:"participant,omitempty"`
+}
+
+func newEvent(t EventType, sessionID string) Event {
+	return Event{
+		ID:         uuid.New().String(),
+		Type:       t,
+		SessionID:  sessionID,
+		OccurredAt: time.Now().UTC(),
+	}
+}
+
+func sessionEvent(t EventType, s session.CompleteSessionView) Event {
+	ret := newEvent(t, s.SessionID)
+	ret.TeamID = s.TeamID
+	view := session.ToParticipantView(s, "")
+	ret.Session = &view
+	return ret
+}
+
+func NewSessionStartedEvent(s session.CompleteSessionView) Event {
+	return sessionEvent(SessionStarted, s)
+}
+
+func NewParticipantJoinedEvent(s session.CompleteSessionView, participant session.User) Event {
+	ret := sessionEvent(ParticipantJoined, s)
+	ret.Participant = &participant
+	return ret
+}
+
+func NewParticipantLeftEvent(s session.CompleteSessionView, participant session.User) Event {
+	ret := sessionEvent(ParticipantLeft, s)
+	ret.Participant = &participant
+	return ret
+}
+
+func NewVotesRevealedEvent(s session.CompleteSessionView) Event {
+	return sessionEvent(VotesRevealed, s)
+}
+
+func NewVotesClearedEvent(s session.CompleteSessionView) Event {
+	return sessionEvent(VotesCleared, s)
+}
+
+// Publisher sends an event along to everyone who has registered interest in it
+type Publisher func(ctx context.Context, event Event) error
+
+type LambdaInvoker interface {
+	InvokeWithContext(ctx aws.Context, input *awslambda.InvokeInput, opts ...request.Option) (*awslambda.InvokeOutput, error)
+}
+
+// NewPublisher hands events off to the delivery lambda so slow or flaky endpoints, and the retries that go with them,
+// don't hold up the request that triggered the event
+func NewPublisher(invoker LambdaInvoker, deliveryFunction string) Publisher {
+	return func(ctx context.Context, event Event) error {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		_, err = invoker.InvokeWithContext(ctx, &awslambda.InvokeInput{
+			FunctionName:   aws.String(deliveryFunction),
+			InvocationType: aws.String(awslambda.InvocationTypeEvent),
+			Payload:        payload,
+		})
+		return errors.Wrap(err, "error queueing webhook delivery")
+	}
+}
+
+// NewDispatcher does the actual delivery of an event to each interested webhook, recording how each delivery went
+func NewDispatcher(listRegistrations Lister, deliver Deliverer, logDelivery DeliveryLogger) Publisher {
+	return func(ctx context.Context, event Event) error {
+		owners := []string{SessionOwner(event.SessionID)}
+		if event.TeamID != "" {
+			owners = append(owners, TeamOwner(event.TeamID))
+		}
+
+		var dispatchErr error
+		for _, owner := range owners {
+			registrations, err := listRegistrations(ctx, owner)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+
+			for _, r := range registrations {
+				if !r.Subscribed(event.Type) {
+					continue
+				}
+				d := deliver(ctx, r, event)
+				if !d.Delivered {
+					zerolog.Ctx(ctx).Warn().Str("webhookID", r.ID).Str("eventID", event.ID).Str("error", d.Error).Msg("webhook delivery failed")
+				}
+				err := logDelivery(ctx, owner, d)
+				if err != nil {
+					// keep going so one bad write doesn't cost everyone else their delivery
+					zerolog.Ctx(ctx).Error().Err(err).Str("webhookID", r.ID).Msg("error logging webhook delivery")
+					dispatchErr = err
+				}
+			}
+		}
+		return dispatchErr
+	}
+}
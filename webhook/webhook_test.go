@@ -0,0 +1,149 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	awslambda "github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/jonsabados/pointypoints/session"
+	"github.com/jonsabados/pointypoints/session/testutil"
+	"github.com/jonsabados/pointypoints/webhook"
+)
+
+type mockInvoker struct {
+	mock.Mock
+}
+
+func (m *mockInvoker) InvokeWithContext(ctx aws.Context, input *awslambda.InvokeInput, opts ...request.Option) (*awslambda.InvokeOutput, error) {
+	args := m.Called(ctx, input)
+	return args.Get(0).(*awslambda.InvokeOutput), args.Error(1)
+}
+
+func TestNewPublisher(t *testing.T) {
+	asserter := assert.New(t)
+
+	ctx := testutil.NewTestContext()
+	event := webhook.NewVotesRevealedEvent(session.CompleteSessionView{
+		SessionID:             "abcdefg",
+		FacilitatorSessionKey: "sekret",
+		VotesShown:            true,
+		Participants: []session.User{
+			{UserID: "a", Handle: "A", CurrentVote: aws.String("3"), SocketID: "aaaa"},
+		},
+	})
+	payload, err := json.Marshal(event)
+	asserter.NoError(err)
+
+	invoker := &mockInvoker{}
+	invoker.On("InvokeWithContext", ctx, &awslambda.InvokeInput{
+		FunctionName:   aws.String("deliverWebhooks"),
+		InvocationType: aws.String("Event"),
+		Payload:        payload,
+	}).Return(&awslambda.InvokeOutput{}, nil)
+
+	err = webhook.NewPublisher(invoker, "deliverWebhooks")(ctx, event)
+	asserter.NoError(err)
+	invoker.AssertExpectations(t)
+
+	asserter.NotContains(string(payload), "sekret", "facilitator key should never make it into a payload")
+	asserter.NotContains(string(payload), "aaaa", "socket ids should never make it into a payload")
+	asserter.Contains(string(payload), `"currentVote":"3"`)
+}
+
+func TestNewDispatcher(t *testing.T) {
+	asserter := assert.New(t)
+
+	ctx := testutil.NewTestContext()
+	event := webhook.Event{
+		ID:        "event",
+		Type:      webhook.VotesCleared,
+		SessionID: "abcdefg",
+	}
+
+	lister := webhook.Lister(func(ctx context.Context, ownerID string) ([]webhook.Registration, error) {
+		asserter.Equal("session:abcdefg", ownerID)
+		return []webhook.Registration{
+			{ID: "everything"},
+			{ID: "reveals", Events: []webhook.EventType{webhook.VotesRevealed}},
+			{ID: "clears", Events: []webhook.EventType{webhook.VotesRevealed, webhook.VotesCleared}},
+		}, nil
+	})
+
+	delivered := make([]string, 0)
+	deliverer := webhook.Deliverer(func(ctx context.Context, r webhook.Registration, e webhook.Event) webhook.Delivery {
+		asserter.Equal(event, e)
+		delivered = append(delivered, r.ID)
+		return webhook.Delivery{WebhookID: r.ID, EventID: e.ID, Delivered: r.ID == "everything"}
+	})
+
+	logged := make([]webhook.Delivery, 0)
+	logger := webhook.DeliveryLogger(func(ctx context.Context, ownerID string, d webhook.Delivery) error {
+		asserter.Equal("session:abcdefg", ownerID)
+		logged = append(logged, d)
+		if d.WebhookID == "everything" {
+			return errors.New("kaboom")
+		}
+		return nil
+	})
+
+	err := webhook.NewDispatcher(lister, deliverer, logger)(ctx, event)
+	asserter.EqualError(err, "kaboom")
+	asserter.Equal([]string{"everything", "clears"}, delivered)
+	asserter.Equal([]webhook.Delivery{
+		{WebhookID: "everything", EventID: "event", Delivered: true},
+		{WebhookID: "clears", EventID: "event"},
+	}, logged)
+}
+
+func TestNewDispatcher_TeamSession(t *testing.T) {
+	asserter := assert.New(t)
+
+	ctx := testutil.NewTestContext()
+	event := webhook.NewSessionStartedEvent(session.CompleteSessionView{
+		SessionID: "abcdefg",
+		TeamID:    "team1",
+	})
+	asserter.Equal("team1", event.TeamID)
+
+	lister := webhook.Lister(func(ctx context.Context, ownerID string) ([]webhook.Registration, error) {
+		return []webhook.Registration{{ID: ownerID}}, nil
+	})
+
+	delivered := make([]string, 0)
+	deliverer := webhook.Deliverer(func(ctx context.Context, r webhook.Registration, e webhook.Event) webhook.Delivery {
+		delivered = append(delivered, r.ID)
+		return webhook.Delivery{WebhookID: r.ID, EventID: e.ID, Delivered: true}
+	})
+
+	loggedOwners := make([]string, 0)
+	logger := webhook.DeliveryLogger(func(ctx context.Context, ownerID string, d webhook.Delivery) error {
+		loggedOwners = append(loggedOwners, ownerID)
+		return nil
+	})
+
+	err := webhook.NewDispatcher(lister, deliverer, logger)(ctx, event)
+	asserter.NoError(err)
+	asserter.Equal([]string{"session:abcdefg", "team:team1"}, delivered)
+	asserter.Equal([]string{"session:abcdefg", "team:team1"}, loggedOwners)
+}
+
+func TestNewParticipantLeftEvent(t *testing.T) {
+	asserter := assert.New(t)
+
+	departed := session.User{UserID: "a", Name: "Alice"}
+	event := webhook.NewParticipantLeftEvent(session.CompleteSessionView{
+		SessionID: "abcdefg",
+		TeamID:    "team1",
+	}, departed)
+	asserter.Equal(webhook.ParticipantLeft, event.Type)
+	asserter.Equal("abcdefg", event.SessionID)
+	asserter.Equal("team1", event.TeamID)
+	asserter.Equal(&departed, event.Participant)
+}